@@ -0,0 +1,154 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent/react"
+)
+
+// DefaultAgentGraphNames are the agent graphs traced by iteration when Config.AgentGraphNames is empty.
+var DefaultAgentGraphNames = []string{react.GraphName}
+
+// MetadataAgentIteration is the metadata key of the 1-based index of an agent loop iteration run.
+const MetadataAgentIteration = "agent_iteration"
+
+// agentLoop groups the runs of an agent graph, e.g. a ReAct agent, by iteration: every chat model call starts a new
+// iteration chain run, the tools and other nodes that follow it are traced as its children.
+type agentLoop struct {
+	mu         sync.Mutex
+	iterations int
+	current    *LangsmithState // state of the open iteration run, nil before the first model call
+}
+
+// newAgentLoop returns the loop of info if it's an agent graph, see Config.AgentGraphNames.
+func (c *Config) newAgentLoop(info *callbacks.RunInfo) *agentLoop {
+	if c.FlatAgentLoops || info.Component != compose.ComponentOfGraph {
+		return nil
+	}
+	names := c.AgentGraphNames
+	if len(names) == 0 {
+		names = DefaultAgentGraphNames
+	}
+	if !containsString(names, info.Name) {
+		return nil
+	}
+	return &agentLoop{}
+}
+
+// agentIteration returns the state the run of info is started with, state is returned unchanged unless it's the
+// state of an agent graph run. A chat model starts a new iteration, ending the previous one.
+func (c *CallbackHandler) agentIteration(ctx context.Context, info *callbacks.RunInfo, state *LangsmithState) *LangsmithState {
+	loop := state.loop
+	if loop == nil {
+		return state
+	}
+	loop.mu.Lock()
+	defer loop.mu.Unlock()
+	if info.Component != components.ComponentOfChatModel {
+		if loop.current == nil {
+			return state
+		}
+		return loop.current
+	}
+	c.finishIteration(ctx, loop, nil)
+	loop.iterations++
+
+	opts, _ := ctx.Value(langsmithTraceOptionKey{}).(*traceOptions)
+	if opts == nil {
+		opts = &traceOptions{}
+	}
+	extra := SafeDeepCopySyncMapMetadata(opts.Metadata)
+	setRunMetadata(extra, MetadataAgentIteration, loop.iterations)
+	runID := c.cfg.RunIDGen(ctx)
+	startTime, started := runStartTime(state.ParentDottedOrder)
+	run := &Run{
+		ID:          runID,
+		TraceID:     state.TraceID,
+		Name:        fmt.Sprintf("iteration %d", loop.iterations),
+		RunType:     RunTypeChain,
+		StartTime:   startTime,
+		SessionName: state.session,
+		Extra:       extra,
+		Tags:        opts.Tags,
+		ParentRunID: state.parentRunID(),
+		DottedOrder: dottedOrder(state.ParentDottedOrder, startTime, runID),
+	}
+	c.createRun(ctx, run)
+
+	metadata := &sync.Map{}
+	for k, v := range run.Extra {
+		metadata.Store(k, v)
+	}
+	loop.current = &LangsmithState{
+		TraceID:           run.TraceID,
+		ParentRunID:       runID,
+		ParentDottedOrder: run.DottedOrder,
+		Metadata:          metadata,
+		Tags:              run.Tags,
+		startTime:         run.StartTime,
+		started:           started,
+		events:            &runEvents{},
+		cli:               state.cli,
+		session:           state.session,
+		annotations:       newRunAnnotations(run.Extra, run.Tags),
+		root:              state.root,
+		trace:             state.trace,
+		path:              state.path,
+		usage:             c.cfg.newUsageRollup(state.usage),
+		timing:            state.timing,
+	}
+	return loop.current
+}
+
+// finishAgentLoop ends the open iteration of an agent graph run, it fails with the agent if err isn't nil.
+func (c *CallbackHandler) finishAgentLoop(ctx context.Context, state *LangsmithState, err error) {
+	loop := state.loop
+	if loop == nil {
+		return
+	}
+	loop.mu.Lock()
+	defer loop.mu.Unlock()
+	c.finishIteration(ctx, loop, err)
+}
+
+func (c *CallbackHandler) finishIteration(ctx context.Context, loop *agentLoop, err error) {
+	iteration := loop.current
+	if iteration == nil {
+		return
+	}
+	loop.current = nil
+	endTime := iteration.now()
+	patch := &RunPatch{
+		EndTime: &endTime,
+		Events:  iteration.events.drain(),
+	}
+	if err != nil {
+		details, errStr := c.cfg.errorDetails(err)
+		patch.Error = &errStr
+		patch.Extra = SafeDeepCopySyncMapMetadata(iteration.Metadata)
+		patch.Extra[ExtraErrorDetails] = details
+	}
+	iteration.annotations.apply(patch)
+	c.updateRun(ctx, iteration.ParentRunID, patch)
+}
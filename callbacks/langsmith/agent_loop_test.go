@@ -0,0 +1,178 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/flow/agent/react"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedModel answers with its messages in order
+type scriptedModel struct {
+	mu       sync.Mutex
+	messages []*schema.Message
+}
+
+func (m *scriptedModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	msg := m.messages[0]
+	m.messages = m.messages[1:]
+	return msg, nil
+}
+
+func (m *scriptedModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m *scriptedModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+// TestAgentLoop 测试 ReAct agent 的每轮 思考→行动→观察 被归入带序号的 iteration 子链
+func TestAgentLoop(t *testing.T) {
+	mCli := &mockLangsmith{}
+	h := &CallbackHandler{cli: mCli, cfg: &Config{
+		RunIDGen: func(ctx context.Context) string { return uuid.NewString() },
+	}}
+	var mu sync.Mutex
+	runs := map[string]*Run{}
+	patches := map[string]*RunPatch{}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		run := args.Get(1).(*Run)
+		runs[run.ID] = run
+	}).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		patches[args.String(1)] = args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	ctx := context.Background()
+	toolCall := schema.ToolCall{ID: "call-1", Function: schema.FunctionCall{Name: "echo", Arguments: `{"a":1}`}}
+	a, err := react.NewAgent(ctx, &react.AgentConfig{
+		ToolCallingModel: &scriptedModel{messages: []*schema.Message{
+			schema.AssistantMessage("", []schema.ToolCall{toolCall}),
+			schema.AssistantMessage("done", nil),
+		}},
+		ToolsConfig: compose.ToolsNodeConfig{Tools: []tool.BaseTool{echoTool{}}},
+	})
+	require.NoError(t, err)
+	out, err := a.Generate(ctx, []*schema.Message{schema.UserMessage("hi")}, agent.WithComposeOptions(compose.WithCallbacks(h)))
+	require.NoError(t, err)
+	assert.Equal(t, "done", out.Content)
+
+	mu.Lock()
+	defer mu.Unlock()
+	var root *Run
+	iterations := map[string]*Run{}
+	for _, run := range runs {
+		if run.ParentRunID == nil {
+			root = run
+		}
+	}
+	require.NotNil(t, root)
+	assert.Equal(t, react.GraphName, root.Name)
+	for _, run := range runs {
+		if run.ParentRunID != nil && *run.ParentRunID == root.ID {
+			assert.Equal(t, RunTypeChain, run.RunType)
+			iterations[run.ID] = run
+		}
+	}
+	require.Len(t, iterations, 2)
+
+	children := map[string][]string{}
+	for _, run := range runs {
+		if run.ParentRunID != nil {
+			if it, ok := iterations[*run.ParentRunID]; ok {
+				children[it.Name] = append(children[it.Name], run.Name)
+			}
+		}
+	}
+	assert.ElementsMatch(t, []string{react.ModelNodeName, react.ToolsNodeName}, children["iteration 1"])
+	assert.Equal(t, []string{react.ModelNodeName}, children["iteration 2"])
+	for _, it := range iterations {
+		md := it.Extra["metadata"].(map[string]interface{})
+		if it.Name == "iteration 1" {
+			assert.Equal(t, 1, md[MetadataAgentIteration])
+		} else {
+			assert.Equal(t, 2, md[MetadataAgentIteration])
+		}
+		require.Contains(t, patches, it.ID)
+		assert.NotNil(t, patches[it.ID].EndTime)
+		assert.Nil(t, patches[it.ID].Error)
+	}
+}
+
+// TestAgentLoopError 测试 agent 失败时结束当前 iteration 并标记错误, 以及关闭分组
+func TestAgentLoopError(t *testing.T) {
+	mCli := &mockLangsmith{}
+	h := &CallbackHandler{cli: mCli, cfg: &Config{
+		RunIDGen:        func(ctx context.Context) string { return uuid.NewString() },
+		AgentGraphNames: []string{"my_agent"},
+	}}
+	var runs []*Run
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		runs = append(runs, args.Get(1).(*Run))
+	}).Return(nil)
+	patches := map[string]*RunPatch{}
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		patches[args.String(1)] = args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	agentInfo := &callbacks.RunInfo{Name: "my_agent", Component: compose.ComponentOfGraph}
+	modelInfo := &callbacks.RunInfo{Name: "model", Component: components.ComponentOfChatModel}
+	agentCtx := h.OnStart(context.Background(), agentInfo, "in")
+	modelCtx := h.OnStart(agentCtx, modelInfo, &model.CallbackInput{})
+	h.OnError(modelCtx, modelInfo, errors.New("boom"))
+	h.OnError(agentCtx, agentInfo, errors.New("boom"))
+
+	require.Len(t, runs, 3)
+	assert.Equal(t, "iteration 1", runs[1].Name)
+	assert.Equal(t, runs[1].ID, *runs[2].ParentRunID)
+	require.NotNil(t, patches[runs[1].ID].Error)
+	assert.Equal(t, "boom", *patches[runs[1].ID].Error)
+
+	// flat loops
+	runs = nil
+	h.cfg.FlatAgentLoops = true
+	agentCtx = h.OnStart(context.Background(), agentInfo, "in")
+	h.OnStart(agentCtx, modelInfo, &model.CallbackInput{})
+	require.Len(t, runs, 2)
+	assert.Equal(t, runs[0].ID, *runs[1].ParentRunID)
+}
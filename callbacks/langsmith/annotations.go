@@ -0,0 +1,126 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"sync"
+)
+
+// runAnnotations collects the metadata and tags added to a run by business code until it ends,
+// it's shared by all contexts derived from the run.
+type runAnnotations struct {
+	mu        sync.Mutex
+	extra     map[string]interface{} // extra of the run when it was created
+	tags      []string               // tags of the run when it was created
+	metadata  map[string]interface{}
+	addedTags []string
+}
+
+func newRunAnnotations(extra map[string]interface{}, tags []string) *runAnnotations {
+	return &runAnnotations{extra: extra, tags: tags}
+}
+
+// setExtra records the extra of a run whose creation completes in the background, e.g. once its stream input is read.
+func (a *runAnnotations) setExtra(extra map[string]interface{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.extra = extra
+}
+
+// createdExtra returns a copy of the extra of the run when it was created, it's safe to call on nil.
+func (a *runAnnotations) createdExtra() map[string]interface{} {
+	if a == nil {
+		return map[string]interface{}{}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	extra := make(map[string]interface{}, len(a.extra)+1)
+	for k, v := range a.extra {
+		extra[k] = copyMetadataValue(v)
+	}
+	return extra
+}
+
+// apply adds the collected metadata and tags to the final patch of the run, it's safe to call on nil.
+func (a *runAnnotations) apply(patch *RunPatch) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.metadata) > 0 {
+		if patch.Extra == nil {
+			patch.Extra = make(map[string]interface{}, len(a.extra))
+			for k, v := range a.extra {
+				patch.Extra[k] = v
+			}
+		}
+		for k, v := range a.metadata {
+			setRunMetadata(patch.Extra, k, v)
+		}
+	}
+	if len(a.addedTags) > 0 {
+		patch.Tags = append(append([]string(nil), a.tags...), a.addedTags...)
+	}
+}
+
+// UpdateCurrentRunMetadata merges metadata into the metadata of the run ctx is traced in, e.g. cache_hit=true
+// recorded deep inside a node. It's reported when the run ends.
+func UpdateCurrentRunMetadata(ctx context.Context, metadata map[string]interface{}) error {
+	_, state := GetState(ctx)
+	if state == nil || state.annotations == nil {
+		return ErrNoRunInContext
+	}
+	a := state.annotations
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.metadata == nil {
+		a.metadata = make(map[string]interface{}, len(metadata))
+	}
+	for k, v := range metadata {
+		a.metadata[k] = v
+	}
+	return nil
+}
+
+// AddCurrentRunTag adds a tag to the run ctx is traced in, it's reported when the run ends.
+func AddCurrentRunTag(ctx context.Context, tag string) error {
+	_, state := GetState(ctx)
+	if state == nil || state.annotations == nil {
+		return ErrNoRunInContext
+	}
+	state.annotations.addTag(tag)
+	return nil
+}
+
+// addTag adds a tag to the run unless it has it already.
+func (a *runAnnotations) addTag(tag string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, t := range a.tags {
+		if t == tag {
+			return
+		}
+	}
+	for _, t := range a.addedTags {
+		if t == tag {
+			return
+		}
+	}
+	a.addedTags = append(a.addedTags, tag)
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrentRunMutation(t *testing.T) {
+	assert.ErrorIs(t, UpdateCurrentRunMetadata(context.Background(), map[string]interface{}{"a": 1}), ErrNoRunInContext)
+	assert.ErrorIs(t, AddCurrentRunTag(context.Background(), "a"), ErrNoRunInContext)
+
+	mCli := &mockLangsmith{}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	var patch *RunPatch
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		patch = args.Get(2).(*RunPatch)
+	}).Return(nil)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{RunIDGen: func(ctx context.Context) string { return uuid.NewString() }}}
+	md := &sync.Map{}
+	md.Store("metadata", map[string]interface{}{"user": "u1"})
+	ctx := SetTrace(context.Background(), AddTag("base"), SetMetadata(md))
+	ctx = h.OnStart(ctx, &callbacks.RunInfo{Name: "node"}, "in")
+
+	require.NoError(t, UpdateCurrentRunMetadata(ForkSpan(ctx), map[string]interface{}{"cache_hit": true}))
+	require.NoError(t, AddCurrentRunTag(ctx, "cached"))
+	require.NoError(t, AddCurrentRunTag(ctx, "cached"))
+	require.NoError(t, AddCurrentRunTag(ctx, "base"))
+	h.OnEnd(ctx, &callbacks.RunInfo{Name: "node"}, "out")
+
+	require.NotNil(t, patch)
+	assert.Equal(t, []string{"base", "cached"}, patch.Tags)
+	runMD := patch.Extra["metadata"].(map[string]interface{})
+	assert.Equal(t, true, runMD["cache_hit"])
+	assert.Equal(t, "u1", runMD["user"])
+}
+
+func TestFlowTraceCurrentRunMutation(t *testing.T) {
+	mCli := &mockLangsmith{}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	var patch *RunPatch
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		patch = args.Get(2).(*RunPatch)
+	}).Return(nil)
+	ft := &FlowTrace{cli: mCli, cfg: &Config{RunIDGen: func(ctx context.Context) string { return uuid.NewString() }}}
+	ctx, runID, err := ft.StartSpan(context.Background(), "span", nil, WithSpanMetadata(map[string]interface{}{"k": "v"}))
+	require.NoError(t, err)
+	require.NoError(t, UpdateCurrentRunMetadata(ctx, map[string]interface{}{"cache_hit": false}))
+	ft.FinishSpan(ctx, runID)
+
+	md := patch.Extra["metadata"].(map[string]interface{})
+	assert.Equal(t, false, md["cache_hit"])
+	assert.Equal(t, "v", md["k"])
+	assert.Nil(t, patch.Tags)
+}
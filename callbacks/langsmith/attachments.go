@@ -0,0 +1,205 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Attachment is a binary file of a run, e.g. an image of a multimodal model input, uploaded along with the run and
+// shown in the attachments tab of the run in langsmith.
+type Attachment struct {
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"` // base64 in JSON, e.g. in FileExporter records
+}
+
+// AttachmentURLPrefix replaces the inline data of media moved to attachments, followed by the attachment name.
+const AttachmentURLPrefix = "attachment://"
+
+// createRunMultipart creates run and uploads its attachments in a single multipart request.
+func (c *langsmithClient) createRunMultipart(ctx context.Context, run *Run) (*CreateRunResponse, error) {
+	payload, err := c.marshaler.Marshal(run)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal run data: %w", err)
+	}
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err = writeMultipart(w, "post."+run.ID, "application/json", payload); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(run.Attachments))
+	for name := range run.Attachments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a := run.Attachments[name]
+		if err = writeMultipart(w, "attachment."+run.ID+"."+name, a.ContentType, a.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err = w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write multipart body: %w", err)
+	}
+
+	resp, body, err := c.doContent(ctx, ExportOpCreate, "POST", c.ingestURL+"/runs/multipart", w.FormDataContentType(), buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return c.createRunResponse(ctx, run.ID, "/runs/multipart", resp, body)
+}
+
+func writeMultipart(w *multipart.Writer, name, contentType string, data []byte) error {
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, name))
+	h.Set("Content-Type", fmt.Sprintf("%s; length=%d", contentType, len(data)))
+	part, err := w.CreatePart(h)
+	if err == nil {
+		_, err = part.Write(data)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write multipart %s: %w", name, err)
+	}
+	return nil
+}
+
+// modelInputAttachments moves the inline media of the messages of a chat model input to attachments, see
+// messageAttachments. input is returned unchanged if it has none or attachments are disabled.
+func (c *Config) modelInputAttachments(info *callbacks.RunInfo, input callbacks.CallbackInput) (callbacks.CallbackInput, map[string]*Attachment) {
+	if c.inlineMedia() || c.HideInputs || info.Component != components.ComponentOfChatModel {
+		return input, nil
+	}
+	in := model.ConvCallbackInput(input)
+	if in == nil {
+		return input, nil
+	}
+	messages, attachments := messageAttachments(in.Messages)
+	if attachments == nil {
+		return input, nil
+	}
+	cp := *in
+	cp.Messages = messages
+	return &cp, attachments
+}
+
+// messageAttachments replaces the base64 data urls (RFC 2397) of the multimodal parts of messages with
+// AttachmentURLPrefix followed by the name of the attachment holding the decoded data, e.g. message_0_part_1.
+// messages are copied on write, nil attachments are returned if there's no inline media.
+func messageAttachments(messages []*schema.Message) ([]*schema.Message, map[string]*Attachment) {
+	var attachments map[string]*Attachment
+	res := messages
+	for i, msg := range messages {
+		if msg == nil {
+			continue
+		}
+		var parts []schema.ChatMessagePart
+		for j, part := range msg.MultiContent {
+			name := fmt.Sprintf("message_%d_part_%d", i, j)
+			replaced, a := partAttachment(part, AttachmentURLPrefix+name)
+			if a == nil {
+				continue
+			}
+			if attachments == nil {
+				attachments = map[string]*Attachment{}
+				res = append([]*schema.Message(nil), messages...)
+			}
+			if parts == nil {
+				parts = append([]schema.ChatMessagePart(nil), msg.MultiContent...)
+				cp := *msg
+				cp.MultiContent = parts
+				res[i] = &cp
+			}
+			attachments[name] = a
+			parts[j] = replaced
+		}
+	}
+	return res, attachments
+}
+
+// partAttachment returns part with its data url replaced by url, and the decoded data. A nil attachment is returned
+// for text parts and media referenced by regular urls.
+func partAttachment(part schema.ChatMessagePart, url string) (schema.ChatMessagePart, *Attachment) {
+	switch {
+	case part.ImageURL != nil:
+		a := dataURLAttachment(part.ImageURL.URL, part.ImageURL.MIMEType)
+		if a != nil {
+			cp := *part.ImageURL
+			cp.URL = url
+			part.ImageURL = &cp
+		}
+		return part, a
+	case part.AudioURL != nil:
+		a := dataURLAttachment(part.AudioURL.URL, part.AudioURL.MIMEType)
+		if a != nil {
+			cp := *part.AudioURL
+			cp.URL = url
+			part.AudioURL = &cp
+		}
+		return part, a
+	case part.VideoURL != nil:
+		a := dataURLAttachment(part.VideoURL.URL, part.VideoURL.MIMEType)
+		if a != nil {
+			cp := *part.VideoURL
+			cp.URL = url
+			part.VideoURL = &cp
+		}
+		return part, a
+	case part.FileURL != nil:
+		a := dataURLAttachment(part.FileURL.URL, part.FileURL.MIMEType)
+		if a != nil {
+			cp := *part.FileURL
+			cp.URL = url
+			part.FileURL = &cp
+		}
+		return part, a
+	}
+	return part, nil
+}
+
+// dataURLAttachment decodes a base64 data url, e.g. data:image/png;base64,iVBORw0..., nil for other urls.
+func dataURLAttachment(url, mimeType string) *Attachment {
+	if !strings.HasPrefix(url, "data:") {
+		return nil
+	}
+	comma := strings.IndexByte(url, ',')
+	if comma < 0 || !strings.HasSuffix(url[:comma], ";base64") {
+		return nil
+	}
+	data, err := base64.StdEncoding.DecodeString(url[comma+1:])
+	if err != nil {
+		return nil
+	}
+	if mt := strings.TrimSuffix(url[len("data:"):comma], ";base64"); mt != "" {
+		mimeType = mt
+	}
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return &Attachment{ContentType: mimeType, Data: data}
+}
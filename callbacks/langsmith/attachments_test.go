@@ -0,0 +1,135 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateRunMultipart(t *testing.T) {
+	parts := map[string]string{}
+	contentTypes := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/runs/multipart", r.URL.Path)
+		mt, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/form-data", mt)
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			data, _ := io.ReadAll(p)
+			parts[p.FormName()] = string(data)
+			contentTypes[p.FormName()] = p.Header.Get("Content-Type")
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	cli := NewLangsmith("test-key", srv.URL)
+	err := cli.CreateRun(context.Background(), &Run{
+		ID:          "run-1",
+		Name:        "model",
+		Attachments: map[string]*Attachment{"image": {ContentType: "image/png", Data: []byte("png")}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, parts["post.run-1"], `"name":"model"`)
+	assert.NotContains(t, parts["post.run-1"], "png")
+	assert.Equal(t, fmt.Sprintf("application/json; length=%d", len(parts["post.run-1"])), contentTypes["post.run-1"])
+	assert.Equal(t, "png", parts["attachment.run-1.image"])
+	assert.Equal(t, "image/png; length=3", contentTypes["attachment.run-1.image"])
+}
+
+// TestMessageAttachments 测试多模态消息中的 base64 数据被替换为附件, 且不修改原消息
+func TestMessageAttachments(t *testing.T) {
+	png := base64.StdEncoding.EncodeToString([]byte("png-data"))
+	msgs := []*schema.Message{
+		schema.SystemMessage("describe"),
+		{Role: schema.User, MultiContent: []schema.ChatMessagePart{
+			{Type: schema.ChatMessagePartTypeText, Text: "what is it?"},
+			{Type: schema.ChatMessagePartTypeImageURL, ImageURL: &schema.ChatMessageImageURL{URL: "data:image/png;base64," + png}},
+			{Type: schema.ChatMessagePartTypeImageURL, ImageURL: &schema.ChatMessageImageURL{URL: "https://example.com/cat.png"}},
+			{Type: schema.ChatMessagePartTypeFileURL, FileURL: &schema.ChatMessageFileURL{URL: "data:;base64,cGRm", MIMEType: "application/pdf"}},
+		}},
+	}
+
+	res, attachments := messageAttachments(msgs)
+	require.Len(t, attachments, 2)
+	assert.Equal(t, &Attachment{ContentType: "image/png", Data: []byte("png-data")}, attachments["message_1_part_1"])
+	assert.Equal(t, &Attachment{ContentType: "application/pdf", Data: []byte("pdf")}, attachments["message_1_part_3"])
+	assert.Equal(t, AttachmentURLPrefix+"message_1_part_1", res[1].MultiContent[1].ImageURL.URL)
+	assert.Equal(t, "https://example.com/cat.png", res[1].MultiContent[2].ImageURL.URL)
+	assert.Equal(t, AttachmentURLPrefix+"message_1_part_3", res[1].MultiContent[3].FileURL.URL)
+	assert.Same(t, msgs[0], res[0])
+	assert.Equal(t, "data:image/png;base64,"+png, msgs[1].MultiContent[1].ImageURL.URL)
+
+	res, attachments = messageAttachments(msgs[:1])
+	assert.Nil(t, attachments)
+	assert.Equal(t, msgs[:1], res)
+}
+
+// TestHandlerAttachments 测试模型输入中的图片作为附件上传
+func TestHandlerAttachments(t *testing.T) {
+	mCli := &mockLangsmith{}
+	h := &CallbackHandler{cli: mCli, cfg: &Config{
+		RunIDGen: func(ctx context.Context) string { return uuid.NewString() },
+	}}
+	var run *Run
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		run = args.Get(1).(*Run)
+	}).Return(nil)
+
+	info := &callbacks.RunInfo{Name: "model", Component: components.ComponentOfChatModel}
+	input := &model.CallbackInput{Messages: []*schema.Message{{Role: schema.User, MultiContent: []schema.ChatMessagePart{
+		{Type: schema.ChatMessagePartTypeImageURL, ImageURL: &schema.ChatMessageImageURL{URL: "data:image/jpeg;base64,anBn"}},
+	}}}}
+	h.OnStart(context.Background(), info, input)
+	require.NotNil(t, run)
+	assert.Equal(t, []byte("jpg"), run.Attachments["message_0_part_0"].Data)
+	inputs, err := sonic.MarshalString(run.Inputs)
+	require.NoError(t, err)
+	assert.Contains(t, inputs, AttachmentURLPrefix+"message_0_part_0")
+	assert.NotContains(t, inputs, "anBn")
+
+	h.cfg.InlineMedia = true
+	h.OnStart(context.Background(), info, input)
+	assert.Nil(t, run.Attachments)
+	inputs, err = sonic.MarshalString(run.Inputs)
+	require.NoError(t, err)
+	assert.Contains(t, inputs, "anBn")
+}
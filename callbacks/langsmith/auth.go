@@ -0,0 +1,41 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// AuthProvider authenticates a request sent to langsmith, e.g. for self-hosted gateways requiring bearer tokens or
+// HMAC signatures instead of x-api-key. It's called on every attempt of a request, after the default headers are set,
+// with the request body to sign. A returned error fails the request without sending it.
+type AuthProvider func(req *http.Request, body []byte) error
+
+// BearerAuth returns an AuthProvider replacing the x-api-key header with the bearer token returned by token, which is
+// called on every request so tokens can be refreshed.
+func BearerAuth(token func(req *http.Request) (string, error)) AuthProvider {
+	return func(req *http.Request, body []byte) error {
+		t, err := token(req)
+		if err != nil {
+			return fmt.Errorf("failed to get bearer token: %w", err)
+		}
+		req.Header.Del("x-api-key")
+		req.Header.Set("Authorization", "Bearer "+t)
+		return nil
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthProvider(t *testing.T) {
+	var headers []http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), r.Header.Get("X-Signature"))
+		headers = append(headers, r.Header)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	sign := func(req *http.Request, body []byte) error {
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		req.Header.Set("X-Signature", hex.EncodeToString(mac.Sum(nil)))
+		return nil
+	}
+	cli := NewLangsmith("test-key", srv.URL, WithAuthProvider(sign))
+	assert.NoError(t, cli.UpdateRun(context.Background(), "run-1", &RunPatch{Tags: []string{"a"}}))
+	assert.Equal(t, "test-key", headers[0].Get("x-api-key"))
+
+	// a failing provider fails the request without sending it
+	cli = NewLangsmith("test-key", srv.URL, WithAuthProvider(func(req *http.Request, body []byte) error {
+		return errors.New("no credentials")
+	}))
+	assert.ErrorContains(t, cli.UpdateRun(context.Background(), "run-1", &RunPatch{}), "no credentials")
+	assert.Len(t, headers, 1)
+}
+
+func TestBearerAuth(t *testing.T) {
+	var auth, apiKey string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, apiKey = r.Header.Get("Authorization"), r.Header.Get("x-api-key")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	cli := NewLangsmith("test-key", srv.URL, WithAuthProvider(BearerAuth(func(req *http.Request) (string, error) {
+		return "token-1", nil
+	})))
+	assert.NoError(t, cli.UpdateRun(context.Background(), "run-1", &RunPatch{}))
+	assert.Equal(t, "Bearer token-1", auth)
+	assert.Empty(t, apiKey)
+
+	cli = NewLangsmith("test-key", srv.URL, WithAuthProvider(BearerAuth(func(req *http.Request) (string, error) {
+		return "", errors.New("expired")
+	})))
+	assert.ErrorContains(t, cli.UpdateRun(context.Background(), "run-1", &RunPatch{}), "failed to get bearer token: expired")
+}
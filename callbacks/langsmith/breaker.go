@@ -0,0 +1,100 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultCircuitBreakerCooldown is how long an open circuit breaker fails requests fast before probing langsmith.
+const DefaultCircuitBreakerCooldown = 30 * time.Second
+
+// ErrCircuitOpen is returned without calling langsmith while the circuit breaker is open, the run is dropped, or
+// spooled when Config.SpoolDir is set.
+var ErrCircuitOpen = errors.New("langsmith circuit breaker is open")
+
+// circuitBreaker opens after threshold consecutive failures, then fails requests fast for cooldown. Afterwards a
+// single probe request is let through: its success closes the breaker, its failure opens it for another cooldown.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// newCircuitBreaker returns nil, i.e. no breaker, when threshold isn't positive.
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultCircuitBreakerCooldown
+	}
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow reports whether a request may be sent. It's safe to call on nil.
+func (b *circuitBreaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record reports the outcome of an allowed request, it returns whether the breaker changed state.
+func (b *circuitBreaker) record(ok bool) (opened, closed bool) {
+	if b == nil {
+		return false, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasOpen := b.failures >= b.threshold
+	b.probing = false
+	if ok {
+		b.failures = 0
+		return false, wasOpen
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+		return !wasOpen, false
+	}
+	return false, false
+}
+
+// release gives back an allowed request whose outcome is unknown, e.g. canceled locally.
+func (b *circuitBreaker) release() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
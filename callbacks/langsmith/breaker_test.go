@@ -0,0 +1,95 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	assert.Nil(t, newCircuitBreaker(0, time.Second))
+	var nilBreaker *circuitBreaker
+	assert.True(t, nilBreaker.allow())
+
+	b := newCircuitBreaker(2, 20*time.Millisecond)
+	assert.Equal(t, DefaultCircuitBreakerCooldown, newCircuitBreaker(1, 0).cooldown)
+	assert.True(t, b.allow())
+	opened, _ := b.record(false)
+	assert.False(t, opened)
+	assert.True(t, b.allow())
+	opened, _ = b.record(false)
+	assert.True(t, opened)
+	assert.False(t, b.allow())
+
+	time.Sleep(30 * time.Millisecond)
+	assert.True(t, b.allow()) // probe
+	assert.False(t, b.allow())
+	opened, _ = b.record(false)
+	assert.False(t, opened) // still open
+	assert.False(t, b.allow())
+
+	time.Sleep(30 * time.Millisecond)
+	assert.True(t, b.allow())
+	_, closed := b.record(true)
+	assert.True(t, closed)
+	assert.True(t, b.allow())
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	var calls, healthy int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	logger := &recordLogger{}
+	cli := NewLangsmith("key", srv.URL, WithCircuitBreaker(2, 20*time.Millisecond), WithClientLogger(logger))
+	ctx := context.Background()
+	assert.ErrorIs(t, cli.UpdateRun(ctx, "run-1", &RunPatch{}), ErrServerError)
+	assert.ErrorIs(t, cli.UpdateRun(ctx, "run-1", &RunPatch{}), ErrServerError)
+	err := cli.UpdateRun(ctx, "run-1", &RunPatch{})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.True(t, IsRetryable(err))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&healthy, 1)
+	time.Sleep(30 * time.Millisecond)
+	assert.NoError(t, cli.UpdateRun(ctx, "run-1", &RunPatch{}))
+	assert.NoError(t, cli.UpdateRun(ctx, "run-1", &RunPatch{}))
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+	var entries []string
+	for _, entry := range logger.entries {
+		if strings.Contains(entry, "circuit breaker") {
+			entries = append(entries, entry)
+		}
+	}
+	require.Len(t, entries, 2)
+	assert.True(t, strings.HasPrefix(entries[0], "ERROR langsmith circuit breaker opened"))
+	assert.True(t, strings.HasPrefix(entries[1], "WARN langsmith circuit breaker closed"))
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+)
+
+// TagBudgetExceeded tags the root run of a trace whose model runs exceeded Config.TraceBudget.
+const TagBudgetExceeded = "budget_exceeded"
+
+// TraceBudget bounds the total tokens and cost of the model runs of a trace, see Config.TraceBudget. The usage is
+// counted as model runs end, including the model runs filtered out of the trace.
+type TraceBudget struct {
+	MaxTokens int     // total tokens of the trace, 0 for no limit
+	MaxCost   float64 // cost of the trace in USD by the model prices, see Config.Pricing. 0 for no limit
+	// OnExceeded is called once per trace, when the model run crossing the budget ends, with the context of its end
+	// callback. It may log, annotate the current run, or cancel the context of the request to abort it. optional
+	OnExceeded func(ctx context.Context, exceeded *BudgetExceeded)
+}
+
+// BudgetExceeded describes a trace exceeding its TraceBudget.
+type BudgetExceeded struct {
+	TraceID   string
+	RootRunID string  // outermost run of the trace traced by the handler
+	Tokens    int     // total tokens of the trace so far
+	Cost      float64 // cost of the trace so far, 0 if no model has a price
+}
+
+// traceBudgetState tracks whether the trace of a root rollup exceeded its budget.
+type traceBudgetState struct {
+	traceID     string
+	runID       string
+	annotations *runAnnotations // of the root run
+	exceeded    bool            // guarded by the mutex of the root rollup
+}
+
+// bindRoot makes r the root rollup of the trace of state, if it has no parent.
+func (r *usageRollup) bindRoot(state *LangsmithState) {
+	if r == nil || r.parent != nil {
+		return
+	}
+	r.budget = &traceBudgetState{traceID: state.TraceID, runID: state.ParentRunID, annotations: state.annotations}
+}
+
+// check returns the exceeded budget the first time tokens or cost cross it, nil otherwise.
+func (s *traceBudgetState) check(budget *TraceBudget, tokens int, cost float64) *BudgetExceeded {
+	if s == nil || budget == nil || s.exceeded {
+		return nil
+	}
+	if (budget.MaxTokens <= 0 || tokens <= budget.MaxTokens) && (budget.MaxCost <= 0 || cost <= budget.MaxCost) {
+		return nil
+	}
+	s.exceeded = true
+	return &BudgetExceeded{TraceID: s.traceID, RootRunID: s.runID, Tokens: tokens, Cost: cost}
+}
+
+// exceed tags the root run and notifies the application.
+func (s *traceBudgetState) exceed(ctx context.Context, budget *TraceBudget, exceeded *BudgetExceeded) {
+	if s.annotations != nil {
+		s.annotations.addTag(TagBudgetExceeded)
+	}
+	if budget.OnExceeded != nil {
+		budget.OnExceeded(ctx, exceeded)
+	}
+}
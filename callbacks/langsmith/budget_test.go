@@ -0,0 +1,68 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTraceBudget 测试超出 trace 预算时标记根运行并回调一次
+func TestTraceBudget(t *testing.T) {
+	for _, rootOnly := range []bool{false, true} {
+		mCli := new(mockLangsmith)
+		var exceeded []*BudgetExceeded
+		h, err := NewLangsmithHandler(&Config{Exporter: mCli, Blocking: true, RootOnly: rootOnly, TraceBudget: &TraceBudget{
+			MaxTokens: 3000,
+			OnExceeded: func(ctx context.Context, e *BudgetExceeded) {
+				exceeded = append(exceeded, e)
+			},
+		}})
+		require.NoError(t, err)
+		mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+		patches := map[string]*RunPatch{}
+		mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			patches[args.String(1)] = args.Get(2).(*RunPatch)
+		}).Return(nil)
+
+		rootInfo := &callbacks.RunInfo{Name: "root", Component: "Graph"}
+		rootCtx := h.OnStart(context.Background(), rootInfo, "input")
+		root := rootCtx.Value(langsmithStateKey{}).(*LangsmithState)
+		for i := 0; i < 3; i++ {
+			info := &callbacks.RunInfo{Name: "model", Component: components.ComponentOfChatModel}
+			ctx := h.OnStart(rootCtx, info, &model.CallbackInput{})
+			h.OnEnd(ctx, info, &model.CallbackOutput{TokenUsage: &model.TokenUsage{TotalTokens: 1200}})
+		}
+		h.OnEnd(rootCtx, rootInfo, "output")
+
+		require.Len(t, exceeded, 1, "rootOnly=%v", rootOnly)
+		assert.Equal(t, &BudgetExceeded{TraceID: root.TraceID, RootRunID: root.ParentRunID, Tokens: 3600}, exceeded[0])
+		require.NotNil(t, patches[root.ParentRunID])
+		assert.Contains(t, patches[root.ParentRunID].Tags, TagBudgetExceeded)
+		if rootOnly {
+			// the usage is only aggregated for the budget
+			assert.NotContains(t, patches[root.ParentRunID].Extra["metadata"], MetadataRollupUsage)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+)
+
+// BundleVersion is the format version of the TraceBundle written by this package.
+const BundleVersion = 1
+
+// MetadataImportedFrom is the metadata key of an imported run holding the id of the run it was imported from.
+const MetadataImportedFrom = "imported_from"
+
+const defaultBundleMaxRuns = 10000
+
+// TraceBundle is a portable set of runs exported from a langsmith workspace, e.g. to reproduce a customer issue in a
+// staging workspace. Attachments aren't part of a bundle, the media of the exported runs moved to attachments, see
+// Config.InlineMedia, stay referenced by AttachmentURLPrefix urls in the inputs of the imported runs.
+type TraceBundle struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	Runs       []*Run    `json:"runs"`
+}
+
+// ExportBundleOptions selects the runs exported by ExportBundle, TraceIDs and Runs can be combined.
+type ExportBundleOptions struct {
+	// TraceIDs are exported as whole traces.
+	TraceIDs []string
+	// Runs exports the runs matching a filter, e.g. the failed root runs of a project. Cursor is ignored, all pages
+	// are exported.
+	Runs *ListRunsOptions
+	// WholeTraces exports the whole traces of the runs matched by Runs instead of the runs only.
+	WholeTraces bool
+	// MaxRuns fails the export once it grows over this number of runs, protecting against unbounded filters.
+	// default: 10000
+	MaxRuns int
+}
+
+// ImportBundleOptions configures ImportBundle.
+type ImportBundleOptions struct {
+	// SessionName is the project the runs are imported into. default: the langsmith default project
+	SessionName string
+	// KeepIDs imports the runs with their original ids instead of new ones, importing the same bundle twice into a
+	// workspace then fails with conflicts.
+	KeepIDs bool
+}
+
+// ExportBundle reads the runs selected by opts from cli into a TraceBundle, the runs are ordered parents first.
+func ExportBundle(ctx context.Context, cli QueryClient, opts *ExportBundleOptions) (*TraceBundle, error) {
+	if opts == nil || (len(opts.TraceIDs) == 0 && opts.Runs == nil) {
+		return nil, fmt.Errorf("trace ids or a runs filter are required")
+	}
+	e := &bundleExport{cli: cli, maxRuns: opts.MaxRuns, seen: map[string]bool{}, traces: map[string]bool{}}
+	if e.maxRuns <= 0 {
+		e.maxRuns = defaultBundleMaxRuns
+	}
+	traceIDs := append([]string{}, opts.TraceIDs...)
+	if opts.Runs != nil {
+		filter := *opts.Runs
+		filter.Cursor = ""
+		matched, err := e.list(ctx, &filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range matched {
+			if opts.WholeTraces && run.TraceID != "" {
+				traceIDs = append(traceIDs, run.TraceID)
+				continue
+			}
+			if err = e.add(run); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, traceID := range traceIDs {
+		if e.traces[traceID] {
+			continue
+		}
+		e.traces[traceID] = true
+		runs, err := e.list(ctx, &ListRunsOptions{TraceID: traceID})
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range runs {
+			if err = e.add(run); err != nil {
+				return nil, err
+			}
+		}
+	}
+	sortRunsParentsFirst(e.runs)
+	return &TraceBundle{Version: BundleVersion, ExportedAt: time.Now().UTC(), Runs: e.runs}, nil
+}
+
+type bundleExport struct {
+	cli     QueryClient
+	maxRuns int
+	seen    map[string]bool
+	traces  map[string]bool
+	runs    []*Run
+}
+
+// list reads all pages of runs matching opts.
+func (e *bundleExport) list(ctx context.Context, opts *ListRunsOptions) ([]*Run, error) {
+	var runs []*Run
+	for {
+		page, err := e.cli.ListRuns(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("list runs: %w", err)
+		}
+		runs = append(runs, page.Runs...)
+		if len(runs) > e.maxRuns {
+			return nil, fmt.Errorf("bundle exceeds %d runs", e.maxRuns)
+		}
+		if page.NextCursor == "" || len(page.Runs) == 0 {
+			return runs, nil
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
+
+func (e *bundleExport) add(run *Run) error {
+	if run == nil || e.seen[run.ID] {
+		return nil
+	}
+	if len(e.runs) >= e.maxRuns {
+		return fmt.Errorf("bundle exceeds %d runs", e.maxRuns)
+	}
+	e.seen[run.ID] = true
+	e.runs = append(e.runs, run)
+	return nil
+}
+
+// sortRunsParentsFirst sorts runs by dotted order, the dotted order of a parent is a prefix of its children's.
+func sortRunsParentsFirst(runs []*Run) {
+	sort.SliceStable(runs, func(i, j int) bool {
+		if runs[i].DottedOrder != runs[j].DottedOrder {
+			return runs[i].DottedOrder < runs[j].DottedOrder
+		}
+		return runs[i].StartTime.Before(runs[j].StartTime)
+	})
+}
+
+// WriteBundle writes bundle as JSON to w.
+func WriteBundle(w io.Writer, bundle *TraceBundle) error {
+	data, err := sonic.Marshal(bundle)
+	if err != nil {
+		return fmt.Errorf("marshal bundle: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// ReadBundle reads a bundle written by WriteBundle.
+func ReadBundle(r io.Reader) (*TraceBundle, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &TraceBundle{}
+	if err = sonic.Unmarshal(data, bundle); err != nil {
+		return nil, fmt.Errorf("unmarshal bundle: %w", err)
+	}
+	if bundle.Version < 1 || bundle.Version > BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", bundle.Version)
+	}
+	return bundle, nil
+}
+
+// ImportBundle creates the runs of bundle with cli, e.g. a client of another workspace, parents before their
+// children. Unless opts.KeepIDs is set the runs get new ids, the parent, trace and dotted order of the runs are
+// rewritten accordingly and the original id is kept as MetadataImportedFrom. Runs whose parent isn't part of the
+// bundle become roots. Reference examples belong to the source workspace and are dropped. The ids of the created
+// runs are returned by original id. Runs whose parent links form a cycle fail the import.
+func ImportBundle(ctx context.Context, cli RunExporter, bundle *TraceBundle, opts *ImportBundleOptions) (map[string]string, error) {
+	if bundle == nil {
+		return nil, fmt.Errorf("bundle is required")
+	}
+	if opts == nil {
+		opts = &ImportBundleOptions{}
+	}
+	byID := make(map[string]*Run, len(bundle.Runs))
+	for _, run := range bundle.Runs {
+		if run != nil {
+			byID[run.ID] = run
+		}
+	}
+	im := &bundleImport{cli: cli, opts: opts, byID: byID, imported: map[string]*Run{}, importing: map[string]bool{}, ids: map[string]string{}}
+	runs := append([]*Run{}, bundle.Runs...)
+	sortRunsParentsFirst(runs)
+	for _, run := range runs {
+		if run == nil {
+			continue
+		}
+		if _, err := im.importRun(ctx, run); err != nil {
+			return im.ids, err
+		}
+	}
+	return im.ids, nil
+}
+
+type bundleImport struct {
+	cli       RunExporter
+	opts      *ImportBundleOptions
+	byID      map[string]*Run
+	imported  map[string]*Run // created runs by original id
+	importing map[string]bool // runs waiting for their parents to be imported, by original id
+	ids       map[string]string
+}
+
+// importRun creates run after its parent, so runs without a dotted order are imported in a valid order too.
+func (im *bundleImport) importRun(ctx context.Context, run *Run) (*Run, error) {
+	if created, ok := im.imported[run.ID]; ok {
+		return created, nil
+	}
+	if im.importing[run.ID] {
+		return nil, fmt.Errorf("import run %s: parent runs form a cycle", run.ID)
+	}
+	im.importing[run.ID] = true
+	defer delete(im.importing, run.ID)
+	var parent *Run
+	if run.ParentRunID != nil {
+		if src, ok := im.byID[*run.ParentRunID]; ok && src != run {
+			var err error
+			if parent, err = im.importRun(ctx, src); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	created := *run
+	created.ID = run.ID
+	if !im.opts.KeepIDs {
+		created.ID = uuid.NewString()
+	}
+	created.SessionName = im.opts.SessionName
+	created.ReferenceExampleID = nil
+	created.Attachments = nil
+	if parent != nil {
+		parentID := parent.ID
+		created.ParentRunID = &parentID
+		created.TraceID = parent.TraceID
+		created.DottedOrder = dottedOrder(parent.DottedOrder, run.StartTime, created.ID)
+	} else {
+		created.ParentRunID = nil
+		created.TraceID = created.ID
+		created.DottedOrder = dottedOrder("", run.StartTime, created.ID)
+	}
+	if !im.opts.KeepIDs {
+		created.Extra = importedExtra(run.Extra, run.ID)
+	}
+	if err := im.cli.CreateRun(ctx, &created); err != nil {
+		return nil, fmt.Errorf("import run %s: %w", run.ID, err)
+	}
+	im.imported[run.ID] = &created
+	im.ids[run.ID] = created.ID
+	return &created, nil
+}
+
+// importedExtra copies extra with MetadataImportedFrom added to its metadata.
+func importedExtra(extra map[string]interface{}, runID string) map[string]interface{} {
+	copied := make(map[string]interface{}, len(extra)+1)
+	for k, v := range extra {
+		copied[k] = v
+	}
+	metadata := map[string]interface{}{}
+	if m, ok := extra["metadata"].(map[string]interface{}); ok {
+		for k, v := range m {
+			metadata[k] = v
+		}
+	}
+	metadata[MetadataImportedFrom] = runID
+	copied["metadata"] = metadata
+	return copied
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func bundleTrace() []*Run {
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	root := &Run{ID: "root", Name: "graph", RunType: RunTypeChain, StartTime: start, TraceID: "root",
+		DottedOrder: dottedOrder("", start, "root"), ReferenceExampleID: strPtr("example")}
+	childStart := start.Add(time.Millisecond)
+	child := &Run{ID: "child", Name: "model", RunType: RunTypeLLM, StartTime: childStart, TraceID: "root",
+		ParentRunID: strPtr("root"), DottedOrder: dottedOrder(root.DottedOrder, childStart, "child"),
+		Extra: map[string]interface{}{"metadata": map[string]interface{}{"k": "v"}}}
+	return []*Run{root, child}
+}
+
+func strPtr(s string) *string { return &s }
+
+// TestExportBundle 测试按过滤条件导出完整 trace 并分页读取
+func TestExportBundle(t *testing.T) {
+	ctx := context.Background()
+	trace := bundleTrace()
+	mCli := &mockLangsmith{}
+	mCli.On("ListRuns", ctx, mock.MatchedBy(func(o *ListRunsOptions) bool { return o.IsRoot })).
+		Return(&RunsPage{Runs: []*Run{trace[0]}}, nil).Once()
+	mCli.On("ListRuns", ctx, mock.MatchedBy(func(o *ListRunsOptions) bool { return o.TraceID == "root" && o.Cursor == "" })).
+		Return(&RunsPage{Runs: []*Run{trace[1]}, NextCursor: "next"}, nil).Once()
+	mCli.On("ListRuns", ctx, mock.MatchedBy(func(o *ListRunsOptions) bool { return o.TraceID == "root" && o.Cursor == "next" })).
+		Return(&RunsPage{Runs: []*Run{trace[0]}}, nil).Once()
+
+	bundle, err := ExportBundle(ctx, mCli, &ExportBundleOptions{
+		TraceIDs: []string{"root"}, Runs: &ListRunsOptions{IsRoot: true}, WholeTraces: true})
+	require.NoError(t, err)
+	require.Len(t, bundle.Runs, 2)
+	assert.Equal(t, "root", bundle.Runs[0].ID)
+	assert.Equal(t, "child", bundle.Runs[1].ID)
+	assert.Equal(t, BundleVersion, bundle.Version)
+	mCli.AssertExpectations(t)
+
+	_, err = ExportBundle(ctx, mCli, nil)
+	assert.Error(t, err)
+
+	mCli.On("ListRuns", ctx, mock.Anything).Return(&RunsPage{Runs: trace}, nil)
+	_, err = ExportBundle(ctx, mCli, &ExportBundleOptions{TraceIDs: []string{"other"}, MaxRuns: 1})
+	assert.ErrorContains(t, err, "exceeds 1 runs")
+}
+
+// TestBundleRoundTrip 测试 bundle 的写入与读取
+func TestBundleRoundTrip(t *testing.T) {
+	bundle := &TraceBundle{Version: BundleVersion, ExportedAt: time.Now().UTC(), Runs: bundleTrace()}
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteBundle(buf, bundle))
+	read, err := ReadBundle(buf)
+	require.NoError(t, err)
+	require.Len(t, read.Runs, 2)
+	assert.Equal(t, "child", read.Runs[1].ID)
+	assert.Equal(t, "root", *read.Runs[1].ParentRunID)
+
+	_, err = ReadBundle(strings.NewReader(`{"version":99}`))
+	assert.ErrorContains(t, err, "unsupported bundle version")
+}
+
+// TestImportBundle 测试导入时重写 run id、父子关系与 dotted order
+func TestImportBundle(t *testing.T) {
+	ctx := context.Background()
+	trace := bundleTrace()
+	var created []*Run
+	mCli := &mockLangsmith{}
+	mCli.On("CreateRun", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		created = append(created, args.Get(1).(*Run))
+	}).Return(nil)
+
+	// children listed first are still created after their parent
+	ids, err := ImportBundle(ctx, mCli, &TraceBundle{Version: BundleVersion, Runs: []*Run{trace[1], trace[0]}},
+		&ImportBundleOptions{SessionName: "staging"})
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+	root, child := created[0], created[1]
+	assert.Equal(t, ids["root"], root.ID)
+	assert.Equal(t, ids["child"], child.ID)
+	assert.NotEqual(t, "root", root.ID)
+	assert.Equal(t, root.ID, root.TraceID)
+	assert.Nil(t, root.ParentRunID)
+	assert.Nil(t, root.ReferenceExampleID)
+	assert.Equal(t, "staging", root.SessionName)
+	assert.Equal(t, root.ID, *child.ParentRunID)
+	assert.Equal(t, root.ID, child.TraceID)
+	assert.Equal(t, dottedOrder(root.DottedOrder, trace[1].StartTime, child.ID), child.DottedOrder)
+	assert.Equal(t, map[string]interface{}{"k": "v", MetadataImportedFrom: "child"}, child.Extra["metadata"])
+	// the bundle is left untouched
+	assert.Equal(t, "root", *trace[1].ParentRunID)
+	assert.Equal(t, map[string]interface{}{"k": "v"}, trace[1].Extra["metadata"])
+
+	// a run whose parent isn't in the bundle becomes a root
+	created = nil
+	ids, err = ImportBundle(ctx, mCli, &TraceBundle{Version: BundleVersion, Runs: trace[1:]}, &ImportBundleOptions{KeepIDs: true})
+	require.NoError(t, err)
+	require.Len(t, created, 1)
+	assert.Equal(t, "child", ids["child"])
+	assert.Nil(t, created[0].ParentRunID)
+	assert.Equal(t, "child", created[0].TraceID)
+	assert.Equal(t, dottedOrder("", trace[1].StartTime, "child"), created[0].DottedOrder)
+}
+
+// TestImportBundleCycle 测试父子关系成环的 run 导入失败，而不是无限递归
+func TestImportBundleCycle(t *testing.T) {
+	ctx := context.Background()
+	mCli := &mockLangsmith{}
+	runs := []*Run{{ID: "a", ParentRunID: strPtr("b")}, {ID: "b", ParentRunID: strPtr("a")}}
+	_, err := ImportBundle(ctx, mCli, &TraceBundle{Version: BundleVersion, Runs: runs}, nil)
+	assert.ErrorContains(t, err, "cycle")
+	mCli.AssertNotCalled(t, "CreateRun", mock.Anything, mock.Anything)
+}
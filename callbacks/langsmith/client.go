@@ -19,21 +19,86 @@ package langsmith
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"math/rand"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
-
-	"github.com/bytedance/sonic"
 )
 
+// maxRetryBackoff caps the exponential backoff of retried requests.
+const maxRetryBackoff = 30 * time.Second
+
+// RunExporter receives the runs traced by CallbackHandler and FlowTrace, Langsmith exports them to the langsmith API.
+type RunExporter interface {
+	CreateRun(ctx context.Context, run *Run) error
+	UpdateRun(ctx context.Context, runID string, patch *RunPatch) error
+}
+
+// CreateRunResponse is the response of langsmith to a created run.
+type CreateRunResponse struct {
+	StatusCode int
+	// Existed reports the run was already created, e.g. by a retried attempt, the request still succeeds.
+	Existed bool
+	// Body is the decoded response body, only with WithRunReadBack. It's nil if the body is empty, e.g. of a 202.
+	Body map[string]interface{}
+}
+
+// RunResponseCreator is implemented by the client returned by NewLangsmith, it returns the response of a created run
+// instead of only an error. The run itself is never modified.
+type RunResponseCreator interface {
+	CreateRunWithResponse(ctx context.Context, run *Run) (*CreateRunResponse, error)
+}
+
 // Langsmith func interface
+//
+// The client returned by NewLangsmith and Client.API also implements FeedbackClient, DatasetClient, PromptClient,
+// ProjectClient, QueryClient, ShareClient, RunURLClient and InfoClient, assert the interface of the requests needed.
 type Langsmith interface {
 	CreateRun(ctx context.Context, run *Run) error
 	UpdateRun(ctx context.Context, runID string, patch *RunPatch) error
 }
 
+// ErrNotSupported is returned when the langsmith client doesn't implement the interface of a request, e.g. a mock
+// only exporting runs.
+var ErrNotSupported = errors.New("langsmith client doesn't support the request")
+
+// langsmithWrapper is implemented by the clients changing how runs are exported, e.g. spooling them, the other
+// requests go to the client they wrap.
+type langsmithWrapper interface {
+	unwrap() Langsmith
+}
+
+// apiClient returns cli as T, or the first client wrapped by cli implementing T. what names the requests in the
+// ErrNotSupported error returned if there's none.
+func apiClient[T any](cli Langsmith, what string) (T, error) {
+	for cli != nil {
+		if api, ok := cli.(T); ok {
+			return api, nil
+		}
+		w, ok := cli.(langsmithWrapper)
+		if !ok {
+			break
+		}
+		cli = w.unwrap()
+	}
+	var zero T
+	return zero, fmt.Errorf("%w: %s", ErrNotSupported, what)
+}
+
+// rootClient returns the client wrapped by cli, which sends the requests other than runs.
+func rootClient(cli Langsmith) Langsmith {
+	for {
+		w, ok := cli.(langsmithWrapper)
+		if !ok {
+			return cli
+		}
+		cli = w.unwrap()
+	}
+}
+
 const (
 	// DefaultLangsmithAPIURL Langsmith default url
 	DefaultLangsmithAPIURL = "https://api.smith.langchain.com"
@@ -42,9 +107,11 @@ const (
 type RunType string
 
 const (
-	RunTypeChain RunType = "chain" // chain node
-	RunTypeLLM   RunType = "llm"   // llm model node
-	RunTypeTool  RunType = "tool"  // tool node
+	RunTypeChain     RunType = "chain"     // chain node
+	RunTypeLLM       RunType = "llm"       // llm model node
+	RunTypeTool      RunType = "tool"      // tool node
+	RunTypeRetriever RunType = "retriever" // retriever node
+	RunTypePrompt    RunType = "prompt"    // chat template node
 )
 
 type Run struct {
@@ -63,6 +130,9 @@ type Run struct {
 	ReferenceExampleID *string                `json:"reference_example_id,omitempty"` // ID of a reference example associated with the run. This is usually only present for evaluation runs.
 	DottedOrder        string                 `json:"dotted_order,omitempty"`         // Ordering string, hierarchical. Format: run_start_timeZrun_uuid.child_run_start_timeZchild_run_uuid...
 	Tags               []string               `json:"tags,omitempty"`                 // Tags or labels associated with the run.
+	Events             []RunEvent             `json:"events,omitempty"`               // Timeline events of the run.
+
+	Attachments map[string]*Attachment `json:"-"` // Binary files of the run by name, uploaded with the run as multipart.
 }
 
 // RunPatch update run when it is finished or failed, patch output or error msg.
@@ -72,92 +142,429 @@ type RunPatch struct {
 	Outputs map[string]interface{} `json:"outputs,omitempty"`  // A map or set of outputs generated by the run.
 	Error   *string                `json:"error,omitempty"`    // Error message if the run encountered an error.
 	Extra   map[string]interface{} `json:"extra,omitempty"`    // Any extra information run.
+	Events  []RunEvent             `json:"events,omitempty"`   // Timeline events of the run, e.g. the first streamed token.
+	Tags    []string               `json:"tags,omitempty"`     // Tags of the run, replacing the tags it was created with.
 }
 
+// RunEvent is a timestamped event inside a run, shown on the run timeline in langsmith.
+type RunEvent struct {
+	Name   string                 `json:"name"`
+	Time   time.Time              `json:"time"`
+	Kwargs map[string]interface{} `json:"kwargs,omitempty"`
+}
+
+const (
+	// RunEventNewToken marks a streamed token, langsmith computes time to first token from the first one.
+	RunEventNewToken = "new_token"
+	// RunEventRetry marks a retried attempt inside the run, e.g. a retried model call.
+	RunEventRetry = "retry"
+	// RunEventToolSelected marks the tool chosen by an agent.
+	RunEventToolSelected = "tool_selected"
+)
+
+// WorkspaceIDHeader selects the workspace of a request, for API keys having access to several workspaces.
+const WorkspaceIDHeader = "X-Tenant-Id"
+
 type langsmithClient struct {
 	apiKey     string
 	baseURL    string
+	ingestURL  string // runs are written here, default: baseURL
+	workspace  string // default workspace of the requests, see WorkspaceIDHeader
+	auth       AuthProvider
+	readBack   bool // decode the response bodies of created runs
+	schema     SchemaVersion
 	httpClient *http.Client
+	logger     Logger
+	metrics    Metrics
+	marshaler  Marshaler
+
+	maxRetries   int
+	retryBackoff time.Duration
+
+	limiter  *rateLimiter // nil without rate limit
+	inFlight semaphore    // nil without concurrency cap
+	breaker  *circuitBreaker
+
+	projects sync.Map // projectKey -> *Project, cache of GetRunURL
+}
+
+// ClientOption customizes the client created by NewLangsmith
+type ClientOption func(*langsmithClient)
+
+// WithHTTPClient sets the http client used to call langsmith, default timeout is 10s
+func WithHTTPClient(cli *http.Client) ClientOption {
+	return func(c *langsmithClient) {
+		if cli != nil {
+			c.httpClient = cli
+		}
+	}
+}
+
+// WithClientLogger sets the Logger used by the client
+func WithClientLogger(logger Logger) ClientOption {
+	return func(c *langsmithClient) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
+
+// WithClientMetrics sets the Metrics notified of retried requests
+func WithClientMetrics(metrics Metrics) ClientOption {
+	return func(c *langsmithClient) {
+		if metrics != nil {
+			c.metrics = metrics
+		}
+	}
+}
+
+// WithMarshaler sets the Marshaler encoding requests and decoding responses, default: sonic
+func WithMarshaler(m Marshaler) ClientOption {
+	return func(c *langsmithClient) {
+		if m != nil {
+			c.marshaler = m
+		}
+	}
+}
+
+// WithRateLimit limits the requests sent to langsmith to requestsPerSecond, with bursts of up to burst requests.
+// requests wait for their turn, or until their context is done. default: no limit
+func WithRateLimit(requestsPerSecond float64, burst int) ClientOption {
+	return func(c *langsmithClient) {
+		c.limiter = newRateLimiter(requestsPerSecond, burst)
+	}
+}
+
+// WithMaxInFlight caps the requests sent to langsmith concurrently, others wait for a free slot. default: no cap
+func WithMaxInFlight(n int) ClientOption {
+	return func(c *langsmithClient) {
+		c.inFlight = newSemaphore(n)
+	}
+}
+
+// WithCircuitBreaker fails requests fast with ErrCircuitOpen after threshold consecutive network errors or 5xx
+// responses, instead of waiting for the timeout of every request while langsmith is down. After cooldown a probe
+// request checks whether langsmith recovered. default: disabled
+func WithCircuitBreaker(threshold int, cooldown time.Duration) ClientOption {
+	return func(c *langsmithClient) {
+		c.breaker = newCircuitBreaker(threshold, cooldown)
+	}
+}
+
+// WithMaxRetries sets how many times a failed request is retried, backoff doubles on every attempt up to 30s, with
+// jitter. The Retry-After of 429 responses is honored instead. only network errors, 429 and 5xx responses are retried.
+// default: 0, no retry
+func WithMaxRetries(maxRetries int, backoff time.Duration) ClientOption {
+	return func(c *langsmithClient) {
+		c.maxRetries = maxRetries
+		if backoff > 0 {
+			c.retryBackoff = backoff
+		}
+	}
+}
+
+// WithIngestURL sends run writes to a dedicated ingest host, e.g. of EU or self-hosted deployments, while reads,
+// datasets and feedback still go to the api url. default: the api url
+func WithIngestURL(ingestURL string) ClientOption {
+	return func(c *langsmithClient) {
+		if ingestURL != "" {
+			c.ingestURL = ingestURL
+		}
+	}
+}
+
+// WithClientWorkspaceID sends every request to a workspace, for API keys having access to several of them. the
+// workspace of a trace set by WithWorkspaceID takes precedence. default: the workspace of the API key
+func WithClientWorkspaceID(workspaceID string) ClientOption {
+	return func(c *langsmithClient) {
+		c.workspace = workspaceID
+	}
+}
+
+// WithAuthProvider authenticates every request with auth, after the x-api-key and workspace headers are set.
+func WithAuthProvider(auth AuthProvider) ClientOption {
+	return func(c *langsmithClient) {
+		c.auth = auth
+	}
+}
+
+// WithRunReadBack decodes the response body of created runs into CreateRunResponse.Body, see RunResponseCreator.
+// default: disabled, the body is ignored
+func WithRunReadBack(enabled bool) ClientOption {
+	return func(c *langsmithClient) {
+		c.readBack = enabled
+	}
 }
 
 // NewLangsmith create langsmith client
-func NewLangsmith(apiKey, apiUrl string) Langsmith {
+func NewLangsmith(apiKey, apiUrl string, opts ...ClientOption) Langsmith {
 	if apiUrl == "" {
 		apiUrl = DefaultLangsmithAPIURL
 	}
-	return &langsmithClient{
+	c := &langsmithClient{
 		apiKey:     apiKey,
 		baseURL:    apiUrl,
 		httpClient: &http.Client{Timeout: 10 * time.Second},
+		logger:     defaultLogger,
+		metrics:    nopMetrics{},
+		marshaler:  sonicMarshaler{},
+
+		retryBackoff: 100 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	if c.ingestURL == "" {
+		c.ingestURL = c.baseURL
+	}
+	return c
 }
 
-// CreateRun create run
+// CreateRun create run, creating a run that already exists succeeds, which makes retries idempotent
 func (c *langsmithClient) CreateRun(ctx context.Context, run *Run) error {
-	jsonData, err := sonic.Marshal(run)
+	_, err := c.CreateRunWithResponse(ctx, run)
+	return err
+}
+
+// CreateRunWithResponse implements RunResponseCreator
+func (c *langsmithClient) CreateRunWithResponse(ctx context.Context, run *Run) (*CreateRunResponse, error) {
+	run = c.encodeRun(ctx, run)
+	if len(run.Attachments) > 0 {
+		return c.createRunMultipart(ctx, run)
+	}
+	jsonData, err := c.marshaler.Marshal(run)
 	if err != nil {
-		return fmt.Errorf("failed to marshal run data: %w", err)
+		return nil, fmt.Errorf("failed to marshal run data: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/runs", bytes.NewBuffer(jsonData))
+	resp, body, err := c.do(ctx, ExportOpCreate, "POST", c.ingestURL+"/runs", jsonData)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
+	return c.createRunResponse(ctx, run.ID, "/runs", resp, body)
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", c.apiKey)
+// createRunResponse checks the response of a created run, and decodes its body if read-back is enabled.
+func (c *langsmithClient) createRunResponse(ctx context.Context, runID, path string, resp *http.Response, body []byte) (*CreateRunResponse, error) {
+	if resp.StatusCode == http.StatusConflict {
+		// run ids are generated by the client, a conflict means the run was already created,
+		// e.g. by an attempt which timed out on our side, or by a replay of the spool
+		c.logger.Debug(ctx, "run already exists", "run_id", runID)
+		return &CreateRunResponse{StatusCode: resp.StatusCode, Existed: true}, nil
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		c.logger.Debug(ctx, "create run request failed", "run_id", runID, "status", resp.Status)
+		return nil, newAPIError("POST", path, resp, body)
+	}
 
-	resp, err := c.httpClient.Do(req)
+	created := &CreateRunResponse{StatusCode: resp.StatusCode}
+	if c.readBack && len(bytes.TrimSpace(body)) > 0 {
+		// the run is created whatever the body is, an unexpected one isn't worth a retry
+		if err := c.marshaler.Unmarshal(body, &created.Body); err != nil {
+			c.logger.Debug(ctx, "failed to decode create run response", "run_id", runID, "err", err)
+		}
+	}
+	return created, nil
+}
+
+// UpdateRun update run when it is finished or failed, patch output or error msg.
+func (c *langsmithClient) UpdateRun(ctx context.Context, runID string, patch *RunPatch) error {
+	jsonData, err := c.marshaler.Marshal(c.encodePatch(patch))
 	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+		return fmt.Errorf("failed to marshal patch data: %w", err)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	url := fmt.Sprintf("%s/runs/%s", c.ingestURL, runID)
+	resp, body, err := c.do(ctx, ExportOpUpdate, "PATCH", url, jsonData)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return err
 	}
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
-		return fmt.Errorf("failed to create run, status: %s, body: %s", resp.Status, string(body))
+		c.logger.Debug(ctx, "update run request failed", "run_id", runID, "status", resp.Status)
+		return newAPIError("PATCH", "/runs/"+runID, resp, body)
 	}
 
-	// decode resp data
-	err = sonic.Unmarshal(body, run)
+	return nil
+}
+
+// doJSON sends in as the json body to path, and decodes a 2xx response body into out. in and out can be nil.
+func (c *langsmithClient) doJSON(ctx context.Context, op requestOp, method, path string, in, out interface{}) error {
+	var data []byte
+	if in != nil {
+		var err error
+		data, err = c.marshaler.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request data: %w", err)
+		}
+	}
+	resp, body, err := c.do(ctx, op, method, c.baseURL+path, data)
 	if err != nil {
-		return fmt.Errorf("failed to decode response body: %w", err)
+		return err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return newAPIError(method, path, resp, body)
+	}
+	if out != nil && len(body) > 0 {
+		if err = c.marshaler.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("failed to decode response body: %w", err)
+		}
 	}
-
 	return nil
 }
 
-// UpdateRun update run when it is finished or failed, patch output or error msg.
-func (c *langsmithClient) UpdateRun(ctx context.Context, runID string, patch *RunPatch) error {
-	jsonData, err := json.Marshal(patch)
-	if err != nil {
-		return fmt.Errorf("failed to marshal patch data: %w", err)
+// do sends a json request and reads the whole response body.
+func (c *langsmithClient) do(ctx context.Context, op requestOp, method, url string, data []byte) (*http.Response, []byte, error) {
+	return c.doContent(ctx, op, method, url, "application/json", data)
+}
+
+// doContent sends a request with a body of contentType and reads the whole response body.
+// network errors, 429 and 5xx responses are retried up to maxRetries times with exponential backoff.
+func (c *langsmithClient) doContent(ctx context.Context, op requestOp, method, url, contentType string, data []byte) (*http.Response, []byte, error) {
+	var (
+		lastErr    error
+		retryAfter time.Duration
+	)
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			c.logger.Warn(ctx, "retrying langsmith request", "method", method, "url", url, "attempt", attempt, "err", lastErr)
+			op.retried(c.metrics)
+			timer := time.NewTimer(c.backoff(attempt, retryAfter))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, nil, fmt.Errorf("failed to execute request: %w", ctx.Err())
+			case <-timer.C:
+			}
+		}
+
+		resp, body, err := c.send(ctx, method, url, contentType, data)
+		if err != nil {
+			lastErr = err
+			var netErr *networkError
+			if errors.As(err, &netErr) && attempt < c.maxRetries && ctx.Err() == nil {
+				continue
+			}
+			return nil, nil, lastErr
+		}
+		if isRetryableStatus(resp.StatusCode) && attempt < c.maxRetries {
+			lastErr = fmt.Errorf("status: %s", resp.Status)
+			retryAfter = 0
+			if resp.StatusCode == http.StatusTooManyRequests {
+				retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+			}
+			continue
+		}
+		return resp, body, nil
 	}
+}
 
-	url := fmt.Sprintf("%s/runs/%s", c.baseURL, runID)
-	req, err := http.NewRequestWithContext(ctx, "PATCH", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+// backoff returns the delay before the given retry attempt: retryAfter if set, otherwise retryBackoff doubled on
+// every attempt up to maxRetryBackoff, jittered between half and all of it so that concurrent requests spread out.
+func (c *langsmithClient) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	backoff := c.retryBackoff
+	for i := 1; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// parseRetryAfter parses a Retry-After header, in seconds or as an http date, 0 if unset or invalid.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
 	}
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", c.apiKey)
+// send makes a single attempt of a request through the circuit breaker.
+func (c *langsmithClient) send(ctx context.Context, method, url, contentType string, data []byte) (*http.Response, []byte, error) {
+	if !c.breaker.allow() {
+		return nil, nil, ErrCircuitOpen
+	}
+	resp, body, err := c.sendAllowed(ctx, method, url, contentType, data)
+	var netErr *networkError
+	failed := ctx.Err() == nil && (errors.As(err, &netErr) || (err == nil && resp.StatusCode >= http.StatusInternalServerError))
+	if err != nil && !failed {
+		// local failures, e.g. a canceled context, tell nothing about langsmith
+		c.breaker.release()
+		return resp, body, err
+	}
+	opened, closed := c.breaker.record(!failed)
+	if opened {
+		c.logger.Error(ctx, "langsmith circuit breaker opened, requests fail fast", "url", url)
+	} else if closed {
+		c.logger.Warn(ctx, "langsmith circuit breaker closed", "url", url)
+	}
+	return resp, body, err
+}
 
-	resp, err := c.httpClient.Do(req)
+// sendAllowed waits for the rate limiter and a free in-flight slot, then sends the request.
+func (c *langsmithClient) sendAllowed(ctx context.Context, method, url, contentType string, data []byte) (*http.Response, []byte, error) {
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to wait for rate limiter: %w", err)
+	}
+	if err := c.inFlight.acquire(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to wait for in-flight requests: %w", err)
+	}
+	defer c.inFlight.release()
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
 	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	apiKey, workspaceID := requestCredentials(ctx)
+	if apiKey == "" {
+		apiKey = c.apiKey
+	}
+	if workspaceID == "" {
+		workspaceID = c.workspace
 	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	req.Header.Set("x-api-key", apiKey)
+	if workspaceID != "" {
+		req.Header.Set(WorkspaceIDHeader, workspaceID)
+	}
+	if c.auth != nil {
+		if err = c.auth(req, data); err != nil {
+			return nil, nil, fmt.Errorf("failed to authenticate request: %w", err)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to execute request: %w", &networkError{err: err})
 	}
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
-		return fmt.Errorf("failed to update run, status: %s, body: %s", resp.Status, string(body))
+	body, err := readBody(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
+	return resp, body, nil
+}
 
-	return nil
+// networkError marks the failures of send to reach langsmith, which are retried.
+type networkError struct {
+	err error
+}
+
+func (e *networkError) Error() string { return e.err.Error() }
+func (e *networkError) Unwrap() error { return e.err }
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
 }
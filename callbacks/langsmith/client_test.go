@@ -0,0 +1,224 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientRetry(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "test-key", r.Header.Get("x-api-key"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	t.Run("retry until success", func(t *testing.T) {
+		cli := NewLangsmith("test-key", srv.URL, WithMaxRetries(2, time.Millisecond))
+		err := cli.UpdateRun(context.Background(), "run-1", &RunPatch{})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("no retry by default", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		cli := NewLangsmith("test-key", srv.URL)
+		err := cli.UpdateRun(context.Background(), "run-1", &RunPatch{})
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
+
+// TestClientRetryBackoff 测试重试退避有上限并带抖动
+func TestClientRetryBackoff(t *testing.T) {
+	cli := NewLangsmith("test-key", "http://localhost", WithMaxRetries(100, time.Second)).(*langsmithClient)
+	for _, tt := range []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 500 * time.Millisecond, time.Second},
+		{3, 2 * time.Second, 4 * time.Second},
+		{64, maxRetryBackoff / 2, maxRetryBackoff},
+	} {
+		for i := 0; i < 20; i++ {
+			backoff := cli.backoff(tt.attempt, 0)
+			assert.GreaterOrEqual(t, backoff, tt.min, "attempt %d", tt.attempt)
+			assert.LessOrEqual(t, backoff, tt.max, "attempt %d", tt.attempt)
+		}
+	}
+	assert.Equal(t, 5*time.Second, cli.backoff(1, 5*time.Second))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3", now))
+	assert.Equal(t, 10*time.Second, parseRetryAfter(now.Add(10*time.Second).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("-1", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+	assert.Zero(t, parseRetryAfter(now.Add(-time.Second).Format(http.TimeFormat), now))
+}
+
+// TestClientRetryAfter 测试 429 响应的 Retry-After 会被遵守
+func TestClientRetryAfter(t *testing.T) {
+	var calls int32
+	var first time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		assert.GreaterOrEqual(t, time.Since(first), 900*time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	cli := NewLangsmith("test-key", srv.URL, WithMaxRetries(1, time.Millisecond))
+	assert.NoError(t, cli.UpdateRun(context.Background(), "run-1", &RunPatch{}))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestClientNoRetryOnBadRequest(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	cli := NewLangsmith("test-key", srv.URL, WithMaxRetries(3, time.Millisecond))
+	err := cli.CreateRun(context.Background(), &Run{ID: "run-1"})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestClientCreateRunConflict(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first attempt is created but answered with an error, the retry conflicts with it
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"detail":"run already exists"}`))
+	}))
+	defer srv.Close()
+
+	cli := NewLangsmith("test-key", srv.URL, WithMaxRetries(1, time.Millisecond))
+	assert.NoError(t, cli.CreateRun(context.Background(), &Run{ID: "run-1"}))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// conflicts of other requests are still reported
+	assert.ErrorIs(t, cli.UpdateRun(context.Background(), "run-1", &RunPatch{}), ErrConflict)
+}
+
+func TestClientIngestURL(t *testing.T) {
+	var apiPaths, ingestPaths []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiPaths = append(apiPaths, r.Method+" "+r.URL.Path)
+		_, _ = w.Write([]byte(`{"id":"fb-1"}`))
+	}))
+	defer api.Close()
+	ingest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ingestPaths = append(ingestPaths, r.Method+" "+r.URL.Path)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ingest.Close()
+
+	ctx := context.Background()
+	cli := NewLangsmith("test-key", api.URL, WithIngestURL(ingest.URL)).(*langsmithClient)
+	assert.NoError(t, cli.CreateRun(ctx, &Run{ID: "run-1"}))
+	assert.NoError(t, cli.UpdateRun(ctx, "run-1", &RunPatch{}))
+	_, err := cli.CreateFeedback(ctx, &Feedback{RunID: "run-1", Key: "score"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"POST /runs", "PATCH /runs/run-1"}, ingestPaths)
+	assert.Equal(t, []string{"POST /feedback"}, apiPaths)
+
+	// runs go to the api url by default
+	apiPaths = nil
+	cli = NewLangsmith("test-key", api.URL, WithIngestURL("")).(*langsmithClient)
+	assert.NoError(t, cli.UpdateRun(ctx, "run-1", &RunPatch{}))
+	assert.Equal(t, []string{"PATCH /runs/run-1"}, apiPaths)
+}
+
+func TestClientCreateRunResponse(t *testing.T) {
+	body := `{"id":"server-id","name":"changed"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body == "" {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	// the run is never modified by the response
+	run := &Run{ID: "run-1", Name: "graph"}
+	cli := NewLangsmith("test-key", srv.URL)
+	assert.NoError(t, cli.CreateRun(ctx, run))
+	assert.Equal(t, &Run{ID: "run-1", Name: "graph"}, run)
+	resp, err := cli.(RunResponseCreator).CreateRunWithResponse(ctx, run)
+	assert.NoError(t, err)
+	assert.Equal(t, &CreateRunResponse{StatusCode: http.StatusOK}, resp)
+
+	cli = NewLangsmith("test-key", srv.URL, WithRunReadBack(true))
+	resp, err = cli.(RunResponseCreator).CreateRunWithResponse(ctx, run)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": "server-id", "name": "changed"}, resp.Body)
+	assert.Equal(t, "graph", run.Name)
+
+	// empty and unexpected bodies are tolerated
+	for _, body = range []string{"", "not json"} {
+		resp, err = cli.(RunResponseCreator).CreateRunWithResponse(ctx, run)
+		assert.NoError(t, err)
+		assert.Nil(t, resp.Body)
+	}
+}
+
+func TestAPIClientUnwrap(t *testing.T) {
+	api := NewLangsmith("test-key", "http://localhost:1984")
+	spooled := &spooledLangsmith{Langsmith: &exporterLangsmith{Langsmith: api, exporter: NewConsoleExporter(io.Discard)}}
+	assert.Same(t, api, rootClient(spooled))
+
+	cli, err := apiClient[FeedbackClient](spooled, "feedback")
+	assert.NoError(t, err)
+	assert.Same(t, api, cli)
+
+	h := &CallbackHandler{cli: NewConsoleExporter(io.Discard), cfg: &Config{}}
+	ctx := context.WithValue(context.Background(), langsmithStateKey{}, &LangsmithState{TraceID: "trace-1", ParentRunID: "run-1"})
+	_, err = h.CreateFeedback(ctx, &Feedback{Key: "score"})
+	assert.ErrorIs(t, err, ErrNotSupported)
+	_, err = h.GetShareURL(ctx)
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
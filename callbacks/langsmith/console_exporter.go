@@ -0,0 +1,140 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConsoleExporter is a RunExporter printing every finished trace as an indented run tree, with run types,
+// durations and errors. It's meant for local development without a langsmith account, set it as Config.Exporter:
+//
+//	chain [chain] 1.2s
+//	  ChatModel [llm] 800ms
+//	  tool [tool] 3ms error: timeout
+//
+// Runs are buffered until the root run of their trace finishes.
+type ConsoleExporter struct {
+	w      io.Writer
+	mu     sync.Mutex
+	traces map[string][]*Run // trace id -> runs
+	runs   map[string]*Run   // run id -> run
+}
+
+// NewConsoleExporter creates a ConsoleExporter writing to w, or to stderr when w is nil.
+func NewConsoleExporter(w io.Writer) *ConsoleExporter {
+	if w == nil {
+		w = os.Stderr
+	}
+	return &ConsoleExporter{w: w, traces: map[string][]*Run{}, runs: map[string]*Run{}}
+}
+
+// CreateRun implements RunExporter
+func (e *ConsoleExporter) CreateRun(ctx context.Context, run *Run) error {
+	r := *run
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.runs[r.ID] = &r
+	traceID := consoleTraceID(&r)
+	e.traces[traceID] = append(e.traces[traceID], &r)
+	return e.flushLocked(&r)
+}
+
+// UpdateRun implements RunExporter
+func (e *ConsoleExporter) UpdateRun(ctx context.Context, runID string, patch *RunPatch) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	run, ok := e.runs[runID]
+	if !ok {
+		return nil
+	}
+	if patch.EndTime != nil {
+		run.EndTime = patch.EndTime
+	}
+	if patch.Error != nil {
+		run.Error = patch.Error
+	}
+	return e.flushLocked(run)
+}
+
+// flushLocked prints and forgets the trace of run once run is its finished root.
+func (e *ConsoleExporter) flushLocked(run *Run) error {
+	traceID := consoleTraceID(run)
+	if run.EndTime == nil || run.ID != traceID {
+		return nil
+	}
+	runs := e.traces[traceID]
+	delete(e.traces, traceID)
+	for _, r := range runs {
+		delete(e.runs, r.ID)
+	}
+	_, err := io.WriteString(e.w, renderConsoleTree(runs))
+	return err
+}
+
+func consoleTraceID(run *Run) string {
+	if run.TraceID != "" {
+		return run.TraceID
+	}
+	return run.ID
+}
+
+// renderConsoleTree renders runs of one trace, dotted orders sort parents before their children and siblings
+// by start time, the depth of a run is the number of its ancestors in the dotted order.
+func renderConsoleTree(runs []*Run) string {
+	sort.SliceStable(runs, func(i, j int) bool {
+		if runs[i].DottedOrder != runs[j].DottedOrder {
+			return runs[i].DottedOrder < runs[j].DottedOrder
+		}
+		return runs[i].StartTime.Before(runs[j].StartTime)
+	})
+	sb := &strings.Builder{}
+	for _, run := range runs {
+		sb.WriteString(strings.Repeat("  ", strings.Count(run.DottedOrder, ".")))
+		sb.WriteString(fmt.Sprintf("%s [%s]", run.Name, run.RunType))
+		if run.EndTime != nil {
+			sb.WriteString(" ")
+			sb.WriteString(consoleDuration(run.EndTime.Sub(run.StartTime)))
+		} else {
+			sb.WriteString(" (unfinished)")
+		}
+		if run.Error != nil {
+			sb.WriteString(" error: ")
+			sb.WriteString(*run.Error)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func consoleDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(time.Millisecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
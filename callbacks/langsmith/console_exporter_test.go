@@ -0,0 +1,58 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsoleExporter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exp := NewConsoleExporter(buf)
+	ctx := context.Background()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := func(d time.Duration) *time.Time {
+		t := start.Add(d)
+		return &t
+	}
+	root := "root"
+
+	require.NoError(t, exp.CreateRun(ctx, &Run{ID: "root", Name: "graph", RunType: RunTypeChain, StartTime: start,
+		TraceID: "root", DottedOrder: "20250101T000000000000Zroot"}))
+	require.NoError(t, exp.CreateRun(ctx, &Run{ID: "tool", Name: "search", RunType: RunTypeTool, StartTime: start.Add(2 * time.Millisecond),
+		TraceID: "root", ParentRunID: &root, DottedOrder: "20250101T000000000000Zroot.20250101T000000002000Ztool"}))
+	require.NoError(t, exp.CreateRun(ctx, &Run{ID: "model", Name: "ChatModel", RunType: RunTypeLLM, StartTime: start.Add(time.Millisecond),
+		TraceID: "root", ParentRunID: &root, DottedOrder: "20250101T000000000000Zroot.20250101T000000001000Zmodel"}))
+	require.NoError(t, exp.UpdateRun(ctx, "model", &RunPatch{EndTime: end(801 * time.Millisecond)}))
+	errMsg := "timeout"
+	require.NoError(t, exp.UpdateRun(ctx, "tool", &RunPatch{EndTime: end(5 * time.Millisecond), Error: &errMsg}))
+	assert.Empty(t, buf.String())
+
+	require.NoError(t, exp.UpdateRun(ctx, "root", &RunPatch{EndTime: end(1234 * time.Millisecond)}))
+	assert.Equal(t, "graph [chain] 1.23s\n  ChatModel [llm] 800ms\n  search [tool] 3ms error: timeout\n", buf.String())
+	assert.Empty(t, exp.traces)
+	assert.Empty(t, exp.runs)
+
+	// unknown runs are ignored
+	assert.NoError(t, exp.UpdateRun(ctx, "unknown", &RunPatch{EndTime: end(0)}))
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"strings"
+
+	"github.com/cloudwego/eino/components/model"
+)
+
+// ModelPrice is the price of a model in USD per 1K tokens.
+type ModelPrice struct {
+	PromptPer1K     float64
+	CompletionPer1K float64
+}
+
+// DefaultModelPrices are list prices of common models, used when Config.Pricing has no entry for a model.
+// they may be outdated, set Config.Pricing to use your negotiated prices.
+var DefaultModelPrices = map[string]ModelPrice{
+	"gpt-4o":            {PromptPer1K: 0.0025, CompletionPer1K: 0.01},
+	"gpt-4o-mini":       {PromptPer1K: 0.00015, CompletionPer1K: 0.0006},
+	"gpt-4.1":           {PromptPer1K: 0.002, CompletionPer1K: 0.008},
+	"gpt-4.1-mini":      {PromptPer1K: 0.0004, CompletionPer1K: 0.0016},
+	"gpt-4.1-nano":      {PromptPer1K: 0.0001, CompletionPer1K: 0.0004},
+	"gpt-4-turbo":       {PromptPer1K: 0.01, CompletionPer1K: 0.03},
+	"gpt-3.5-turbo":     {PromptPer1K: 0.0005, CompletionPer1K: 0.0015},
+	"o3-mini":           {PromptPer1K: 0.0011, CompletionPer1K: 0.0044},
+	"claude-3-5-sonnet": {PromptPer1K: 0.003, CompletionPer1K: 0.015},
+	"claude-3-7-sonnet": {PromptPer1K: 0.003, CompletionPer1K: 0.015},
+	"claude-3-5-haiku":  {PromptPer1K: 0.0008, CompletionPer1K: 0.004},
+	"claude-3-opus":     {PromptPer1K: 0.015, CompletionPer1K: 0.075},
+	"gemini-1.5-pro":    {PromptPer1K: 0.00125, CompletionPer1K: 0.005},
+	"gemini-1.5-flash":  {PromptPer1K: 0.000075, CompletionPer1K: 0.0003},
+	"gemini-2.0-flash":  {PromptPer1K: 0.0001, CompletionPer1K: 0.0004},
+	"deepseek-chat":     {PromptPer1K: 0.00027, CompletionPer1K: 0.0011},
+	"deepseek-reasoner": {PromptPer1K: 0.00055, CompletionPer1K: 0.00219},
+}
+
+// modelPrice looks up the price of a model by the longest matching name prefix, e.g. gpt-4o-2024-08-06 is priced as
+// gpt-4o and gpt-4o-mini-2024-07-18 as gpt-4o-mini. Config.Pricing takes precedence over DefaultModelPrices.
+func (c *Config) modelPrice(name string) (ModelPrice, bool) {
+	if name == "" {
+		return ModelPrice{}, false
+	}
+	var best string
+	var bestPrice ModelPrice
+	found := false
+	lookup := func(table map[string]ModelPrice, override bool) {
+		for prefix, price := range table {
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			if !found || len(prefix) > len(best) || (override && prefix == best) {
+				best, bestPrice, found = prefix, price, true
+			}
+		}
+	}
+	lookup(DefaultModelPrices, false)
+	if c != nil {
+		lookup(c.Pricing, true)
+	}
+	return bestPrice, found
+}
+
+// usageCost returns the cost of usage by a model, false if the model has no price.
+func (c *Config) usageCost(modelName string, usage *model.TokenUsage) (float64, bool) {
+	price, ok := c.modelPrice(modelName)
+	if !ok {
+		return 0, false
+	}
+	return float64(usage.PromptTokens)/1000*price.PromptPer1K + float64(usage.CompletionTokens)/1000*price.CompletionPer1K, true
+}
+
+// reportUsage sets the token usage of a model run into extra.metadata,
+// together with prompt_cost, completion_cost and total_cost when the model has a price.
+// The total cost is returned, false if the model has no price.
+func (c *Config) reportUsage(extra map[string]interface{}, usage *model.TokenUsage) (float64, bool) {
+	md, _ := extra["metadata"].(map[string]interface{})
+	cp := make(map[string]interface{}, len(md)+4)
+	for k, v := range md {
+		cp[k] = v
+	}
+	cp["usage_metadata"] = map[string]int{
+		"input_tokens":  usage.PromptTokens,
+		"output_tokens": usage.CompletionTokens,
+		"total_tokens":  usage.TotalTokens,
+	}
+	extra["metadata"] = cp
+	modelName, _ := cp["ls_model_name"].(string)
+	price, ok := c.modelPrice(modelName)
+	if !ok {
+		return 0, false
+	}
+	promptCost := float64(usage.PromptTokens) / 1000 * price.PromptPer1K
+	completionCost := float64(usage.CompletionTokens) / 1000 * price.CompletionPer1K
+	cp["prompt_cost"] = promptCost
+	cp["completion_cost"] = completionCost
+	cp["total_cost"] = promptCost + completionCost
+	return promptCost + completionCost, true
+}
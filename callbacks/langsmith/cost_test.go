@@ -0,0 +1,80 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelPrice(t *testing.T) {
+	cfg := &Config{Pricing: map[string]ModelPrice{
+		"gpt-4o":   {PromptPer1K: 1, CompletionPer1K: 2},
+		"my-model": {PromptPer1K: 3, CompletionPer1K: 4},
+	}}
+
+	price, ok := cfg.modelPrice("gpt-4o")
+	assert.True(t, ok)
+	assert.Equal(t, ModelPrice{PromptPer1K: 1, CompletionPer1K: 2}, price)
+
+	price, ok = cfg.modelPrice("gpt-4o-mini-2024-07-18")
+	assert.True(t, ok)
+	assert.Equal(t, DefaultModelPrices["gpt-4o-mini"], price, "a longer default prefix wins over a shorter custom one")
+
+	price, ok = cfg.modelPrice("my-model-v2")
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, price.PromptPer1K)
+
+	_, ok = cfg.modelPrice("unknown")
+	assert.False(t, ok)
+	_, ok = (*Config)(nil).modelPrice("")
+	assert.False(t, ok)
+}
+
+func TestModelRunCost(t *testing.T) {
+	mCli := new(mockLangsmith)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{Pricing: map[string]ModelPrice{"my-model": {PromptPer1K: 1, CompletionPer1K: 2}}}}
+	var patched *RunPatch
+	mCli.On("UpdateRun", mock.Anything, "run-1", mock.Anything).Run(func(args mock.Arguments) {
+		patched = args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	md := &sync.Map{}
+	md.Store("metadata", map[string]interface{}{"ls_model_name": "my-model"})
+	ctx := context.WithValue(context.Background(), langsmithStateKey{}, &LangsmithState{ParentRunID: "run-1", Metadata: md})
+	info := &callbacks.RunInfo{Component: components.ComponentOfChatModel}
+	h.OnEnd(ctx, info, &model.CallbackOutput{
+		Message:    schema.AssistantMessage("hi", nil),
+		TokenUsage: &model.TokenUsage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500},
+	})
+
+	require.NotNil(t, patched)
+	metadata := patched.Extra["metadata"].(map[string]interface{})
+	assert.Equal(t, map[string]int{"input_tokens": 1000, "output_tokens": 500, "total_tokens": 1500}, metadata["usage_metadata"])
+	assert.InDelta(t, 1.0, metadata["prompt_cost"], 1e-9)
+	assert.InDelta(t, 1.0, metadata["completion_cost"], 1e-9)
+	assert.InDelta(t, 2.0, metadata["total_cost"], 1e-9)
+}
@@ -0,0 +1,208 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const opDataset APIOp = "dataset"
+
+// ErrNotFound is returned when the requested langsmith resource doesn't exist.
+var ErrNotFound = errors.New("langsmith resource not found")
+
+// Dataset is a collection of examples used to evaluate an application.
+type Dataset struct {
+	ID           string                 `json:"id,omitempty"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description,omitempty"`
+	DataType     string                 `json:"data_type,omitempty"` // "kv", "llm" or "chat", default: kv
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	ExampleCount int                    `json:"example_count,omitempty"`
+	CreatedAt    *time.Time             `json:"created_at,omitempty"`
+	ModifiedAt   *time.Time             `json:"modified_at,omitempty"`
+}
+
+// Example is an input with its expected output in a Dataset.
+type Example struct {
+	ID          string                 `json:"id,omitempty"`
+	DatasetID   string                 `json:"dataset_id"`
+	Inputs      map[string]interface{} `json:"inputs"`
+	Outputs     map[string]interface{} `json:"outputs,omitempty"` // reference outputs
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	SourceRunID string                 `json:"source_run_id,omitempty"` // the run the example was created from
+	CreatedAt   *time.Time             `json:"created_at,omitempty"`
+	ModifiedAt  *time.Time             `json:"modified_at,omitempty"`
+}
+
+// ExampleUpdate patches an existing Example, nil fields are left untouched.
+type ExampleUpdate struct {
+	DatasetID string                 `json:"dataset_id,omitempty"` // moves the example to another dataset
+	Inputs    map[string]interface{} `json:"inputs,omitempty"`
+	Outputs   map[string]interface{} `json:"outputs,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ListExamplesOptions filters and paginates ListExamples.
+type ListExamplesOptions struct {
+	DatasetID  string   // required
+	ExampleIDs []string // only return these examples
+	Offset     int
+	Limit      int // default: server side default (100)
+}
+
+// DatasetClient manages datasets and their examples, it's implemented by the client returned by NewLangsmith.
+type DatasetClient interface {
+	CreateDataset(ctx context.Context, dataset *Dataset) (*Dataset, error)
+	ReadDataset(ctx context.Context, name string) (*Dataset, error)
+	CreateExample(ctx context.Context, example *Example) (*Example, error)
+	ListExamples(ctx context.Context, opts *ListExamplesOptions) ([]*Example, error)
+	UpdateExample(ctx context.Context, exampleID string, update *ExampleUpdate) error
+}
+
+// CreateDataset creates a dataset, DataType defaults to kv, dataset isn't modified
+func (c *langsmithClient) CreateDataset(ctx context.Context, dataset *Dataset) (*Dataset, error) {
+	if dataset == nil || dataset.Name == "" {
+		return nil, fmt.Errorf("dataset name is required")
+	}
+	cp := *dataset
+	if cp.DataType == "" {
+		cp.DataType = "kv"
+	}
+	created := &Dataset{}
+	if err := c.doJSON(ctx, opDataset, "POST", "/datasets", &cp, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// ReadDataset reads a dataset by name, ErrNotFound is returned if it doesn't exist
+func (c *langsmithClient) ReadDataset(ctx context.Context, name string) (*Dataset, error) {
+	var datasets []*Dataset
+	if err := c.doJSON(ctx, opDataset, "GET", "/datasets?"+url.Values{"name": {name}}.Encode(), nil, &datasets); err != nil {
+		return nil, err
+	}
+	for _, ds := range datasets {
+		if ds.Name == name {
+			return ds, nil
+		}
+	}
+	return nil, fmt.Errorf("dataset %q: %w", name, ErrNotFound)
+}
+
+// CreateExample adds an example into example.DatasetID
+func (c *langsmithClient) CreateExample(ctx context.Context, example *Example) (*Example, error) {
+	if example == nil || example.DatasetID == "" {
+		return nil, fmt.Errorf("example dataset id is required")
+	}
+	created := &Example{}
+	if err := c.doJSON(ctx, opDataset, "POST", "/examples", example, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// ListExamples lists examples of a dataset
+func (c *langsmithClient) ListExamples(ctx context.Context, opts *ListExamplesOptions) ([]*Example, error) {
+	if opts == nil || opts.DatasetID == "" {
+		return nil, fmt.Errorf("dataset id is required")
+	}
+	query := url.Values{"dataset": {opts.DatasetID}}
+	for _, id := range opts.ExampleIDs {
+		query.Add("id", id)
+	}
+	if opts.Offset > 0 {
+		query.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	var examples []*Example
+	if err := c.doJSON(ctx, opDataset, "GET", "/examples?"+query.Encode(), nil, &examples); err != nil {
+		return nil, err
+	}
+	return examples, nil
+}
+
+// UpdateExample patches inputs, outputs or metadata of an example
+func (c *langsmithClient) UpdateExample(ctx context.Context, exampleID string, update *ExampleUpdate) error {
+	return c.doJSON(ctx, opDataset, "PATCH", "/examples/"+url.PathEscape(exampleID), update, nil)
+}
+
+// ErrNoRootRun is returned by AddRunToDataset when the root run of the trace isn't traced by a CallbackHandler of
+// this process, e.g. it's a remote parent or a FlowTrace span.
+var ErrNoRootRun = errors.New("root run of the trace not traced by the handler")
+
+// AddRunToDataset adds the root run of the trace ctx is traced in to the named dataset once it ended, e.g. to curate
+// a golden case from production in one line. The example holds the inputs and outputs of the run, the dataset is
+// created if it doesn't exist, CallbackHandler.Flush and Shutdown wait for it. Failures are logged.
+func AddRunToDataset(ctx context.Context, datasetName string) error {
+	if datasetName == "" {
+		return fmt.Errorf("dataset name is required")
+	}
+	_, state := GetState(ctx)
+	if state == nil || state.ParentRunID == "" {
+		return ErrNoRunInContext
+	}
+	if state.root == nil {
+		return ErrNoRootRun
+	}
+	state.root.addDataset(datasetName)
+	return nil
+}
+
+// addToDatasets creates an example from the ended root run in each dataset in the background.
+func (c *CallbackHandler) addToDatasets(ctx context.Context, run *Run, datasets []string) {
+	if len(datasets) == 0 {
+		return
+	}
+	ctx, cancel := c.cfg.exportContext(ctx)
+	c.background.run(func() {
+		defer cancel()
+		for _, name := range datasets {
+			if err := c.addToDataset(ctx, run, name); err != nil {
+				c.cfg.logger().Error(ctx, "add run to dataset error", "err", err, "run_id", run.ID, "dataset", name)
+			}
+		}
+	})
+}
+
+func (c *CallbackHandler) addToDataset(ctx context.Context, run *Run, datasetName string) error {
+	cli, err := apiClient[DatasetClient](c.cli, "dataset")
+	if err != nil {
+		return err
+	}
+	dataset, err := cli.ReadDataset(ctx, datasetName)
+	if errors.Is(err, ErrNotFound) {
+		dataset, err = cli.CreateDataset(ctx, &Dataset{Name: datasetName})
+	}
+	if err != nil {
+		return err
+	}
+	_, err = cli.CreateExample(ctx, &Example{
+		DatasetID:   dataset.ID,
+		Inputs:      run.Inputs,
+		Outputs:     run.Outputs,
+		SourceRunID: run.ID,
+	})
+	return err
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatasetClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/datasets":
+			var ds map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&ds))
+			assert.Equal(t, "kv", ds["data_type"])
+			_, _ = w.Write([]byte(`{"id":"ds-1","name":"golden"}`))
+		case r.Method == "GET" && r.URL.Path == "/datasets":
+			if r.URL.Query().Get("name") == "golden" {
+				_, _ = w.Write([]byte(`[{"id":"ds-1","name":"golden"}]`))
+				return
+			}
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == "POST" && r.URL.Path == "/examples":
+			_, _ = w.Write([]byte(`{"id":"ex-1","dataset_id":"ds-1","inputs":{"q":"hi"}}`))
+		case r.Method == "GET" && r.URL.Path == "/examples":
+			assert.Equal(t, "ds-1", r.URL.Query().Get("dataset"))
+			assert.Equal(t, "10", r.URL.Query().Get("offset"))
+			assert.Equal(t, "5", r.URL.Query().Get("limit"))
+			_, _ = w.Write([]byte(`[{"id":"ex-1","dataset_id":"ds-1","inputs":{"q":"hi"},"outputs":{"a":"hello"}}]`))
+		case r.Method == "PATCH" && r.URL.Path == "/examples/ex-1":
+			var ex map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&ex))
+			assert.Equal(t, map[string]interface{}{"outputs": map[string]interface{}{"a": "hey"}}, ex)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	cli := NewLangsmith("test-key", srv.URL).(*langsmithClient)
+	ctx := context.Background()
+
+	dataset := &Dataset{Name: "golden"}
+	ds, err := cli.CreateDataset(ctx, dataset)
+	require.NoError(t, err)
+	assert.Equal(t, "ds-1", ds.ID)
+	assert.Empty(t, dataset.DataType)
+
+	ds, err = cli.ReadDataset(ctx, "golden")
+	require.NoError(t, err)
+	assert.Equal(t, "ds-1", ds.ID)
+	_, err = cli.ReadDataset(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	ex, err := cli.CreateExample(ctx, &Example{DatasetID: "ds-1", Inputs: map[string]interface{}{"q": "hi"}})
+	require.NoError(t, err)
+	assert.Equal(t, "ex-1", ex.ID)
+	_, err = cli.CreateExample(ctx, &Example{})
+	assert.Error(t, err)
+
+	examples, err := cli.ListExamples(ctx, &ListExamplesOptions{DatasetID: "ds-1", Offset: 10, Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, examples, 1)
+	assert.Equal(t, "hello", examples[0].Outputs["a"])
+
+	assert.NoError(t, cli.UpdateExample(ctx, "ex-1", &ExampleUpdate{Outputs: map[string]interface{}{"a": "hey"}}))
+}
+
+// TestAddRunToDataset 测试在请求内标记根 run，结束后以其输入输出创建 example
+func TestAddRunToDataset(t *testing.T) {
+	mCli := &mockLangsmith{}
+	h := &CallbackHandler{cli: mCli, cfg: &Config{RunIDGen: DefaultRunIDGen}, background: &backgroundTasks{}}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mCli.On("ReadDataset", mock.Anything, "golden").Return(nil, fmt.Errorf("dataset %q: %w", "golden", ErrNotFound))
+	mCli.On("CreateDataset", mock.Anything, mock.MatchedBy(func(ds *Dataset) bool { return ds.Name == "golden" })).
+		Return(&Dataset{ID: "ds-1", Name: "golden"}, nil)
+	examples := make(chan *Example, 2)
+	mCli.On("CreateExample", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		examples <- args.Get(1).(*Example)
+	}).Return(&Example{ID: "ex-1"}, nil)
+
+	assert.ErrorIs(t, AddRunToDataset(context.Background(), "golden"), ErrNoRunInContext)
+	remote := context.WithValue(context.Background(), langsmithStateKey{}, &LangsmithState{TraceID: "t", ParentRunID: "remote"})
+	assert.ErrorIs(t, AddRunToDataset(remote, "golden"), ErrNoRootRun)
+
+	graph := &callbacks.RunInfo{Name: "graph", Component: compose.ComponentOfGraph}
+	tool := &callbacks.RunInfo{Name: "tool", Component: components.ComponentOfTool}
+	graphCtx := h.OnStart(context.Background(), graph, "question")
+	toolCtx := h.OnStart(graphCtx, tool, "in")
+	require.NoError(t, AddRunToDataset(toolCtx, "golden"))
+	h.OnEnd(toolCtx, tool, "out")
+	assert.Empty(t, examples, "added once the root run ended")
+	h.OnEnd(graphCtx, graph, "answer")
+	require.NoError(t, h.Shutdown(context.Background()))
+
+	var example *Example
+	select {
+	case example = <-examples:
+	default:
+		t.Fatal("example not created before Shutdown returned")
+	}
+	assert.Equal(t, "ds-1", example.DatasetID)
+	assert.Equal(t, CurrentRunID(graphCtx), example.SourceRunID)
+	assert.Equal(t, `"question"`, example.Inputs["input"])
+	assert.Equal(t, `"answer"`, example.Outputs["output"])
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dottedOrderTimeLayout is the second-precision part of a dotted order timestamp, the microseconds are appended to it,
+// e.g. 20250102T030405123456Z<run id>.
+const dottedOrderTimeLayout = "20060102T150405"
+
+// runClock hands out run start times with the microsecond precision of dotted orders. The times are strictly
+// increasing within the process, so sibling runs started within the same microsecond keep their execution order.
+type runClock struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+var defaultRunClock = &runClock{}
+
+// now returns the current UTC time truncated to microseconds, strictly after the previous time it returned, and the
+// monotonic clock reading it was taken from.
+func (c *runClock) now() (time.Time, time.Time) {
+	mono := time.Now()
+	t := mono.UTC().Truncate(time.Microsecond)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !t.After(c.last) {
+		t = c.last.Add(time.Microsecond)
+	}
+	c.last = t
+	return t, mono
+}
+
+// runStartTime returns the start time of a run whose parent has parentDottedOrder, a run never starts before or at
+// the same microsecond as its parent, even if the parent was started on a host whose clock is ahead. The monotonic
+// clock reading of the start is returned too, the end time of the run is derived from it, see LangsmithState.now.
+func runStartTime(parentDottedOrder string) (t, started time.Time) {
+	t, started = defaultRunClock.now()
+	if parentDottedOrder == "" {
+		return t, started
+	}
+	// the skew of a remote parent isn't kept by the clock, it would shift the runs of unrelated traces too
+	if parentStart, err := dottedOrderStartTime(parentDottedOrder); err == nil && !t.After(parentStart) {
+		t = parentStart.Add(time.Microsecond)
+	}
+	return t, started
+}
+
+// dottedOrderTimeLen is the length of a dotted order timestamp, microseconds and Z included.
+const dottedOrderTimeLen = len(dottedOrderTimeLayout) + 7
+
+// dottedOrder returns the dotted order of a run, its start time should come from runStartTime. it's built with a
+// single allocation, as every run computes one.
+func dottedOrder(parentDottedOrder string, startTime time.Time, runID string) string {
+	t := startTime.UTC()
+	var ts [dottedOrderTimeLen]byte
+	buf := t.AppendFormat(ts[:0], dottedOrderTimeLayout)
+	micros := t.Nanosecond() / int(time.Microsecond)
+	for i := 6; i > 0; i-- {
+		ts[len(buf)+i-1] = byte('0' + micros%10)
+		micros /= 10
+	}
+	ts[dottedOrderTimeLen-1] = 'Z'
+
+	var b strings.Builder
+	b.Grow(len(parentDottedOrder) + 1 + dottedOrderTimeLen + len(runID))
+	if parentDottedOrder != "" {
+		b.WriteString(parentDottedOrder)
+		b.WriteByte('.')
+	}
+	b.Write(ts[:])
+	b.WriteString(runID)
+	return b.String()
+}
+
+// dottedOrderStartTime returns the start time of the last run of a dotted order.
+func dottedOrderStartTime(dottedOrder string) (time.Time, error) {
+	segment := dottedOrder[strings.LastIndex(dottedOrder, ".")+1:]
+	idx := strings.Index(segment, "Z")
+	if idx != len(dottedOrderTimeLayout)+6 {
+		return time.Time{}, fmt.Errorf("invalid dotted order %q", dottedOrder)
+	}
+	t, err := time.Parse(dottedOrderTimeLayout, segment[:len(dottedOrderTimeLayout)])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid dotted order %q: %w", dottedOrder, err)
+	}
+	micros, err := strconv.Atoi(segment[len(dottedOrderTimeLayout):idx])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid dotted order %q: %w", dottedOrder, err)
+	}
+	return t.Add(time.Duration(micros) * time.Microsecond), nil
+}
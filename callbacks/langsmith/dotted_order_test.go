@@ -0,0 +1,107 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDottedOrder 测试 dotted order 格式与官方 SDK 一致 (%Y%m%dT%H%M%S%fZ<run id>)
+func TestDottedOrder(t *testing.T) {
+	start := time.Date(2025, 1, 2, 3, 4, 5, 123456789, time.FixedZone("UTC+8", 8*3600))
+	root := dottedOrder("", start, "root")
+	assert.Equal(t, "20250101T190405123456Zroot", root)
+
+	child := dottedOrder(root, start.Add(time.Millisecond), "child")
+	assert.Equal(t, "20250101T190405123456Zroot.20250101T190405124456Zchild", child)
+
+	traceID, runID, err := parseDottedOrder(child)
+	require.NoError(t, err)
+	assert.Equal(t, "root", traceID)
+	assert.Equal(t, "child", runID)
+
+	parsed, err := dottedOrderStartTime(child)
+	require.NoError(t, err)
+	assert.True(t, parsed.Equal(start.Add(time.Millisecond).Truncate(time.Microsecond)))
+
+	_, err = dottedOrderStartTime("parent.dotted.order")
+	assert.Error(t, err)
+	_, err = dottedOrderStartTime("20250101T190405abcdefZroot")
+	assert.Error(t, err)
+}
+
+// TestRunStartTime 测试同一微秒内启动的兄弟节点保持执行顺序, 且子节点晚于父节点
+func TestRunStartTime(t *testing.T) {
+	rootStart, _ := runStartTime("")
+	parent := dottedOrder("", rootStart, "parent")
+
+	var children []string
+	for i := 0; i < 100; i++ {
+		start, _ := runStartTime(parent)
+		assert.Equal(t, start, start.Truncate(time.Microsecond))
+		children = append(children, dottedOrder(parent, start, strings.Repeat("z", 100-i)))
+	}
+	assert.True(t, sort.StringsAreSorted(children))
+	assert.Less(t, parent, children[0])
+
+	// the parent was started on a host whose clock is ahead
+	future := time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+	start, _ := runStartTime(dottedOrder("", future, "remote"))
+	assert.Equal(t, future.Add(time.Microsecond), start)
+}
+
+// TestStateNow 测试结束时间由单调时钟推导, 不受墙上时钟跳变影响
+func TestStateNow(t *testing.T) {
+	startTime, started := runStartTime("")
+	// the wall clock jumped an hour back since the run started
+	state := &LangsmithState{startTime: startTime.Add(time.Hour), started: started}
+	end := state.now()
+	assert.False(t, end.Before(state.startTime))
+	assert.WithinDuration(t, state.startTime, end, time.Second)
+
+	var nilState *LangsmithState
+	assert.WithinDuration(t, time.Now(), nilState.now(), time.Second)
+	assert.WithinDuration(t, time.Now(), (&LangsmithState{}).now(), time.Second)
+}
+
+func TestDottedOrderFormat(t *testing.T) {
+	for _, ts := range []time.Time{
+		time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		time.Date(2025, 12, 31, 23, 59, 59, 999999999, time.UTC),
+		time.Date(2025, 6, 7, 8, 9, 10, 42000, time.FixedZone("UTC+8", 8*3600)),
+	} {
+		u := ts.UTC()
+		want := fmt.Sprintf("%s%06dZ%s", u.Format(dottedOrderTimeLayout), u.Nanosecond()/int(time.Microsecond), "run-1")
+		assert.Equal(t, want, dottedOrder("", ts, "run-1"))
+		assert.Equal(t, "parent."+want, dottedOrder("parent", ts, "run-1"))
+	}
+}
+
+func BenchmarkDottedOrder(b *testing.B) {
+	start := time.Now()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = dottedOrder("20250102T030405000000Zparent", start, "01a14bf4-0597-736a-84d8-bcd74837e3dd")
+	}
+}
@@ -0,0 +1,252 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidRun is returned for runs failing ValidateRun, match it with errors.Is.
+var ErrInvalidRun = errors.New("invalid langsmith run")
+
+// ValidateRun checks that run is well formed for langsmith: its id, name, run type and start time are set, and its
+// dotted order is consistent with its id, trace id, parent run id and start time.
+func ValidateRun(run *Run) error {
+	switch {
+	case run.ID == "":
+		return fmt.Errorf("%w: missing id", ErrInvalidRun)
+	case run.Name == "":
+		return fmt.Errorf("%w: missing name of run %s", ErrInvalidRun, run.ID)
+	case run.RunType == "":
+		return fmt.Errorf("%w: missing run type of run %s", ErrInvalidRun, run.ID)
+	case run.StartTime.IsZero():
+		return fmt.Errorf("%w: missing start time of run %s", ErrInvalidRun, run.ID)
+	case run.EndTime != nil && run.EndTime.Before(run.StartTime):
+		return fmt.Errorf("%w: run %s ends before it starts", ErrInvalidRun, run.ID)
+	}
+	if run.DottedOrder == "" {
+		return nil
+	}
+	traceID, runID, err := parseDottedOrder(run.DottedOrder)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRun, err)
+	}
+	if runID != run.ID {
+		return fmt.Errorf("%w: dotted order %q doesn't end with run %s", ErrInvalidRun, run.DottedOrder, run.ID)
+	}
+	if run.TraceID != "" && traceID != run.TraceID {
+		return fmt.Errorf("%w: dotted order %q doesn't start with trace %s", ErrInvalidRun, run.DottedOrder, run.TraceID)
+	}
+	segments := strings.Split(run.DottedOrder, ".")
+	if run.ParentRunID == nil {
+		if len(segments) != 1 {
+			return fmt.Errorf("%w: dotted order %q of root run %s has ancestors", ErrInvalidRun, run.DottedOrder, run.ID)
+		}
+	} else {
+		if len(segments) < 2 || !strings.HasSuffix(segments[len(segments)-2], "Z"+*run.ParentRunID) {
+			return fmt.Errorf("%w: dotted order %q doesn't match parent run %s", ErrInvalidRun, run.DottedOrder, *run.ParentRunID)
+		}
+	}
+	start, err := dottedOrderStartTime(run.DottedOrder)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRun, err)
+	}
+	if !start.Equal(run.StartTime.UTC().Truncate(time.Microsecond)) {
+		return fmt.Errorf("%w: dotted order %q doesn't match start time %s of run %s", ErrInvalidRun, run.DottedOrder,
+			run.StartTime.Format(time.RFC3339Nano), run.ID)
+	}
+	return nil
+}
+
+// dryRunLangsmith validates and serializes runs as the client would send them, logs the request bodies at debug
+// level and hands the runs to next instead of sending them. The other requests are logged and answered locally too:
+// created resources are returned with a generated id, reads find nothing, and the requests returning a url fail with
+// ErrNotSupported.
+type dryRunLangsmith struct {
+	cli  *langsmithClient
+	next RunExporter // optional
+}
+
+func (e *dryRunLangsmith) CreateRun(ctx context.Context, run *Run) error {
+	if err := ValidateRun(run); err != nil {
+		e.cli.logger.Warn(ctx, "langsmith dry run: invalid run", "run_id", run.ID, "err", err)
+		return err
+	}
+	body, err := e.cli.marshaler.Marshal(e.cli.encodeRun(ctx, run))
+	if err != nil {
+		return fmt.Errorf("failed to marshal run: %w", err)
+	}
+	e.cli.logger.Debug(ctx, "langsmith dry run", "op", ExportOpCreate, "run_id", run.ID, "body", string(body))
+	if e.next != nil {
+		return e.next.CreateRun(ctx, run)
+	}
+	return nil
+}
+
+func (e *dryRunLangsmith) UpdateRun(ctx context.Context, runID string, patch *RunPatch) error {
+	if runID == "" {
+		e.cli.logger.Warn(ctx, "langsmith dry run: invalid run", "err", "missing run id")
+		return fmt.Errorf("%w: missing id of updated run", ErrInvalidRun)
+	}
+	body, err := e.cli.marshaler.Marshal(e.cli.encodePatch(patch))
+	if err != nil {
+		return fmt.Errorf("failed to marshal run patch: %w", err)
+	}
+	e.cli.logger.Debug(ctx, "langsmith dry run", "op", ExportOpUpdate, "run_id", runID, "body", string(body))
+	if e.next != nil {
+		return e.next.UpdateRun(ctx, runID, patch)
+	}
+	return nil
+}
+
+// skip logs a request the dry run doesn't send.
+func (e *dryRunLangsmith) skip(ctx context.Context, op APIOp, method, path string, body interface{}) {
+	kvs := []interface{}{"op", op, "request", method + " " + path}
+	if body != nil {
+		if data, err := e.cli.marshaler.Marshal(body); err == nil {
+			kvs = append(kvs, "body", string(data))
+		}
+	}
+	e.cli.logger.Debug(ctx, "langsmith dry run", kvs...)
+}
+
+// notFound is the error of the reads, nothing is read in a dry run.
+func notFound(what string) error {
+	return fmt.Errorf("%w: %s isn't read in a dry run", ErrNotFound, what)
+}
+
+// notSupported is the error of the requests returning a url, which only langsmith can create.
+func notSupported(what string) error {
+	return fmt.Errorf("%w: %s isn't created in a dry run", ErrNotSupported, what)
+}
+
+func (e *dryRunLangsmith) CreateFeedback(ctx context.Context, feedback *Feedback) (*Feedback, error) {
+	if feedback == nil || feedback.Key == "" {
+		return nil, fmt.Errorf("feedback key is required")
+	}
+	e.skip(ctx, opFeedback, "POST", "/feedback", feedback)
+	created := *feedback
+	created.ID = uuid.NewString()
+	return &created, nil
+}
+
+func (e *dryRunLangsmith) UpdateFeedback(ctx context.Context, feedbackID string, update *FeedbackUpdate) error {
+	e.skip(ctx, opFeedback, "PATCH", "/feedback/"+feedbackID, update)
+	return nil
+}
+
+func (e *dryRunLangsmith) DeleteFeedback(ctx context.Context, feedbackID string) error {
+	e.skip(ctx, opFeedback, "DELETE", "/feedback/"+feedbackID, nil)
+	return nil
+}
+
+func (e *dryRunLangsmith) CreateFeedbackToken(ctx context.Context, runID, feedbackKey string, opts *FeedbackTokenOptions) (*FeedbackToken, error) {
+	e.skip(ctx, opFeedback, "POST", "/feedback/tokens", map[string]interface{}{"run_id": runID, "feedback_key": feedbackKey})
+	return nil, notSupported("feedback token")
+}
+
+func (e *dryRunLangsmith) CreateDataset(ctx context.Context, dataset *Dataset) (*Dataset, error) {
+	if dataset == nil || dataset.Name == "" {
+		return nil, fmt.Errorf("dataset name is required")
+	}
+	e.skip(ctx, opDataset, "POST", "/datasets", dataset)
+	created := *dataset
+	created.ID = uuid.NewString()
+	return &created, nil
+}
+
+func (e *dryRunLangsmith) ReadDataset(ctx context.Context, name string) (*Dataset, error) {
+	return nil, notFound("dataset " + name)
+}
+
+func (e *dryRunLangsmith) CreateExample(ctx context.Context, example *Example) (*Example, error) {
+	if example == nil || example.DatasetID == "" {
+		return nil, fmt.Errorf("example dataset id is required")
+	}
+	e.skip(ctx, opDataset, "POST", "/examples", example)
+	created := *example
+	created.ID = uuid.NewString()
+	return &created, nil
+}
+
+func (e *dryRunLangsmith) ListExamples(ctx context.Context, opts *ListExamplesOptions) ([]*Example, error) {
+	return nil, nil
+}
+
+func (e *dryRunLangsmith) UpdateExample(ctx context.Context, exampleID string, update *ExampleUpdate) error {
+	e.skip(ctx, opDataset, "PATCH", "/examples/"+exampleID, update)
+	return nil
+}
+
+func (e *dryRunLangsmith) PullPrompt(ctx context.Context, identifier string) (*PromptCommit, error) {
+	return nil, notFound("prompt " + identifier)
+}
+
+func (e *dryRunLangsmith) PushPrompt(ctx context.Context, identifier string, manifest map[string]interface{}, opts *PushPromptOptions) (string, error) {
+	e.skip(ctx, opPrompt, "POST", "/commits/"+identifier, map[string]interface{}{"manifest": manifest})
+	return "", nil
+}
+
+func (e *dryRunLangsmith) CreateProject(ctx context.Context, project *Project) (*Project, error) {
+	if project == nil || project.Name == "" {
+		return nil, fmt.Errorf("project name is required")
+	}
+	e.skip(ctx, opProject, "POST", "/sessions", project)
+	created := *project
+	created.ID = uuid.NewString()
+	return &created, nil
+}
+
+func (e *dryRunLangsmith) ReadProject(ctx context.Context, name string) (*Project, error) {
+	return nil, notFound("project " + name)
+}
+
+func (e *dryRunLangsmith) ListProjects(ctx context.Context, opts *ListProjectsOptions) ([]*Project, error) {
+	return nil, nil
+}
+
+func (e *dryRunLangsmith) ReadRun(ctx context.Context, runID string) (*Run, error) {
+	return nil, notFound("run " + runID)
+}
+
+func (e *dryRunLangsmith) ListRuns(ctx context.Context, opts *ListRunsOptions) (*RunsPage, error) {
+	return &RunsPage{}, nil
+}
+
+func (e *dryRunLangsmith) ShareRun(ctx context.Context, runID string) (string, error) {
+	e.skip(ctx, opShare, "PUT", "/runs/"+runID+"/share", nil)
+	return "", notSupported("share url")
+}
+
+func (e *dryRunLangsmith) UnshareRun(ctx context.Context, runID string) error {
+	e.skip(ctx, opShare, "DELETE", "/runs/"+runID+"/share", nil)
+	return nil
+}
+
+func (e *dryRunLangsmith) GetRunURL(ctx context.Context, projectName, runID, traceID string) (string, error) {
+	return "", notSupported("run url")
+}
+
+func (e *dryRunLangsmith) Info(ctx context.Context) (*ServerInfo, error) {
+	return nil, notSupported("server info")
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRun(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 1000, time.UTC)
+	before := start.Add(-time.Second)
+	root, other := "root", "other"
+	valid := func() *Run {
+		return &Run{ID: "child", Name: "tool", RunType: RunTypeTool, StartTime: start, TraceID: "root", ParentRunID: &root,
+			DottedOrder: "20250101T000000000000Zroot.20250101T000000000001Zchild"}
+	}
+	assert.NoError(t, ValidateRun(valid()))
+	assert.NoError(t, ValidateRun(&Run{ID: "root", Name: "graph", RunType: RunTypeChain, StartTime: start}))
+
+	for name, mutate := range map[string]func(*Run){
+		"missing id":        func(r *Run) { r.ID = "" },
+		"missing name":      func(r *Run) { r.Name = "" },
+		"missing run type":  func(r *Run) { r.RunType = "" },
+		"missing start":     func(r *Run) { r.StartTime = time.Time{} },
+		"end before start":  func(r *Run) { r.EndTime = &before },
+		"malformed order":   func(r *Run) { r.DottedOrder = "root" },
+		"other run id":      func(r *Run) { r.ID = "other" },
+		"other trace":       func(r *Run) { r.TraceID = "other" },
+		"other parent":      func(r *Run) { r.ParentRunID = &other },
+		"root with parents": func(r *Run) { r.ParentRunID = nil },
+		"other start":       func(r *Run) { r.StartTime = start.Add(time.Millisecond) },
+	} {
+		run := valid()
+		mutate(run)
+		assert.ErrorIs(t, ValidateRun(run), ErrInvalidRun, name)
+	}
+}
+
+// TestDryRun 测试 dry run 模式不发送任何请求，只记录日志并交给 Exporter
+func TestDryRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer srv.Close()
+	logger := &recordLogger{}
+	buf := &bytes.Buffer{}
+	h, err := NewLangsmithHandler(&Config{APIKey: "test-key", APIURL: srv.URL, DryRun: true, Blocking: true,
+		VerifyConnection: true, AutoCreateProject: &ProjectDefaults{}, Logger: logger, Exporter: NewConsoleExporter(buf)})
+	require.NoError(t, err)
+
+	ctx := h.OnStart(context.Background(), &callbacks.RunInfo{Name: "graph", Component: "Graph"}, "input")
+	h.OnEnd(ctx, &callbacks.RunInfo{Name: "graph", Component: "Graph"}, "output")
+	assert.True(t, strings.HasPrefix(buf.String(), "graph [chain]"), buf.String())
+
+	var logged []string
+	for _, entry := range logger.entries {
+		if strings.HasPrefix(entry, "DEBUG langsmith dry run [") {
+			logged = append(logged, entry)
+		}
+	}
+	require.Len(t, logged, 2)
+	assert.Contains(t, logged[0], "[op create run_id ")
+	assert.Contains(t, logged[0], `"inputs":`)
+	assert.Contains(t, logged[1], "[op update run_id ")
+
+	// invalid runs are reported
+	cli := (&Config{APIURL: srv.URL, DryRun: true, Logger: logger}).newClient()
+	err = cli.CreateRun(context.Background(), &Run{ID: "run-1"})
+	assert.True(t, errors.Is(err, ErrInvalidRun))
+	assert.Contains(t, logger.entries[len(logger.entries)-1], "WARN langsmith dry run: invalid run")
+}
+
+// TestDryRunAPIRequests 测试 dry run 模式下评估器 feedback 与 AddRunToDataset 不发送请求
+func TestDryRunAPIRequests(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer srv.Close()
+	logger := &recordLogger{}
+	evaluated := make(chan struct{}, 1)
+	client, err := NewClient(&Config{APIKey: "test-key", APIURL: srv.URL, DryRun: true, Blocking: true, Logger: logger,
+		Evaluators: []RunEvaluator{func(ctx context.Context, run *Run) (*Feedback, error) {
+			evaluated <- struct{}{}
+			return &Feedback{Key: "correct", Score: Score(1)}, nil
+		}},
+	})
+	require.NoError(t, err)
+	h := client.Handler()
+
+	info := &callbacks.RunInfo{Name: "graph", Component: "Graph"}
+	ctx := h.OnStart(context.Background(), info, "input")
+	require.NoError(t, AddRunToDataset(ctx, "golden"))
+	h.OnEnd(ctx, info, "output")
+	<-evaluated
+
+	logged := func(request string) bool {
+		logger.mu.Lock()
+		defer logger.mu.Unlock()
+		for _, entry := range logger.entries {
+			if strings.HasPrefix(entry, "DEBUG langsmith dry run [") && strings.Contains(entry, "request "+request) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, request := range []string{"POST /feedback", "POST /datasets", "POST /examples"} {
+		assert.Eventually(t, func() bool { return logged(request) }, time.Second, 5*time.Millisecond, request)
+	}
+
+	api := client.API()
+	fb, err := api.(FeedbackClient).CreateFeedback(ctx, &Feedback{Key: "thumbs"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, fb.ID)
+	_, err = api.(DatasetClient).ReadDataset(ctx, "golden")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = h.GetShareURL(ctx)
+	assert.ErrorIs(t, err, ErrNotSupported)
+	assert.Zero(t, atomic.LoadInt32(&requests))
+}
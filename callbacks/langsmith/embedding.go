@@ -0,0 +1,69 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/embedding"
+)
+
+// EmbeddingPreviewSize is the number of leading values of each vector kept in the outputs of embedding runs, unless
+// Config.FullEmbeddings is set.
+const EmbeddingPreviewSize = 8
+
+// embeddingVector summarizes a vector returned by an embedding component.
+type embeddingVector struct {
+	Dimensions int       `json:"dimensions"`
+	Preview    []float64 `json:"preview"` // first EmbeddingPreviewSize values
+}
+
+// embeddingInputs returns the run inputs of an embedding run: the embedded texts. nil for other components.
+func embeddingInputs(info *callbacks.RunInfo, input callbacks.CallbackInput, limit int) map[string]interface{} {
+	if info.Component != components.ComponentOfEmbedding {
+		return nil
+	}
+	in := embedding.ConvCallbackInput(input)
+	if in == nil {
+		return nil
+	}
+	return map[string]interface{}{"texts": limitPayload(in.Texts, limit)}
+}
+
+// embeddingOutputs returns the run outputs of an embedding run: the dimensions and a preview of each vector, or the
+// full vectors if full is set. nil for other components.
+func embeddingOutputs(info *callbacks.RunInfo, output callbacks.CallbackOutput, full bool, limit int) map[string]interface{} {
+	if info.Component != components.ComponentOfEmbedding {
+		return nil
+	}
+	out := embedding.ConvCallbackOutput(output)
+	if out == nil {
+		return nil
+	}
+	if full {
+		return map[string]interface{}{"embeddings": limitPayload(out.Embeddings, limit)}
+	}
+	vectors := make([]embeddingVector, 0, len(out.Embeddings))
+	for _, vector := range out.Embeddings {
+		preview := vector
+		if len(preview) > EmbeddingPreviewSize {
+			preview = preview[:EmbeddingPreviewSize]
+		}
+		vectors = append(vectors, embeddingVector{Dimensions: len(vector), Preview: preview})
+	}
+	return map[string]interface{}{"embeddings": vectors}
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEmbeddingRunCapture 测试嵌入运行只记录向量维度与预览
+func TestEmbeddingRunCapture(t *testing.T) {
+	h := &CallbackHandler{cfg: &Config{}}
+	info := &callbacks.RunInfo{Component: components.ComponentOfEmbedding}
+
+	inputs, err := h.runInputs(info, &embedding.CallbackInput{Texts: []string{"hello", "world"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hello", "world"}, inputs["texts"])
+
+	vector := make([]float64, 1536)
+	for i := range vector {
+		vector[i] = float64(i)
+	}
+	output := &embedding.CallbackOutput{Embeddings: [][]float64{vector, {0.5}}}
+	outputs, err := h.runOutputs(info, output)
+	assert.NoError(t, err)
+	assert.Equal(t, []embeddingVector{
+		{Dimensions: 1536, Preview: []float64{0, 1, 2, 3, 4, 5, 6, 7}},
+		{Dimensions: 1, Preview: []float64{0.5}},
+	}, outputs["embeddings"])
+
+	h.cfg.FullEmbeddings = true
+	outputs, err = h.runOutputs(info, output)
+	assert.NoError(t, err)
+	assert.Equal(t, output.Embeddings, outputs["embeddings"])
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"os"
+	"strings"
+)
+
+// Environment variables read by ConfigFromEnv, the LANGCHAIN_* variants are accepted as fallbacks like the Python/JS SDKs.
+const (
+	EnvAPIKey      = "LANGSMITH_API_KEY"
+	EnvEndpoint    = "LANGSMITH_ENDPOINT"
+	EnvProject     = "LANGSMITH_PROJECT"
+	EnvTracing     = "LANGSMITH_TRACING"
+	EnvWorkspaceID = "LANGSMITH_WORKSPACE_ID"
+)
+
+var envFallbacks = map[string][]string{
+	EnvAPIKey:   {"LANGCHAIN_API_KEY"},
+	EnvEndpoint: {"LANGCHAIN_ENDPOINT"},
+	EnvProject:  {"LANGCHAIN_PROJECT"},
+	EnvTracing:  {"LANGSMITH_TRACING_V2", "LANGCHAIN_TRACING_V2"},
+}
+
+// ConfigFromEnv builds a Config from LANGSMITH_API_KEY, LANGSMITH_ENDPOINT, LANGSMITH_PROJECT, LANGSMITH_WORKSPACE_ID
+// and LANGSMITH_TRACING.
+// like the Python/JS SDKs, tracing is only enabled when LANGSMITH_TRACING is set to true, the config is Disabled otherwise.
+func ConfigFromEnv() *Config {
+	cfg := &Config{
+		APIKey:      lookupEnv(EnvAPIKey),
+		APIURL:      lookupEnv(EnvEndpoint),
+		SessionName: lookupEnv(EnvProject),
+		WorkspaceID: lookupEnv(EnvWorkspaceID),
+	}
+	cfg.Disabled = !strings.EqualFold(lookupEnv(EnvTracing), "true")
+	return cfg
+}
+
+// NewLangsmithHandlerFromEnv creates a CallbackHandler configured by environment variables, see ConfigFromEnv.
+func NewLangsmithHandlerFromEnv() (*CallbackHandler, error) {
+	return NewLangsmithHandler(ConfigFromEnv())
+}
+
+func lookupEnv(key string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	for _, fallback := range envFallbacks[key] {
+		if v := strings.TrimSpace(os.Getenv(fallback)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("LANGSMITH_API_KEY", "ls-key")
+	t.Setenv("LANGCHAIN_ENDPOINT", "https://eu.api.smith.langchain.com")
+	t.Setenv("LANGSMITH_PROJECT", "my-project")
+	t.Setenv("LANGCHAIN_PROJECT", "ignored")
+	t.Setenv("LANGSMITH_WORKSPACE_ID", "ws-1")
+	for _, key := range []string{"LANGSMITH_TRACING", "LANGSMITH_TRACING_V2", "LANGCHAIN_TRACING_V2"} {
+		t.Setenv(key, "")
+	}
+
+	cfg := ConfigFromEnv()
+	assert.Equal(t, "ls-key", cfg.APIKey)
+	assert.Equal(t, "https://eu.api.smith.langchain.com", cfg.APIURL)
+	assert.Equal(t, "my-project", cfg.SessionName)
+	assert.Equal(t, "ws-1", cfg.WorkspaceID)
+	assert.True(t, cfg.Disabled, "tracing is off unless enabled")
+
+	t.Setenv("LANGCHAIN_TRACING", "true")
+	assert.True(t, ConfigFromEnv().Disabled, "the variable of the v1 tracer is ignored")
+	t.Setenv("LANGCHAIN_TRACING_V2", "true")
+	assert.False(t, ConfigFromEnv().Disabled)
+	t.Setenv("LANGSMITH_TRACING", "1")
+	assert.True(t, ConfigFromEnv().Disabled)
+	t.Setenv("LANGSMITH_TRACING", "TRUE")
+	assert.False(t, ConfigFromEnv().Disabled)
+}
+
+func TestNewLangsmithHandlerFromEnv(t *testing.T) {
+	t.Setenv("LANGSMITH_API_KEY", "ls-key")
+	t.Setenv("LANGSMITH_TRACING", "false")
+
+	h, err := NewLangsmithHandlerFromEnv()
+	require.NoError(t, err)
+	defer h.Shutdown(context.Background())
+	assert.False(t, h.Needed(context.Background(), &callbacks.RunInfo{}, callbacks.TimingOnStart))
+}
+
+func TestSessionNameFallback(t *testing.T) {
+	cfg := &Config{SessionName: "default-project"}
+	assert.Equal(t, "default-project", cfg.sessionName(&traceOptions{}))
+	assert.Equal(t, "trace-project", cfg.sessionName(&traceOptions{SessionName: "trace-project"}))
+}
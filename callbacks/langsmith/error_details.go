@@ -0,0 +1,104 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// ExtraErrorDetails is the key of the ErrorDetails of a failed run in its extra.
+const ExtraErrorDetails = "error_details"
+
+// maxErrorChain bounds the causes recorded for an error, in case an Unwrap implementation loops.
+const maxErrorChain = 32
+
+// ErrorDetails describes the error of a failed run beyond its message, see ExtraErrorDetails.
+type ErrorDetails struct {
+	Type    string       `json:"type"`            // go type of the error, e.g. *fs.PathError
+	Message string       `json:"message"`         // err.Error()
+	Chain   []ErrorCause `json:"chain,omitempty"` // the errors it wraps, depth first, see errors.Unwrap
+	Stack   string       `json:"stack,omitempty"` // set with Config.ErrorStackTrace
+}
+
+// ErrorCause is an error wrapped by the error of a run.
+type ErrorCause struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// errorDetails returns the details of err and the summary reported as the error of the run: the message of the error,
+// then the type and message of every cause, one per line.
+func (c *Config) errorDetails(err error) (*ErrorDetails, string) {
+	details := &ErrorDetails{
+		Type:    fmt.Sprintf("%T", err),
+		Message: err.Error(),
+		Chain:   errorChain(err),
+	}
+	if c != nil && c.ErrorStackTrace {
+		details.Stack = errorStack(err)
+	}
+	var sb strings.Builder
+	sb.WriteString(details.Message)
+	for _, cause := range details.Chain {
+		sb.WriteString("\ncaused by " + cause.Type + ": " + cause.Message)
+	}
+	return details, sb.String()
+}
+
+func errorChain(err error) []ErrorCause {
+	var chain []ErrorCause
+	var walk func(err error)
+	walk = func(err error) {
+		for _, cause := range unwrapError(err) {
+			if cause == nil || len(chain) >= maxErrorChain {
+				continue
+			}
+			chain = append(chain, ErrorCause{Type: fmt.Sprintf("%T", cause), Message: cause.Error()})
+			walk(cause)
+		}
+	}
+	walk(err)
+	return chain
+}
+
+// unwrapError returns the errors wrapped by err, joined errors included.
+func unwrapError(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	if cause := errors.Unwrap(err); cause != nil {
+		return []error{cause}
+	}
+	return nil
+}
+
+// errorStack returns the stack trace recorded by err or one of its causes, e.g. by github.com/pkg/errors, and
+// otherwise the stack of the goroutine reporting the error.
+func errorStack(err error) string {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if _, ok := e.(fmt.Formatter); !ok {
+			continue
+		}
+		if verbose := fmt.Sprintf("%+v", e); verbose != e.Error() {
+			return verbose
+		}
+	}
+	return string(debug.Stack())
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type multiError []error
+
+func (m multiError) Error() string   { return fmt.Sprintf("%d errors", len(m)) }
+func (m multiError) Unwrap() []error { return m }
+
+// stackError records its stack like github.com/pkg/errors
+type stackError struct{ msg string }
+
+func (e *stackError) Error() string { return e.msg }
+func (e *stackError) Format(s fmt.State, verb rune) {
+	if s.Flag('+') {
+		_, _ = io.WriteString(s, e.msg+"\nmain.handler\n\tmain.go:42")
+		return
+	}
+	_, _ = io.WriteString(s, e.msg)
+}
+
+func TestErrorDetails(t *testing.T) {
+	pathErr := &fs.PathError{Op: "open", Path: "/etc/app.yaml", Err: fs.ErrNotExist}
+	err := fmt.Errorf("load config: %w", pathErr)
+
+	details, summary := (&Config{}).errorDetails(err)
+	assert.Equal(t, "*fmt.wrapError", details.Type)
+	assert.Equal(t, err.Error(), details.Message)
+	assert.Equal(t, []ErrorCause{
+		{Type: "*fs.PathError", Message: "open /etc/app.yaml: file does not exist"},
+		{Type: "*errors.errorString", Message: "file does not exist"},
+	}, details.Chain)
+	assert.Empty(t, details.Stack)
+	assert.Equal(t, "load config: open /etc/app.yaml: file does not exist\n"+
+		"caused by *fs.PathError: open /etc/app.yaml: file does not exist\n"+
+		"caused by *errors.errorString: file does not exist", summary)
+
+	details, summary = (*Config)(nil).errorDetails(errors.New("boom"))
+	assert.Nil(t, details.Chain)
+	assert.Equal(t, "boom", summary)
+
+	details, _ = (&Config{}).errorDetails(multiError{errors.New("a"), fmt.Errorf("b: %w", io.EOF)})
+	assert.Equal(t, []ErrorCause{
+		{Type: "*errors.errorString", Message: "a"},
+		{Type: "*fmt.wrapError", Message: "b: EOF"},
+		{Type: "*errors.errorString", Message: "EOF"},
+	}, details.Chain)
+
+	cfg := &Config{ErrorStackTrace: true}
+	details, _ = cfg.errorDetails(fmt.Errorf("handle: %w", &stackError{msg: "bad request"}))
+	assert.Equal(t, "bad request\nmain.handler\n\tmain.go:42", details.Stack)
+	details, _ = cfg.errorDetails(errors.New("boom"))
+	assert.Contains(t, details.Stack, "goroutine")
+}
+
+// TestOnErrorDetails 测试 OnError 在 extra 中记录错误详情
+func TestOnErrorDetails(t *testing.T) {
+	mCli := &mockLangsmith{}
+	h := &CallbackHandler{cli: mCli, cfg: &Config{RunIDGen: DefaultRunIDGen}}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	var patch *RunPatch
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		patch = args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	info := &callbacks.RunInfo{Name: "search", Component: components.ComponentOfTool}
+	ctx := h.OnStart(SetTrace(context.Background(), WithThreadID("t1")), info, "in")
+	h.OnError(ctx, info, fmt.Errorf("search: %w", context.DeadlineExceeded))
+
+	require.NotNil(t, patch)
+	assert.Equal(t, "search: context deadline exceeded\ncaused by context.deadlineExceededError: context deadline exceeded", *patch.Error)
+	details := patch.Extra[ExtraErrorDetails].(*ErrorDetails)
+	assert.Equal(t, "*fmt.wrapError", details.Type)
+	assert.Len(t, details.Chain, 1)
+	assert.Equal(t, "t1", patch.Extra["metadata"].(map[string]interface{})[MetadataThreadID], "run metadata is kept")
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Error classes of APIError, match them with errors.Is.
+var (
+	ErrUnauthorized    = errors.New("langsmith request unauthorized")
+	ErrConflict        = errors.New("langsmith resource already exists")
+	ErrPayloadTooLarge = errors.New("langsmith payload too large")
+	ErrRateLimited     = errors.New("langsmith rate limit exceeded")
+	ErrServerError     = errors.New("langsmith server error")
+)
+
+// RequestIDHeader is the response header carrying the id of a langsmith request, to report to langsmith support.
+const RequestIDHeader = "X-Request-Id"
+
+// APIError is returned by the client for a non-2xx response, errors.Is matches it with its error class,
+// e.g. ErrNotFound or ErrRateLimited.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+	RequestID  string
+}
+
+func newAPIError(method, path string, resp *http.Response, body []byte) *APIError {
+	return &APIError{
+		Method:     method,
+		Path:       path,
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		RequestID:  resp.Header.Get(RequestIDHeader),
+	}
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("failed to %s %s, status: %d %s, body: %s", e.Method, e.Path, e.StatusCode,
+		http.StatusText(e.StatusCode), e.Body)
+	if e.RequestID != "" {
+		msg += ", request id: " + e.RequestID
+	}
+	return msg
+}
+
+// Is reports whether target is the error class of the status code.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrPayloadTooLarge:
+		return e.StatusCode == http.StatusRequestEntityTooLarge
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServerError:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// Retryable reports whether the request may succeed when sent again.
+func (e *APIError) Retryable() bool {
+	return isRetryableStatus(e.StatusCode)
+}
+
+// IsRetryable reports whether err is a temporary failure of a langsmith request: a rate limit, a server error, or a
+// network error. Requests rejected by langsmith, such as invalid or oversized payloads, aren't retryable.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// isRejected reports whether err is a definitive rejection by langsmith, sending the request again can't succeed.
+func isRejected(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && !apiErr.Retryable()
+}
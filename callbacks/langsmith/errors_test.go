@@ -0,0 +1,85 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIError(t *testing.T) {
+	status := http.StatusTooManyRequests
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RequestIDHeader, "req-1")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("slow down"))
+	}))
+	defer srv.Close()
+	cli := NewLangsmith("key", srv.URL).(*langsmithClient)
+
+	err := cli.CreateRun(context.Background(), &Run{ID: "run-1"})
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+	assert.Equal(t, "slow down", apiErr.Body)
+	assert.Equal(t, "req-1", apiErr.RequestID)
+	assert.Equal(t, "POST", apiErr.Method)
+	assert.Equal(t, "/runs", apiErr.Path)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.True(t, IsRetryable(err))
+	assert.Contains(t, err.Error(), "429 Too Many Requests")
+	assert.Contains(t, err.Error(), "request id: req-1")
+
+	status = http.StatusRequestEntityTooLarge
+	err = cli.UpdateRun(context.Background(), "run-1", &RunPatch{})
+	assert.ErrorIs(t, err, ErrPayloadTooLarge)
+	assert.False(t, IsRetryable(err))
+
+	status = http.StatusNotFound
+	_, err = cli.ReadRun(context.Background(), "run-1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrConflict)
+}
+
+func TestAPIErrorClasses(t *testing.T) {
+	cases := map[int]error{
+		http.StatusUnauthorized:          ErrUnauthorized,
+		http.StatusForbidden:             ErrUnauthorized,
+		http.StatusConflict:              ErrConflict,
+		http.StatusInternalServerError:   ErrServerError,
+		http.StatusServiceUnavailable:    ErrServerError,
+		http.StatusRequestEntityTooLarge: ErrPayloadTooLarge,
+	}
+	for code, class := range cases {
+		err := fmt.Errorf("wrapped: %w", &APIError{StatusCode: code})
+		assert.ErrorIs(t, err, class, code)
+	}
+
+	assert.False(t, IsRetryable(nil))
+	assert.False(t, IsRetryable(errors.New("failed to marshal")))
+	assert.False(t, IsRetryable(context.Canceled))
+
+	_, err := NewLangsmith("key", "http://127.0.0.1:1").(*langsmithClient).ReadRun(context.Background(), "run-1")
+	assert.True(t, IsRetryable(err))
+}
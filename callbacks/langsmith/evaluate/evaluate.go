@@ -0,0 +1,382 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package evaluate runs an eino Runnable over a LangSmith dataset and records the results as an experiment,
+// it's the Go equivalent of langsmith.evaluate() of the Python SDK.
+package evaluate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/compose"
+	"github.com/google/uuid"
+
+	"github.com/cloudwego/eino-ext/callbacks/langsmith"
+)
+
+const (
+	defaultRunName     = "Target"
+	listExamplesPageSz = 100
+)
+
+// Evaluator scores a single example run, a nil Feedback means nothing to report.
+// Feedback.RunID is filled by the harness.
+type Evaluator func(ctx context.Context, run *Run) (*langsmith.Feedback, error)
+
+// Run is the execution of the target on one example, as seen by an Evaluator.
+type Run struct {
+	Example    *langsmith.Example
+	Repetition int                    // index of the repetition of the example, from 0, see Config.Repetitions
+	RunID      string                 // id of the experiment run in LangSmith
+	Outputs    map[string]interface{} // converted outputs of the target, nil if it failed
+	Error      error                  // error returned by the target
+}
+
+// ExampleResult is the outcome of one example.
+type ExampleResult struct {
+	*Run
+	Feedback       []*langsmith.Feedback // feedback posted to LangSmith
+	EvaluatorError []error               // errors of evaluators, or of posting their feedback
+}
+
+// Results is the outcome of Evaluate.
+type Results struct {
+	ExperimentName string
+	ExperimentID   string // id of the experiment project
+	DatasetID      string
+	Results        []*ExampleResult // ordered by example, then by repetition
+}
+
+// Config of Evaluate.
+type Config[I, O any] struct {
+	// Langsmith is the config used to reach LangSmith, required.
+	Langsmith *langsmith.Config
+	// Handler traces the internals of the runnable, leave it nil if it's already a global handler.
+	Handler *langsmith.CallbackHandler
+	// DatasetName is the dataset to evaluate on, required.
+	DatasetName string
+	// ExperimentName is the project the experiment runs are logged to. default: "<DatasetName>-<8 random hex digits>"
+	ExperimentName string
+	// ExperimentDescription and ExperimentMetadata describe the experiment, e.g. the model and prompt version compared.
+	// The metadata also records the dataset name, repetitions and concurrency.
+	ExperimentDescription string
+	ExperimentMetadata    map[string]interface{}
+	// Repetitions is how many times every example is run, to measure the variance of the target. default: 1
+	Repetitions int
+	// MaxConcurrency is how many example runs are executed in parallel. default: 1
+	MaxConcurrency int
+	// ExampleTimeout bounds every invocation of the runnable, a timed out run fails with context.DeadlineExceeded.
+	// 0 means no timeout.
+	ExampleTimeout time.Duration
+	// RunName is the name of the root run of each example. default: Target
+	RunName string
+	// ToInput converts an example into the runnable input, required.
+	ToInput func(example *langsmith.Example) (I, error)
+	// ToOutputs converts the runnable output into LangSmith outputs. default: {"output": out}
+	ToOutputs func(out O) map[string]interface{}
+	// Evaluators score every example run, their feedback is posted to LangSmith.
+	Evaluators []Evaluator
+	// InvokeOptions are passed to every Invoke of the runnable.
+	InvokeOptions []compose.Option
+}
+
+// Evaluate invokes runnable on every example of the dataset, logs each invocation as a run of the experiment with
+// reference_example_id set, and posts the feedback of the evaluators. The experiment is created as a project
+// referencing the dataset, so it shows up in the experiments view of the dataset.
+func Evaluate[I, O any](ctx context.Context, runnable compose.Runnable[I, O], cfg *Config[I, O]) (*Results, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	client, err := langsmith.NewClient(cfg.Langsmith)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Shutdown(context.Background()) }()
+	cli, ok := client.API().(apiClient)
+	if !ok {
+		return nil, fmt.Errorf("%w: evaluation", langsmith.ErrNotSupported)
+	}
+	e := &evaluation[I, O]{
+		cfg: cfg,
+		cli: cli,
+		ft:  client.FlowTrace(),
+		r:   runnable,
+	}
+
+	dataset, err := e.cli.ReadDataset(ctx, cfg.DatasetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+	examples, err := listAllExamples(ctx, e.cli, dataset.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list examples: %w", err)
+	}
+
+	results := &Results{
+		ExperimentName: cfg.experimentName(),
+		DatasetID:      dataset.ID,
+	}
+	experiment, err := e.createExperiment(ctx, results.ExperimentName, dataset.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create experiment: %w", err)
+	}
+	results.ExperimentID = experiment.ID
+
+	results.Results, err = e.runExamples(ctx, results.ExperimentName, examples)
+	if err != nil {
+		return results, err
+	}
+
+	if cfg.Handler != nil {
+		if err = cfg.Handler.Flush(ctx); err != nil {
+			return results, fmt.Errorf("failed to flush handler: %w", err)
+		}
+	}
+	return results, nil
+}
+
+// apiClient is the part of the langsmith API used by an evaluation.
+type apiClient interface {
+	langsmith.Langsmith
+	langsmith.DatasetClient
+	langsmith.ProjectClient
+	langsmith.FeedbackClient
+}
+
+type evaluation[I, O any] struct {
+	cfg *Config[I, O]
+	cli apiClient
+	ft  *langsmith.FlowTrace
+	r   compose.Runnable[I, O]
+}
+
+// createExperiment creates the project of the experiment, or reuses it if it already exists.
+func (e *evaluation[I, O]) createExperiment(ctx context.Context, name, datasetID string) (*langsmith.Project, error) {
+	metadata := map[string]interface{}{
+		"dataset_name":    e.cfg.DatasetName,
+		"num_repetitions": e.cfg.repetitions(),
+		"max_concurrency": e.cfg.maxConcurrency(),
+	}
+	for k, v := range e.cfg.ExperimentMetadata {
+		metadata[k] = v
+	}
+	startTime := time.Now().UTC()
+	project, err := e.cli.CreateProject(ctx, &langsmith.Project{
+		Name:               name,
+		Description:        e.cfg.ExperimentDescription,
+		Extra:              map[string]interface{}{"metadata": metadata},
+		ReferenceDatasetID: datasetID,
+		StartTime:          &startTime,
+	})
+	if err == nil {
+		return project, nil
+	}
+	if existing, readErr := e.cli.ReadProject(ctx, name); readErr == nil {
+		return existing, nil
+	}
+	return nil, err
+}
+
+// runExamples runs every repetition of every example with up to Config.MaxConcurrency runs in parallel. The first
+// error stops scheduling new runs, it's returned with the results of the runs completed so far.
+func (e *evaluation[I, O]) runExamples(ctx context.Context, experiment string, examples []*langsmith.Example) ([]*ExampleResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	repetitions := e.cfg.repetitions()
+	results := make([]*ExampleResult, len(examples)*repetitions)
+	sem := make(chan struct{}, e.cfg.maxConcurrency())
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+schedule:
+	for i, example := range examples {
+		for repetition := 0; repetition < repetitions; repetition++ {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				break schedule
+			}
+			wg.Add(1)
+			go func(index int, example *langsmith.Example, repetition int) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				res, err := e.runExample(ctx, experiment, example, repetition)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+					return
+				}
+				results[index] = res
+			}(i*repetitions+repetition, example, repetition)
+		}
+	}
+	wg.Wait()
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	completed := results[:0]
+	for _, res := range results {
+		if res != nil {
+			completed = append(completed, res)
+		}
+	}
+	return completed, firstErr
+}
+
+func (e *evaluation[I, O]) runExample(ctx context.Context, experiment string, example *langsmith.Example, repetition int) (*ExampleResult, error) {
+	ctx = langsmith.SetTrace(ctx,
+		langsmith.WithSessionName(experiment),
+		langsmith.WithReferenceExampleID(example.ID),
+	)
+	spanOpts := []langsmith.SpanOption{langsmith.WithSpanInputs(example.Inputs)}
+	if e.cfg.repetitions() > 1 {
+		spanOpts = append(spanOpts, langsmith.WithSpanMetadata(map[string]interface{}{"repetition": repetition}))
+	}
+	spanCtx, runID, err := e.ft.StartSpan(ctx, e.cfg.runName(), nil, spanOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create run of example %s: %w", example.ID, err)
+	}
+
+	run := &Run{Example: example, Repetition: repetition, RunID: runID}
+	runCtx := spanCtx
+	if e.cfg.ExampleTimeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(runCtx, e.cfg.ExampleTimeout)
+		defer cancel()
+	}
+	run.Outputs, run.Error = e.invoke(runCtx, example)
+
+	// ended through the FlowTrace, like it was created, so that the exporter, spool and payload limits apply
+	if err = e.ft.FinishSpanWithOutputs(spanCtx, runID, run.Outputs, run.Error); err != nil {
+		return nil, fmt.Errorf("failed to update run of example %s: %w", example.ID, err)
+	}
+
+	res := &ExampleResult{Run: run}
+	for _, evaluator := range e.cfg.Evaluators {
+		fb, err := evaluator(ctx, run)
+		if err != nil {
+			res.EvaluatorError = append(res.EvaluatorError, err)
+			continue
+		}
+		if fb == nil {
+			continue
+		}
+		// the feedback of the evaluator is left untouched, it may be shared by the runs
+		cp := *fb
+		cp.RunID = runID
+		if cp.FeedbackSource == nil {
+			cp.FeedbackSource = &langsmith.FeedbackSource{Type: "model"}
+		}
+		created, err := e.cli.CreateFeedback(ctx, &cp)
+		if err != nil {
+			res.EvaluatorError = append(res.EvaluatorError, fmt.Errorf("failed to post feedback %s: %w", fb.Key, err))
+			continue
+		}
+		res.Feedback = append(res.Feedback, created)
+	}
+	return res, nil
+}
+
+func (e *evaluation[I, O]) invoke(ctx context.Context, example *langsmith.Example) (map[string]interface{}, error) {
+	in, err := e.cfg.ToInput(example)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert example input: %w", err)
+	}
+	opts := e.cfg.InvokeOptions
+	if e.cfg.Handler != nil {
+		opts = append(append([]compose.Option{}, opts...), compose.WithCallbacks(callbacks.Handler(e.cfg.Handler)))
+	}
+	out, err := e.r.Invoke(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if e.cfg.ToOutputs != nil {
+		return e.cfg.ToOutputs(out), nil
+	}
+	return map[string]interface{}{"output": out}, nil
+}
+
+func listAllExamples(ctx context.Context, cli langsmith.DatasetClient, datasetID string) ([]*langsmith.Example, error) {
+	var all []*langsmith.Example
+	for {
+		page, err := cli.ListExamples(ctx, &langsmith.ListExamplesOptions{
+			DatasetID: datasetID,
+			Offset:    len(all),
+			Limit:     listExamplesPageSz,
+		})
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < listExamplesPageSz {
+			return all, nil
+		}
+	}
+}
+
+func (c *Config[I, O]) validate() error {
+	if c == nil || c.Langsmith == nil {
+		return fmt.Errorf("langsmith config is required")
+	}
+	if c.DatasetName == "" {
+		return fmt.Errorf("dataset name is required")
+	}
+	if c.ToInput == nil {
+		return fmt.Errorf("ToInput is required")
+	}
+	return nil
+}
+
+func (c *Config[I, O]) experimentName() string {
+	if c.ExperimentName != "" {
+		return c.ExperimentName
+	}
+	return fmt.Sprintf("%s-%s", c.DatasetName, strings.ReplaceAll(uuid.NewString(), "-", "")[:8])
+}
+
+func (c *Config[I, O]) repetitions() int {
+	if c.Repetitions > 1 {
+		return c.Repetitions
+	}
+	return 1
+}
+
+func (c *Config[I, O]) maxConcurrency() int {
+	if c.MaxConcurrency > 1 {
+		return c.MaxConcurrency
+	}
+	return 1
+}
+
+func (c *Config[I, O]) runName() string {
+	if c.RunName != "" {
+		return c.RunName
+	}
+	return defaultRunName
+}
@@ -0,0 +1,270 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package evaluate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudwego/eino-ext/callbacks/langsmith"
+	"github.com/cloudwego/eino-ext/callbacks/langsmith/langsmithtest"
+)
+
+// fakeLangsmith 模拟 LangSmith 的 datasets/examples/runs/feedback 接口
+type fakeLangsmith struct {
+	mu       sync.Mutex
+	runs     map[string]map[string]interface{}
+	patches  map[string]map[string]interface{}
+	feedback []map[string]interface{}
+	projects []map[string]interface{}
+}
+
+func (f *fakeLangsmith) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var body map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/datasets":
+		_, _ = w.Write([]byte(`[{"id":"ds-1","name":"golden"}]`))
+	case r.Method == "GET" && r.URL.Path == "/examples":
+		_, _ = w.Write([]byte(`[
+			{"id":"ex-1","dataset_id":"ds-1","inputs":{"question":"hello"},"outputs":{"answer":"HELLO"}},
+			{"id":"ex-2","dataset_id":"ds-1","inputs":{"question":"fail"}}
+		]`))
+	case r.Method == "POST" && r.URL.Path == "/runs":
+		f.runs[body["id"].(string)] = body
+		_, _ = w.Write([]byte(`{}`))
+	case r.Method == "PATCH" && strings.HasPrefix(r.URL.Path, "/runs/"):
+		f.patches[strings.TrimPrefix(r.URL.Path, "/runs/")] = body
+	case r.Method == "POST" && r.URL.Path == "/sessions":
+		f.projects = append(f.projects, body)
+		_, _ = w.Write([]byte(`{"id":"exp-id","name":"` + body["name"].(string) + `"}`))
+	case r.Method == "POST" && r.URL.Path == "/feedback":
+		f.feedback = append(f.feedback, body)
+		_, _ = w.Write([]byte(`{"id":"fb"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	fake := &fakeLangsmith{runs: map[string]map[string]interface{}{}, patches: map[string]map[string]interface{}{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	chain := compose.NewChain[string, string]()
+	chain.AppendLambda(compose.InvokableLambda(func(ctx context.Context, in string) (string, error) {
+		if in == "fail" {
+			return "", errors.New("boom")
+		}
+		return strings.ToUpper(in), nil
+	}))
+	runnable, err := chain.Compile(context.Background())
+	require.NoError(t, err)
+
+	exactMatch := func(ctx context.Context, run *Run) (*langsmith.Feedback, error) {
+		if run.Error != nil {
+			return nil, nil
+		}
+		score := 0.0
+		if run.Outputs["output"] == run.Example.Outputs["answer"] {
+			score = 1
+		}
+		return &langsmith.Feedback{Key: "exact_match", Score: langsmith.Score(score)}, nil
+	}
+	failing := func(ctx context.Context, run *Run) (*langsmith.Feedback, error) {
+		return nil, errors.New("evaluator failed")
+	}
+
+	res, err := Evaluate(context.Background(), runnable, &Config[string, string]{
+		Langsmith:      &langsmith.Config{APIKey: "test-key", APIURL: srv.URL},
+		DatasetName:    "golden",
+		ExperimentName: "exp-1",
+		ToInput: func(example *langsmith.Example) (string, error) {
+			return example.Inputs["question"].(string), nil
+		},
+		Evaluators: []Evaluator{exactMatch, failing},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "exp-1", res.ExperimentName)
+	assert.Equal(t, "exp-id", res.ExperimentID)
+	require.Len(t, fake.projects, 1)
+	assert.Equal(t, "ds-1", fake.projects[0]["reference_dataset_id"])
+	assert.Equal(t, map[string]interface{}{"dataset_name": "golden", "num_repetitions": 1.0, "max_concurrency": 1.0},
+		fake.projects[0]["extra"].(map[string]interface{})["metadata"])
+	require.Len(t, res.Results, 2)
+
+	first := res.Results[0]
+	assert.Equal(t, "HELLO", first.Outputs["output"])
+	assert.NoError(t, first.Error)
+	require.Len(t, first.Feedback, 1)
+	assert.Len(t, first.EvaluatorError, 1)
+
+	second := res.Results[1]
+	assert.ErrorContains(t, second.Error, "boom")
+	assert.Empty(t, second.Feedback)
+
+	run := fake.runs[first.RunID]
+	assert.Equal(t, "ex-1", run["reference_example_id"])
+	assert.Equal(t, "exp-1", run["session_name"])
+	assert.Equal(t, map[string]interface{}{"output": "HELLO"}, fake.patches[first.RunID]["outputs"])
+	assert.Contains(t, fake.patches[second.RunID]["error"], "boom")
+
+	require.Len(t, fake.feedback, 1)
+	assert.Equal(t, first.RunID, fake.feedback[0]["run_id"])
+	assert.Equal(t, 1.0, fake.feedback[0]["score"])
+}
+
+// TestEvaluateWithExporter 测试实验的 run 与 Config.Exporter 一起创建和结束，不会单独发送到 LangSmith
+func TestEvaluateWithExporter(t *testing.T) {
+	fake := &fakeLangsmith{runs: map[string]map[string]interface{}{}, patches: map[string]map[string]interface{}{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	runnable, err := compose.NewChain[string, string]().
+		AppendLambda(compose.InvokableLambda(func(ctx context.Context, in string) (string, error) {
+			return strings.ToUpper(in), nil
+		})).
+		Compile(context.Background())
+	require.NoError(t, err)
+
+	exporter := langsmithtest.NewExporter()
+	res, err := Evaluate(context.Background(), runnable, &Config[string, string]{
+		Langsmith:      &langsmith.Config{APIKey: "test-key", APIURL: srv.URL, Exporter: exporter, HideOutputs: true},
+		DatasetName:    "golden",
+		ExperimentName: "exp-1",
+		ToInput: func(example *langsmith.Example) (string, error) {
+			return example.Inputs["question"].(string), nil
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Results, 2)
+	assert.Empty(t, fake.runs)
+	assert.Empty(t, fake.patches)
+	for _, r := range res.Results {
+		patches := exporter.Patches(r.RunID)
+		require.Len(t, patches, 1)
+		assert.NotNil(t, patches[0].EndTime)
+		assert.Equal(t, map[string]interface{}{"output": langsmith.HiddenPlaceholder}, patches[0].Outputs)
+	}
+}
+
+// TestEvaluateSharedFeedback 测试 evaluator 返回的 Feedback 不会被修改，可在多个 run 之间共享
+func TestEvaluateSharedFeedback(t *testing.T) {
+	fake := &fakeLangsmith{runs: map[string]map[string]interface{}{}, patches: map[string]map[string]interface{}{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	runnable, err := compose.NewChain[string, string]().
+		AppendLambda(compose.InvokableLambda(func(ctx context.Context, in string) (string, error) {
+			return in, nil
+		})).
+		Compile(context.Background())
+	require.NoError(t, err)
+
+	shared := &langsmith.Feedback{Key: "ok", Score: langsmith.Score(1)}
+	res, err := Evaluate(context.Background(), runnable, &Config[string, string]{
+		Langsmith:   &langsmith.Config{APIKey: "test-key", APIURL: srv.URL},
+		DatasetName: "golden",
+		ToInput: func(example *langsmith.Example) (string, error) {
+			return example.Inputs["question"].(string), nil
+		},
+		Evaluators: []Evaluator{func(ctx context.Context, run *Run) (*langsmith.Feedback, error) {
+			return shared, nil
+		}},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, shared.RunID)
+	assert.Nil(t, shared.FeedbackSource)
+	require.Len(t, fake.feedback, 2)
+	assert.Equal(t, res.Results[0].RunID, fake.feedback[0]["run_id"])
+	assert.Equal(t, res.Results[1].RunID, fake.feedback[1]["run_id"])
+}
+
+func TestEvaluateValidate(t *testing.T) {
+	_, err := Evaluate[string, string](context.Background(), nil, &Config[string, string]{})
+	assert.Error(t, err)
+}
+
+// TestEvaluateRepetitions 测试重复执行、并发与单例超时
+func TestEvaluateRepetitions(t *testing.T) {
+	fake := &fakeLangsmith{runs: map[string]map[string]interface{}{}, patches: map[string]map[string]interface{}{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	var running, maxRunning int32
+	chain := compose.NewChain[string, string]()
+	chain.AppendLambda(compose.InvokableLambda(func(ctx context.Context, in string) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		if in == "fail" {
+			// slow example, cut by the timeout
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		time.Sleep(10 * time.Millisecond)
+		return strings.ToUpper(in), nil
+	}))
+	runnable, err := chain.Compile(context.Background())
+	require.NoError(t, err)
+
+	res, err := Evaluate(context.Background(), runnable, &Config[string, string]{
+		Langsmith:          &langsmith.Config{APIKey: "test-key", APIURL: srv.URL},
+		DatasetName:        "golden",
+		ExperimentMetadata: map[string]interface{}{"model": "gpt-4o"},
+		Repetitions:        3,
+		MaxConcurrency:     4,
+		ExampleTimeout:     50 * time.Millisecond,
+		ToInput: func(example *langsmith.Example) (string, error) {
+			return example.Inputs["question"].(string), nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Regexp(t, "^golden-[0-9a-f]{8}$", res.ExperimentName)
+	assert.Equal(t, map[string]interface{}{"dataset_name": "golden", "num_repetitions": 3.0, "max_concurrency": 4.0, "model": "gpt-4o"},
+		fake.projects[0]["extra"].(map[string]interface{})["metadata"])
+
+	require.Len(t, res.Results, 6)
+	for i, r := range res.Results {
+		assert.Equal(t, []string{"ex-1", "ex-2"}[i/3], r.Example.ID)
+		assert.Equal(t, i%3, r.Repetition)
+		assert.Equal(t, float64(i%3), fake.runs[r.RunID]["extra"].(map[string]interface{})["metadata"].(map[string]interface{})["repetition"])
+	}
+	assert.Equal(t, "HELLO", res.Results[0].Outputs["output"])
+	assert.ErrorIs(t, res.Results[5].Error, context.DeadlineExceeded)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(4))
+	assert.Greater(t, atomic.LoadInt32(&maxRunning), int32(1))
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+)
+
+// RunEvaluator scores a root run once it ended, e.g. with a regex or length check, or an eino chat model as judge.
+// The returned feedback is attached to the run, nil means no feedback.
+type RunEvaluator func(ctx context.Context, run *Run) (*Feedback, error)
+
+// backgroundTasks tracks the work the handlers of a Client do in the background once a root run ended, running
+// evaluators and adding the run to datasets, so that Flush and Shutdown wait for it. Its methods are safe to call on nil, tasks aren't tracked then.
+type backgroundTasks struct {
+	wg sync.WaitGroup
+}
+
+// run calls fn in a new goroutine.
+func (b *backgroundTasks) run(fn func()) {
+	if b == nil {
+		go fn()
+		return
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		fn()
+	}()
+}
+
+// wait blocks until the tasks started so far are done, or ctx is done.
+func (b *backgroundTasks) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// evaluate runs Config.Evaluators on the ended root run in the background, and posts their feedback.
+func (c *CallbackHandler) evaluate(ctx context.Context, run *Run) {
+	if len(c.cfg.Evaluators) == 0 {
+		return
+	}
+	c.background.run(func() {
+		for _, evaluator := range c.cfg.Evaluators {
+			c.runEvaluator(ctx, evaluator, run)
+		}
+	})
+}
+
+// runEvaluator runs evaluator within its own Config.ExportTimeout, a slow judge doesn't eat the time of the others.
+func (c *CallbackHandler) runEvaluator(ctx context.Context, evaluator RunEvaluator, run *Run) {
+	ctx, cancel := c.cfg.exportContext(ctx)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			c.cfg.logger().Error(ctx, "recovered in run evaluator", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+	feedback, err := evaluator(ctx, run)
+	if err != nil {
+		c.cfg.logger().Error(ctx, "run evaluator error", "err", err, "run_id", run.ID)
+		return
+	}
+	if feedback == nil {
+		return
+	}
+	cp := *feedback
+	feedback = &cp
+	feedback.RunID = run.ID
+	feedback.TraceID = run.TraceID
+	if feedback.FeedbackSource == nil {
+		feedback.FeedbackSource = &FeedbackSource{Type: "model"}
+	}
+	cli, err := apiClient[FeedbackClient](c.cli, "feedback")
+	if err == nil {
+		_, err = cli.CreateFeedback(ctx, feedback)
+	}
+	if err != nil {
+		c.cfg.logger().Error(ctx, "post evaluator feedback error", "err", err, "run_id", run.ID, "key", feedback.Key)
+	}
+}
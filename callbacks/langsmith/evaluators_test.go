@@ -0,0 +1,137 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEvaluators 测试根 run 结束后异步执行评估器并提交 feedback
+func TestEvaluators(t *testing.T) {
+	mCli := &mockLangsmith{}
+	evaluated := make(chan *Run, 4)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{
+		RunIDGen: DefaultRunIDGen,
+		Logger:   &recordLogger{},
+		Evaluators: []RunEvaluator{
+			func(ctx context.Context, run *Run) (*Feedback, error) {
+				evaluated <- run
+				if run.Error != nil {
+					return &Feedback{Key: "succeeded", Score: Score(0)}, nil
+				}
+				return &Feedback{Key: "mentions_paris", Score: Score(map[bool]float64{true: 1}[strings.Contains(run.Outputs["output"].(string), "Paris")])}, nil
+			},
+			func(ctx context.Context, run *Run) (*Feedback, error) { return nil, errors.New("judge unavailable") },
+			func(ctx context.Context, run *Run) (*Feedback, error) { return nil, nil },
+			func(ctx context.Context, run *Run) (*Feedback, error) { panic("bad evaluator") },
+		},
+	}}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	feedbacks := make(chan *Feedback, 4)
+	mCli.On("CreateFeedback", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		feedbacks <- args.Get(1).(*Feedback)
+	}).Return(&Feedback{}, nil)
+
+	graph := &callbacks.RunInfo{Name: "graph", Component: compose.ComponentOfGraph}
+	tool := &callbacks.RunInfo{Name: "tool", Component: components.ComponentOfTool}
+	graphCtx := h.OnStart(context.Background(), graph, "capital of France?")
+	h.OnEnd(h.OnStart(graphCtx, tool, "in"), tool, "out")
+	h.OnEnd(graphCtx, graph, "Paris")
+	_, state := GetState(graphCtx)
+
+	var fb *Feedback
+	select {
+	case fb = <-feedbacks:
+	case <-time.After(time.Second):
+		t.Fatal("no feedback posted")
+	}
+	run := <-evaluated
+	assert.Equal(t, "graph", run.Name, "only the root run is evaluated")
+	assert.Equal(t, state.ParentRunID, run.ID)
+	assert.Contains(t, run.Inputs["input"], "capital of France?")
+	require.NotNil(t, run.EndTime)
+	assert.Equal(t, state.ParentRunID, fb.RunID)
+	assert.Equal(t, state.TraceID, fb.TraceID)
+	assert.Equal(t, "mentions_paris", fb.Key)
+	assert.Equal(t, 1.0, *fb.Score)
+	assert.Equal(t, "model", fb.FeedbackSource.Type)
+
+	graphCtx = h.OnStart(context.Background(), graph, "in")
+	h.OnError(graphCtx, graph, errors.New("boom"))
+	select {
+	case fb = <-feedbacks:
+	case <-time.After(time.Second):
+		t.Fatal("no feedback posted")
+	}
+	assert.Equal(t, "succeeded", fb.Key)
+	assert.Equal(t, "boom", *(<-evaluated).Error)
+	assert.Empty(t, evaluated)
+}
+
+// TestEvaluatorsFlush 测试 Flush 等待评估器提交 feedback，且每个评估器有各自的超时
+func TestEvaluatorsFlush(t *testing.T) {
+	mCli := &mockLangsmith{}
+	secondErr := make(chan error, 1)
+	h := &CallbackHandler{cli: mCli, background: &backgroundTasks{}, cfg: &Config{
+		RunIDGen:      DefaultRunIDGen,
+		ExportTimeout: 30 * time.Millisecond,
+		Evaluators: []RunEvaluator{
+			func(ctx context.Context, run *Run) (*Feedback, error) {
+				// a slow judge using up its timeout
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			func(ctx context.Context, run *Run) (*Feedback, error) {
+				secondErr <- ctx.Err()
+				return &Feedback{Key: "fast"}, nil
+			},
+		},
+	}}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mCli.On("CreateFeedback", mock.Anything, mock.Anything).Return(&Feedback{}, nil)
+
+	graph := &callbacks.RunInfo{Name: "graph", Component: compose.ComponentOfGraph}
+	h.OnEnd(h.OnStart(context.Background(), graph, "in"), graph, "out")
+	require.NoError(t, h.Flush(context.Background()))
+	mCli.AssertCalled(t, "CreateFeedback", mock.Anything, mock.Anything)
+	assert.NoError(t, <-secondErr, "the second evaluator has its own timeout")
+
+	// Flush gives up with ctx
+	block := make(chan struct{})
+	defer close(block)
+	h.cfg.Evaluators = []RunEvaluator{func(ctx context.Context, run *Run) (*Feedback, error) {
+		<-block
+		return nil, nil
+	}}
+	h.OnEnd(h.OnStart(context.Background(), graph, "in"), graph, "out")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, h.Flush(ctx), context.DeadlineExceeded)
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// runEvents collects the events of a run until it ends, it's shared by all contexts derived from the run.
+type runEvents struct {
+	mu     sync.Mutex
+	events []RunEvent
+}
+
+func (e *runEvents) add(event RunEvent) {
+	e.mu.Lock()
+	e.events = append(e.events, event)
+	e.mu.Unlock()
+}
+
+// drain returns the collected events and resets them, it's safe to call on nil.
+func (e *runEvents) drain() []RunEvent {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	events := e.events
+	e.events = nil
+	return events
+}
+
+// AddRunEvent appends a timestamped event to the run ctx is traced in, e.g. RunEventToolSelected or a custom event.
+// events are reported when the run ends and shown on its timeline in langsmith.
+func AddRunEvent(ctx context.Context, name string, kwargs map[string]interface{}) error {
+	_, state := GetState(ctx)
+	if state == nil || state.events == nil {
+		return ErrNoRunInContext
+	}
+	state.events.add(RunEvent{Name: name, Time: time.Now().UTC(), Kwargs: kwargs})
+	return nil
+}
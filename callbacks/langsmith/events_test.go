@@ -0,0 +1,69 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddRunEvent(t *testing.T) {
+	assert.ErrorIs(t, AddRunEvent(context.Background(), "custom", nil), ErrNoRunInContext)
+
+	mCli := new(mockLangsmith)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{RunIDGen: func(ctx context.Context) string { return "run-1" }}}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	var patched *RunPatch
+	mCli.On("UpdateRun", mock.Anything, "run-1", mock.Anything).Run(func(args mock.Arguments) {
+		patched = args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	info := &callbacks.RunInfo{Component: "test"}
+	ctx := h.OnStart(context.Background(), info, callbacks.CallbackInput("in"))
+	require.NoError(t, AddRunEvent(ctx, RunEventToolSelected, map[string]interface{}{"tool": "search"}))
+	require.NoError(t, AddRunEvent(ctx, "custom", nil))
+	h.OnEnd(ctx, info, callbacks.CallbackOutput("out"))
+
+	require.Len(t, patched.Events, 2)
+	assert.Equal(t, RunEventToolSelected, patched.Events[0].Name)
+	assert.Equal(t, "search", patched.Events[0].Kwargs["tool"])
+	assert.Equal(t, "custom", patched.Events[1].Name)
+	assert.False(t, patched.Events[1].Time.Before(patched.Events[0].Time))
+}
+
+func TestFlowTraceRunEvents(t *testing.T) {
+	mCli := new(mockLangsmith)
+	ft := &FlowTrace{cli: mCli, cfg: &Config{RunIDGen: func(ctx context.Context) string { return "span-1" }}}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	var patched *RunPatch
+	mCli.On("UpdateRun", mock.Anything, "span-1", mock.Anything).Run(func(args mock.Arguments) {
+		patched = args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	ctx, runID, err := ft.StartSpan(context.Background(), "span", nil)
+	require.NoError(t, err)
+	require.NoError(t, AddRunEvent(ctx, RunEventRetry, map[string]interface{}{"attempt": 1}))
+	ft.FinishSpan(ctx, runID)
+
+	require.Len(t, patched.Events, 1)
+	assert.Equal(t, RunEventRetry, patched.Events[0].Name)
+}
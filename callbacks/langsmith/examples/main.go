@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"sync"
 
 	"github.com/cloudwego/eino-ext/callbacks/langsmith"
@@ -32,17 +33,20 @@ func main() {
 	// Create a langsmith handler
 	// In a real application, you would get the API key from environment variables or a config file.
 	cfg := &langsmith.Config{
-		APIKey: "xxx",
-		APIURL: "xxx",
-		RunIDGen: func(ctx context.Context) string { // optional. run id generator. default is uuid.NewString
+		APIKey: os.Getenv(langsmith.EnvAPIKey),
+		APIURL: langsmith.DefaultLangsmithAPIURL, // optional. the url of a self-hosted langsmith
+		RunIDGen: func(ctx context.Context) string { // optional. run id generator. default is langsmith.DefaultRunIDGen (uuid v7)
 			return uuid.NewString()
 		},
 	}
-	ft := langsmith.NewFlowTrace(cfg)
-	cbh, err := langsmith.NewLangsmithHandler(cfg)
+	// the handler and the flow trace share the client, its limits and export queue
+	client, err := langsmith.NewClient(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer client.Shutdown(context.Background())
+	ft := client.FlowTrace()
+	cbh := client.Handler()
 
 	// Set langsmith as a global callback handler
 	callbacks.AppendGlobalHandlers(cbh)
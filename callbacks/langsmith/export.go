@@ -0,0 +1,432 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultQueueSize is the default capacity of the async export queue.
+	DefaultQueueSize = 1024
+
+	defaultExportWorkers = 4
+)
+
+// ErrQueueFull is reported to Config.OnError when a run is dropped because the async export queue is full.
+var ErrQueueFull = errors.New("langsmith export queue is full")
+
+// ErrExporterClosed is reported to Config.OnError when a run is exported after the handler was shut down.
+var ErrExporterClosed = errors.New("langsmith exporter is closed")
+
+// exportTask is a single create or update request.
+type exportTask struct {
+	ctx   context.Context
+	op    ExportOp
+	run   *Run      // for ExportOpCreate
+	runID string    // for ExportOpUpdate
+	patch *RunPatch // for ExportOpUpdate
+	start time.Time
+}
+
+func newCreateTask(ctx context.Context, run *Run) *exportTask {
+	return &exportTask{ctx: ctx, op: ExportOpCreate, run: run, runID: run.ID, start: time.Now()}
+}
+
+func newUpdateTask(ctx context.Context, runID string, patch *RunPatch) *exportTask {
+	return &exportTask{ctx: ctx, op: ExportOpUpdate, runID: runID, patch: patch, start: time.Now()}
+}
+
+// execute sends the request, a failure is logged and reported to Config.OnError.
+func (t *exportTask) execute(cli Langsmith, cfg *Config) error {
+	ctx, cancel := cfg.exportContext(t.ctx)
+	defer cancel()
+	var err error
+	switch t.op {
+	case ExportOpCreate:
+		err = cli.CreateRun(ctx, t.run)
+	case ExportOpUpdate:
+		err = cli.UpdateRun(ctx, t.runID, t.patch)
+	}
+	cfg.metrics().ExportFinished(t.op, time.Since(t.start), err)
+	if err != nil {
+		t.fail(cfg, err)
+	}
+	return err
+}
+
+func (t *exportTask) fail(cfg *Config, err error) {
+	if t.op == ExportOpCreate {
+		cfg.logger().Error(t.ctx, "failed to create run", "err", err, "run_id", t.runID)
+		cfg.onExportError(t.ctx, t.run, err)
+		return
+	}
+	cfg.logger().Error(t.ctx, "failed to update run", "err", err, "run_id", t.runID)
+	cfg.onExportError(t.ctx, patchToRun(t.runID, t.patch), err)
+}
+
+// createRun sends run to langsmith synchronously, a failure is logged and reported to Config.OnError.
+func createRun(ctx context.Context, cli Langsmith, cfg *Config, run *Run) error {
+	cfg.metrics().ExportStarted(ExportOpCreate)
+	return newCreateTask(ctx, run).execute(cli, cfg)
+}
+
+// updateRun sends patch of runID to langsmith synchronously, a failure is logged and reported to Config.OnError.
+func updateRun(ctx context.Context, cli Langsmith, cfg *Config, runID string, patch *RunPatch) error {
+	cfg.metrics().ExportStarted(ExportOpUpdate)
+	return newUpdateTask(ctx, runID, patch).execute(cli, cfg)
+}
+
+// asyncExporter exports runs in background through bounded queues.
+// tasks of the same run always go to the same worker, so an update is never sent before its create.
+type asyncExporter struct {
+	cli Langsmith
+	cfg *Config
+
+	mu      sync.RWMutex
+	closed  bool
+	queues  []chan *exportTask
+	pending sync.WaitGroup
+	workers sync.WaitGroup
+
+	// window is Config.PatchCoalesceWindow, queued updates are held for it and later updates of the same run merged
+	window     time.Duration
+	coalesceMu sync.Mutex
+	coalescing map[string]*exportTask // run id -> queued update still accepting merges
+}
+
+func newAsyncExporter(cli Langsmith, cfg *Config, queueSize, workers int) *asyncExporter {
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	if workers <= 0 {
+		workers = defaultExportWorkers
+	}
+	perWorker := queueSize / workers
+	if perWorker < 1 {
+		perWorker = 1
+	}
+	e := &asyncExporter{cli: cli, cfg: cfg, window: cfg.PatchCoalesceWindow, coalescing: map[string]*exportTask{}}
+	for i := 0; i < workers; i++ {
+		q := make(chan *exportTask, perWorker)
+		e.queues = append(e.queues, q)
+		e.workers.Add(1)
+		go e.work(q)
+	}
+	return e
+}
+
+func (e *asyncExporter) work(q chan *exportTask) {
+	defer e.workers.Done()
+	for t := range q {
+		e.awaitCoalescing(t)
+		_ = t.execute(e.cli, e.cfg)
+		e.pending.Done()
+	}
+}
+
+// coalesce merges the update t into the queued update of the same run, if any, and reports whether it did.
+// otherwise t becomes the queued update later ones are merged into.
+func (e *asyncExporter) coalesce(t *exportTask) bool {
+	if e.window <= 0 || t.op != ExportOpUpdate {
+		return false
+	}
+	e.coalesceMu.Lock()
+	defer e.coalesceMu.Unlock()
+	if queued, ok := e.coalescing[t.runID]; ok {
+		queued.patch = mergePatches(queued.patch, t.patch)
+		return true
+	}
+	e.coalescing[t.runID] = t
+	return false
+}
+
+// awaitCoalescing holds the update t until the coalescing window since it was queued elapsed, then stops merging into it.
+func (e *asyncExporter) awaitCoalescing(t *exportTask) {
+	if e.window <= 0 || t.op != ExportOpUpdate {
+		return
+	}
+	if wait := time.Until(t.start.Add(e.window)); wait > 0 {
+		time.Sleep(wait)
+	}
+	e.coalesceMu.Lock()
+	defer e.coalesceMu.Unlock()
+	if e.coalescing[t.runID] == t {
+		delete(e.coalescing, t.runID)
+	}
+}
+
+// uncoalesce removes t if it was never queued.
+func (e *asyncExporter) uncoalesce(t *exportTask) {
+	if e.window <= 0 || t.op != ExportOpUpdate {
+		return
+	}
+	e.coalesceMu.Lock()
+	defer e.coalesceMu.Unlock()
+	if e.coalescing[t.runID] == t {
+		delete(e.coalescing, t.runID)
+	}
+}
+
+// mergePatches returns the patch equivalent to sending first then second, neither of them is modified.
+func mergePatches(first, second *RunPatch) *RunPatch {
+	merged := *first
+	if second.EndTime != nil {
+		merged.EndTime = second.EndTime
+	}
+	if second.Inputs != nil {
+		merged.Inputs = second.Inputs
+	}
+	if second.Outputs != nil {
+		merged.Outputs = second.Outputs
+	}
+	if second.Error != nil {
+		merged.Error = second.Error
+	}
+	if second.Extra != nil {
+		merged.Extra = second.Extra
+	}
+	if second.Tags != nil {
+		merged.Tags = second.Tags
+	}
+	if len(second.Events) > 0 {
+		merged.Events = append(append([]RunEvent{}, first.Events...), second.Events...)
+	}
+	return &merged
+}
+
+// submit enqueues t without blocking, t is dropped if its queue is full or the exporter is closed.
+func (e *asyncExporter) submit(t *exportTask) {
+	e.cfg.metrics().ExportStarted(t.op)
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		e.drop(t, ErrExporterClosed)
+		return
+	}
+	if e.coalesce(t) {
+		e.cfg.metrics().ExportFinished(t.op, time.Since(t.start), nil)
+		return
+	}
+	e.pending.Add(1)
+	select {
+	case e.queues[queueIndex(t.runID, len(e.queues))] <- t:
+	default:
+		e.pending.Done()
+		e.uncoalesce(t)
+		e.drop(t, ErrQueueFull)
+	}
+}
+
+// admit reports why a request of op for runID would be dropped by submit right now, nil if it would be queued, so
+// the payload of dropped runs isn't serialized at all. The request can still be dropped if the queue fills meanwhile.
+func (e *asyncExporter) admit(op ExportOp, runID string) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return ErrExporterClosed
+	}
+	if op == ExportOpUpdate && e.window > 0 {
+		e.coalesceMu.Lock()
+		_, queued := e.coalescing[runID]
+		e.coalesceMu.Unlock()
+		if queued {
+			return nil
+		}
+	}
+	if q := e.queues[queueIndex(runID, len(e.queues))]; len(q) >= cap(q) {
+		return ErrQueueFull
+	}
+	return nil
+}
+
+func (e *asyncExporter) drop(t *exportTask, err error) {
+	e.cfg.metrics().ExportFinished(t.op, time.Since(t.start), err)
+	t.fail(e.cfg, err)
+}
+
+// flush waits until all submitted tasks are exported or ctx is done.
+func (e *asyncExporter) flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		e.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shutdown rejects new tasks, then waits for queued tasks to be exported or ctx to be done.
+func (e *asyncExporter) shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		for _, q := range e.queues {
+			close(q)
+		}
+	}
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func queueIndex(runID string, n int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(runID))
+	return int(h.Sum32() % uint32(n))
+}
+
+// DefaultExportTimeout bounds the export of a run, including retries, see Config.ExportTimeout.
+const DefaultExportTimeout = 30 * time.Second
+
+// exportContext returns the context a run is exported with: the callback context is usually canceled soon after the
+// graph returns, so only its values are kept, unless Config.PropagateDeadline is set.
+func (c *Config) exportContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	var timeout time.Duration
+	if c == nil || !c.PropagateDeadline {
+		ctx = detachContext(ctx)
+	}
+	if c != nil {
+		timeout = c.ExportTimeout
+	}
+	if timeout == 0 {
+		timeout = DefaultExportTimeout
+	}
+	if timeout < 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// detachedContext keeps the values of its parent but is never canceled.
+type detachedContext struct {
+	parent context.Context
+}
+
+func detachContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return detachedContext{parent: ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (d detachedContext) Value(key interface{}) interface{} { return d.parent.Value(key) }
+
+func (c *Config) onExportError(ctx context.Context, run *Run, err error) {
+	if c == nil || c.OnError == nil {
+		return
+	}
+	c.OnError(ctx, run, err)
+}
+
+// patchToRun describes a failed patch as a Run, so that Config.OnError receives the same type for both create and update.
+func patchToRun(runID string, patch *RunPatch) *Run {
+	run := &Run{ID: runID}
+	if patch == nil {
+		return run
+	}
+	run.EndTime = patch.EndTime
+	run.Inputs = patch.Inputs
+	run.Outputs = patch.Outputs
+	run.Error = patch.Error
+	run.Extra = patch.Extra
+	return run
+}
+
+// newClient creates the client of the handler and FlowTrace, runs go to Config.Exporter when it's set.
+func (c *Config) newClient() Langsmith {
+	cli := NewLangsmith(c.APIKey, c.APIURL, c.clientOptions()...)
+	if c.DryRun {
+		return &dryRunLangsmith{cli: cli.(*langsmithClient), next: c.Exporter}
+	}
+	if c.AutoCreateProject != nil {
+		cli = &projectCreatingLangsmith{Langsmith: cli, api: cli.(ProjectClient), defaults: c.AutoCreateProject, logger: c.logger()}
+	}
+	var exporter RunExporter = cli
+	if c.Exporter != nil {
+		exporter = c.Exporter
+	}
+	if c.Langfuse != nil {
+		lf, err := NewLangfuseExporter(c.Langfuse)
+		if err != nil {
+			c.logger().Error(context.Background(), "langfuse export disabled", "err", err)
+		} else {
+			exporter = NewMultiExporter(exporter, lf)
+		}
+	}
+	if exporter != RunExporter(cli) {
+		cli = &exporterLangsmith{Langsmith: cli, exporter: exporter}
+	}
+	return cli
+}
+
+// exporterLangsmith sends runs to a RunExporter, other requests still go to the langsmith API.
+type exporterLangsmith struct {
+	Langsmith
+	exporter RunExporter
+}
+
+func (e *exporterLangsmith) unwrap() Langsmith {
+	return e.Langsmith
+}
+
+func (e *exporterLangsmith) CreateRun(ctx context.Context, run *Run) error {
+	return e.exporter.CreateRun(ctx, run)
+}
+
+func (e *exporterLangsmith) UpdateRun(ctx context.Context, runID string, patch *RunPatch) error {
+	return e.exporter.UpdateRun(ctx, runID, patch)
+}
+
+// clientOptions translates the client related fields of Config into ClientOption.
+func (c *Config) clientOptions() []ClientOption {
+	return []ClientOption{
+		WithClientLogger(c.logger()),
+		WithIngestURL(c.IngestURL),
+		WithClientWorkspaceID(c.WorkspaceID),
+		WithAuthProvider(c.AuthProvider),
+		WithSchemaVersion(c.SchemaVersion),
+		WithMaxRetries(c.MaxRetries, 0),
+		WithClientMetrics(c.metrics()),
+		WithMarshaler(c.Marshaler),
+		WithRateLimit(c.RateLimit, c.RateBurst),
+		WithMaxInFlight(c.MaxInFlightRequests),
+		WithCircuitBreaker(c.CircuitBreakerThreshold, c.CircuitBreakerCooldown),
+	}
+}
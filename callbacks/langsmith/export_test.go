@@ -0,0 +1,244 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnExportError(t *testing.T) {
+	mCli := new(mockLangsmith)
+	exportErr := errors.New("export failed")
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(exportErr)
+	mCli.On("UpdateRun", mock.Anything, "run-123", mock.Anything).Return(exportErr)
+
+	var failed []*Run
+	h := &CallbackHandler{
+		cli: mCli,
+		cfg: &Config{
+			Logger: &recordLogger{},
+			RunIDGen: func(ctx context.Context) string {
+				return "run-123"
+			},
+			OnError: func(ctx context.Context, run *Run, err error) {
+				assert.Equal(t, exportErr, err)
+				failed = append(failed, run)
+			},
+		},
+	}
+
+	info := &callbacks.RunInfo{Name: "node"}
+	ctx := h.OnStart(context.Background(), info, "in")
+	h.OnError(ctx, info, errors.New("node failed"))
+
+	assert.Len(t, failed, 2)
+	assert.Equal(t, "node", failed[0].Name)
+	assert.Equal(t, "run-123", failed[1].ID)
+	assert.Equal(t, "node failed", *failed[1].Error)
+}
+
+func TestAsyncExporter(t *testing.T) {
+	mCli := new(mockLangsmith)
+	var mu sync.Mutex
+	var ops []string
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		// the callback context may already be canceled, the export must not be
+		assert.NoError(t, args.Get(0).(context.Context).Err())
+		mu.Lock()
+		ops = append(ops, "create")
+		mu.Unlock()
+	}).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, "run-1", mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		ops = append(ops, "update")
+		mu.Unlock()
+	}).Return(nil)
+
+	e := newAsyncExporter(mCli, &Config{}, 16, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	e.submit(newCreateTask(ctx, &Run{ID: "run-1"}))
+	e.submit(newUpdateTask(ctx, "run-1", &RunPatch{}))
+
+	require.NoError(t, e.flush(context.Background()))
+	assert.Equal(t, []string{"create", "update"}, ops)
+	require.NoError(t, e.shutdown(context.Background()))
+
+	var dropped []error
+	e.cfg.OnError = func(ctx context.Context, run *Run, err error) {
+		dropped = append(dropped, err)
+	}
+	e.cfg.Logger = &recordLogger{}
+	e.submit(newCreateTask(ctx, &Run{ID: "run-2"}))
+	assert.Equal(t, []error{ErrExporterClosed}, dropped)
+}
+
+// TestPatchCoalescing 测试窗口内同一 run 的多次更新被合并为一次 PATCH
+func TestPatchCoalescing(t *testing.T) {
+	mCli := new(mockLangsmith)
+	var patches []*RunPatch
+	mCli.On("UpdateRun", mock.Anything, "run-1", mock.Anything).Run(func(args mock.Arguments) {
+		patches = append(patches, args.Get(2).(*RunPatch))
+	}).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, "run-2", mock.Anything).Return(nil)
+
+	e := newAsyncExporter(mCli, &Config{PatchCoalesceWindow: 50 * time.Millisecond}, 16, 1)
+	ctx := context.Background()
+	end := time.Now()
+	partial := &RunPatch{Outputs: map[string]interface{}{"stream_outputs": "hel"}, Events: []RunEvent{{Name: "a"}}}
+	final := &RunPatch{EndTime: &end, Outputs: map[string]interface{}{"stream_outputs": "hello"}, Events: []RunEvent{{Name: "b"}}}
+	e.submit(newUpdateTask(ctx, "run-1", partial))
+	e.submit(newUpdateTask(ctx, "run-2", &RunPatch{}))
+	e.submit(newUpdateTask(ctx, "run-1", final))
+	require.NoError(t, e.flush(ctx))
+
+	require.Len(t, patches, 1)
+	assert.Equal(t, &end, patches[0].EndTime)
+	assert.Equal(t, "hello", patches[0].Outputs["stream_outputs"])
+	assert.Equal(t, []RunEvent{{Name: "a"}, {Name: "b"}}, patches[0].Events)
+	// the submitted patches are left untouched
+	assert.Nil(t, partial.EndTime)
+	assert.Len(t, final.Events, 1)
+
+	// updates after the window are sent separately
+	e.submit(newUpdateTask(ctx, "run-1", &RunPatch{Tags: []string{"late"}}))
+	require.NoError(t, e.shutdown(ctx))
+	assert.Len(t, patches, 2)
+	mCli.AssertNumberOfCalls(t, "UpdateRun", 3)
+}
+
+// TestLazySerialization 测试导出队列拒绝的 run 不会被序列化
+func TestLazySerialization(t *testing.T) {
+	mCli := new(mockLangsmith)
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	var serialized int32
+	var dropped []*Run
+	cfg := &Config{
+		RunIDGen: DefaultRunIDGen,
+		Logger:   &recordLogger{},
+		Serializer: SerializerFunc(func(info *callbacks.RunInfo, v interface{}) (string, error) {
+			atomic.AddInt32(&serialized, 1)
+			return "payload", nil
+		}),
+		OnError: func(ctx context.Context, run *Run, err error) {
+			assert.ErrorIs(t, err, ErrExporterClosed)
+			dropped = append(dropped, run)
+		},
+	}
+	h := &CallbackHandler{cli: mCli, cfg: cfg, async: newAsyncExporter(mCli, cfg, 16, 1)}
+	info := &callbacks.RunInfo{Name: "lambda", Component: compose.ComponentOfLambda}
+
+	ctx := h.OnStart(context.Background(), info, "in")
+	h.OnEnd(ctx, info, "out")
+	require.NoError(t, h.async.flush(context.Background()))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&serialized))
+
+	require.NoError(t, h.async.shutdown(context.Background()))
+	ctx = h.OnStart(context.Background(), info, "in")
+	h.OnEnd(ctx, info, "out")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&serialized))
+	require.Len(t, dropped, 2)
+	assert.Nil(t, dropped[0].Inputs)
+	assert.Equal(t, "lambda", dropped[0].Name)
+	assert.Equal(t, dropped[0].ID, dropped[1].ID)
+	assert.NotNil(t, dropped[1].EndTime)
+	mCli.AssertNumberOfCalls(t, "CreateRun", 1)
+}
+
+func TestAsyncExporterQueueFull(t *testing.T) {
+	block := make(chan struct{})
+	mCli := new(mockLangsmith)
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		<-block
+	}).Return(nil)
+
+	var dropped []string
+	cfg := &Config{
+		Logger: &recordLogger{},
+		OnError: func(ctx context.Context, run *Run, err error) {
+			assert.ErrorIs(t, err, ErrQueueFull)
+			dropped = append(dropped, run.ID)
+		},
+	}
+	e := newAsyncExporter(mCli, cfg, 1, 1)
+	e.submit(newCreateTask(context.Background(), &Run{ID: "run-1"})) // taken by the worker
+	time.Sleep(50 * time.Millisecond)
+	e.submit(newCreateTask(context.Background(), &Run{ID: "run-2"})) // queued
+	e.submit(newCreateTask(context.Background(), &Run{ID: "run-3"})) // dropped
+	close(block)
+
+	require.NoError(t, e.shutdown(context.Background()))
+	assert.Equal(t, []string{"run-3"}, dropped)
+	mCli.AssertNumberOfCalls(t, "CreateRun", 2)
+}
+
+func TestBlockingHandler(t *testing.T) {
+	h, err := NewLangsmithHandler(&Config{APIKey: "test-key", Blocking: true})
+	require.NoError(t, err)
+	assert.Nil(t, h.async)
+	assert.NoError(t, h.Flush(context.Background()))
+
+	h, err = NewLangsmithHandler(&Config{APIKey: "test-key"})
+	require.NoError(t, err)
+	assert.NotNil(t, h.async)
+	assert.NoError(t, h.Shutdown(context.Background()))
+}
+
+type exportCtxKey struct{}
+
+func TestExportContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), exportCtxKey{}, "v"))
+	cancel()
+
+	var (
+		ctxErr   error
+		value    interface{}
+		deadline time.Time
+		hasDL    bool
+	)
+	mCli := new(mockLangsmith)
+	mCli.On("UpdateRun", mock.Anything, "run-1", mock.Anything).Run(func(args mock.Arguments) {
+		exported := args.Get(0).(context.Context)
+		ctxErr, value = exported.Err(), exported.Value(exportCtxKey{})
+		deadline, hasDL = exported.Deadline()
+	}).Return(nil)
+
+	// the caller canceling its context doesn't lose the final update
+	require.NoError(t, updateRun(ctx, mCli, &Config{}, "run-1", &RunPatch{}))
+	assert.NoError(t, ctxErr)
+	assert.Equal(t, "v", value)
+	assert.True(t, hasDL)
+	assert.WithinDuration(t, time.Now().Add(DefaultExportTimeout), deadline, time.Second)
+
+	require.NoError(t, updateRun(ctx, mCli, &Config{ExportTimeout: -1}, "run-1", &RunPatch{}))
+	assert.False(t, hasDL)
+
+	require.NoError(t, updateRun(ctx, mCli, &Config{PropagateDeadline: true}, "run-1", &RunPatch{}))
+	assert.ErrorIs(t, ctxErr, context.Canceled)
+}
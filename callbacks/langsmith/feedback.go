@@ -0,0 +1,227 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const opFeedback APIOp = "feedback"
+
+// ErrNoRunInContext is returned by context based helpers when ctx is not inside a traced run.
+var ErrNoRunInContext = errors.New("no langsmith run in context")
+
+// Feedback is a score, label or comment attached to a run, e.g. a thumbs-up/down from the end user.
+type Feedback struct {
+	ID             string                 `json:"id,omitempty"`
+	RunID          string                 `json:"run_id,omitempty"`          // the run the feedback is attached to
+	TraceID        string                 `json:"trace_id,omitempty"`        // the trace of the run
+	Key            string                 `json:"key"`                       // name of the metric, e.g. "user_score", "correctness"
+	Score          *float64               `json:"score,omitempty"`           // numeric score, booleans are usually reported as 0 and 1
+	Value          interface{}            `json:"value,omitempty"`           // categorical value, e.g. "thumbs_up"
+	Comment        string                 `json:"comment,omitempty"`         // free text explaining the feedback
+	Correction     interface{}            `json:"correction,omitempty"`      // the expected output, if the run was wrong
+	SourceInfo     map[string]interface{} `json:"source_info,omitempty"`     // information about the source of the feedback
+	FeedbackSource *FeedbackSource        `json:"feedback_source,omitempty"` // who created the feedback, default: api
+	CreatedAt      *time.Time             `json:"created_at,omitempty"`
+	ModifiedAt     *time.Time             `json:"modified_at,omitempty"`
+}
+
+// FeedbackSource describes who created a Feedback.
+type FeedbackSource struct {
+	Type     string                 `json:"type"` // "api", "model" or "app"
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// FeedbackUpdate patches an existing Feedback, nil fields are left untouched.
+type FeedbackUpdate struct {
+	Score      *float64    `json:"score,omitempty"`
+	Value      interface{} `json:"value,omitempty"`
+	Comment    *string     `json:"comment,omitempty"`
+	Correction interface{} `json:"correction,omitempty"`
+}
+
+// Score is a helper returning a pointer to score, for Feedback.Score and FeedbackUpdate.Score.
+func Score(score float64) *float64 {
+	return &score
+}
+
+// FeedbackClient posts feedback on runs, it's implemented by the client returned by NewLangsmith.
+type FeedbackClient interface {
+	CreateFeedback(ctx context.Context, feedback *Feedback) (*Feedback, error)
+	UpdateFeedback(ctx context.Context, feedbackID string, update *FeedbackUpdate) error
+	DeleteFeedback(ctx context.Context, feedbackID string) error
+	CreateFeedbackToken(ctx context.Context, runID, feedbackKey string, opts *FeedbackTokenOptions) (*FeedbackToken, error)
+}
+
+// CreateFeedback attaches feedback to feedback.RunID
+func (c *langsmithClient) CreateFeedback(ctx context.Context, feedback *Feedback) (*Feedback, error) {
+	if feedback == nil || feedback.Key == "" {
+		return nil, fmt.Errorf("feedback key is required")
+	}
+	if feedback.FeedbackSource == nil {
+		cp := *feedback
+		cp.FeedbackSource = &FeedbackSource{Type: "api"}
+		feedback = &cp
+	}
+	created := &Feedback{}
+	if err := c.doJSON(ctx, opFeedback, "POST", "/feedback", feedback, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateFeedback patches the score, value, comment or correction of a feedback
+func (c *langsmithClient) UpdateFeedback(ctx context.Context, feedbackID string, update *FeedbackUpdate) error {
+	return c.doJSON(ctx, opFeedback, "PATCH", "/feedback/"+url.PathEscape(feedbackID), update, nil)
+}
+
+// DeleteFeedback deletes a feedback
+func (c *langsmithClient) DeleteFeedback(ctx context.Context, feedbackID string) error {
+	return c.doJSON(ctx, opFeedback, "DELETE", "/feedback/"+url.PathEscape(feedbackID), nil, nil)
+}
+
+// FeedbackToken is a pre-signed url accepting feedback for a run without an API key, e.g. to let a frontend submit
+// the rating of the end user straight to langsmith: a GET or POST to URL with score, value or comment parameters.
+type FeedbackToken struct {
+	ID        string     `json:"id"`
+	URL       string     `json:"url"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// FeedbackTokenOptions configures the expiry and the accepted values of a FeedbackToken.
+type FeedbackTokenOptions struct {
+	ExpiresIn time.Duration   // validity of the token, default: 3 hours, as set by langsmith
+	ExpiresAt time.Time       // expiry of the token, takes precedence over ExpiresIn
+	Config    *FeedbackConfig // values accepted by the token, default: any score
+}
+
+// FeedbackConfig restricts the feedback accepted for a key.
+type FeedbackConfig struct {
+	Type       string             `json:"type"` // "continuous", "categorical" or "freeform"
+	Min        *float64           `json:"min,omitempty"`
+	Max        *float64           `json:"max,omitempty"`
+	Categories []FeedbackCategory `json:"categories,omitempty"`
+}
+
+// FeedbackCategory is a value accepted by a categorical FeedbackConfig.
+type FeedbackCategory struct {
+	Value float64 `json:"value"`
+	Label string  `json:"label,omitempty"`
+}
+
+type feedbackTokenRequest struct {
+	RunID          string          `json:"run_id"`
+	FeedbackKey    string          `json:"feedback_key"`
+	ExpiresIn      *timeDelta      `json:"expires_in,omitempty"`
+	ExpiresAt      *time.Time      `json:"expires_at,omitempty"`
+	FeedbackConfig *FeedbackConfig `json:"feedback_config,omitempty"`
+}
+
+// timeDelta is the duration format of the langsmith API.
+type timeDelta struct {
+	Days    int `json:"days"`
+	Hours   int `json:"hours"`
+	Minutes int `json:"minutes"`
+}
+
+func newTimeDelta(d time.Duration) *timeDelta {
+	minutes := int((d + time.Minute - 1) / time.Minute)
+	return &timeDelta{Days: minutes / (24 * 60), Hours: minutes / 60 % 24, Minutes: minutes % 60}
+}
+
+// CreateFeedbackToken creates a pre-signed url accepting feedback with feedbackKey for a run
+func (c *langsmithClient) CreateFeedbackToken(ctx context.Context, runID, feedbackKey string, opts *FeedbackTokenOptions) (*FeedbackToken, error) {
+	if runID == "" || feedbackKey == "" {
+		return nil, fmt.Errorf("run id and feedback key are required")
+	}
+	req := &feedbackTokenRequest{RunID: runID, FeedbackKey: feedbackKey}
+	if opts != nil {
+		switch {
+		case !opts.ExpiresAt.IsZero():
+			expiresAt := opts.ExpiresAt.UTC()
+			req.ExpiresAt = &expiresAt
+		case opts.ExpiresIn > 0:
+			req.ExpiresIn = newTimeDelta(opts.ExpiresIn)
+		}
+		req.FeedbackConfig = opts.Config
+	}
+	token := &FeedbackToken{}
+	if err := c.doJSON(ctx, opFeedback, "POST", "/feedback/tokens", req, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// CurrentRunID returns the id of the run ctx is traced in, it's empty outside of a traced run.
+func CurrentRunID(ctx context.Context) string {
+	_, state := GetState(ctx)
+	if state == nil {
+		return ""
+	}
+	return state.ParentRunID
+}
+
+// CreateFeedbackForContext attaches feedback to the run ctx is traced in, e.g. inside a graph node or a FlowTrace span.
+func CreateFeedbackForContext(ctx context.Context, cli FeedbackClient, feedback *Feedback) (*Feedback, error) {
+	_, state := GetState(ctx)
+	if state == nil || state.ParentRunID == "" {
+		return nil, ErrNoRunInContext
+	}
+	if feedback == nil {
+		return nil, fmt.Errorf("feedback is required")
+	}
+	cp := *feedback
+	cp.RunID = state.ParentRunID
+	cp.TraceID = state.TraceID
+	return cli.CreateFeedback(ctx, &cp)
+}
+
+// CreateFeedback attaches feedback to the run ctx is traced in, see CreateFeedbackForContext.
+func (c *CallbackHandler) CreateFeedback(ctx context.Context, feedback *Feedback) (*Feedback, error) {
+	cli, err := apiClient[FeedbackClient](c.cli, "feedback")
+	if err != nil {
+		return nil, err
+	}
+	return CreateFeedbackForContext(ctx, cli, feedback)
+}
+
+// CreateFeedbackTokenForContext creates a pre-signed feedback url for the run ctx is traced in, to hand it to a
+// frontend along with the response, e.g.
+//
+//	token, err := langsmith.CreateFeedbackTokenForContext(ctx, cli, "user_score", nil)
+//	resp.FeedbackURL = token.URL
+func CreateFeedbackTokenForContext(ctx context.Context, cli FeedbackClient, feedbackKey string, opts *FeedbackTokenOptions) (*FeedbackToken, error) {
+	runID := CurrentRunID(ctx)
+	if runID == "" {
+		return nil, ErrNoRunInContext
+	}
+	return cli.CreateFeedbackToken(ctx, runID, feedbackKey, opts)
+}
+
+// CreateFeedbackToken creates a pre-signed feedback url for the run ctx is traced in, see CreateFeedbackTokenForContext.
+func (c *CallbackHandler) CreateFeedbackToken(ctx context.Context, feedbackKey string, opts *FeedbackTokenOptions) (*FeedbackToken, error) {
+	cli, err := apiClient[FeedbackClient](c.cli, "feedback")
+	if err != nil {
+		return nil, err
+	}
+	return CreateFeedbackTokenForContext(ctx, cli, feedbackKey, opts)
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedbackClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/feedback":
+			var fb map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&fb))
+			assert.Equal(t, "run-1", fb["run_id"])
+			assert.Equal(t, "user_score", fb["key"])
+			assert.Equal(t, 1.0, fb["score"])
+			assert.Equal(t, map[string]interface{}{"type": "api"}, fb["feedback_source"])
+			_, _ = w.Write([]byte(`{"id":"fb-1","run_id":"run-1","key":"user_score","score":1}`))
+		case r.Method == "PATCH" && r.URL.Path == "/feedback/fb-1":
+			var fb map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&fb))
+			assert.Equal(t, map[string]interface{}{"comment": "fixed"}, fb)
+		case r.Method == "DELETE" && r.URL.Path == "/feedback/fb-1":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	cli := NewLangsmith("test-key", srv.URL).(*langsmithClient)
+	ctx := context.Background()
+
+	in := &Feedback{RunID: "run-1", Key: "user_score", Score: Score(1)}
+	fb, err := cli.CreateFeedback(ctx, in)
+	require.NoError(t, err)
+	assert.Equal(t, "fb-1", fb.ID)
+	assert.Nil(t, in.FeedbackSource)
+
+	comment := "fixed"
+	assert.NoError(t, cli.UpdateFeedback(ctx, "fb-1", &FeedbackUpdate{Comment: &comment}))
+	assert.NoError(t, cli.DeleteFeedback(ctx, "fb-1"))
+	assert.Error(t, cli.DeleteFeedback(ctx, "unknown"))
+
+	_, err = cli.CreateFeedback(ctx, &Feedback{RunID: "run-1"})
+	assert.Error(t, err)
+}
+
+func TestCreateFeedbackForContext(t *testing.T) {
+	mCli := new(mockLangsmith)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{}}
+
+	_, err := h.CreateFeedback(context.Background(), &Feedback{Key: "thumbs"})
+	assert.ErrorIs(t, err, ErrNoRunInContext)
+
+	ctx := context.WithValue(context.Background(), langsmithStateKey{}, &LangsmithState{
+		TraceID:     "trace-1",
+		ParentRunID: "run-1",
+	})
+	assert.Equal(t, "run-1", CurrentRunID(ctx))
+
+	mCli.On("CreateFeedback", mock.Anything, mock.MatchedBy(func(fb *Feedback) bool {
+		return fb.RunID == "run-1" && fb.TraceID == "trace-1" && fb.Key == "thumbs"
+	})).Return(&Feedback{ID: "fb-1"}, nil)
+	in := &Feedback{Key: "thumbs", Value: "up"}
+	fb, err := h.CreateFeedback(ctx, in)
+	require.NoError(t, err)
+	assert.Equal(t, "fb-1", fb.ID)
+	assert.Equal(t, &Feedback{Key: "thumbs", Value: "up"}, in, "the feedback of the caller is copied")
+
+	_, err = h.CreateFeedback(ctx, nil)
+	assert.EqualError(t, err, "feedback is required")
+}
+
+func TestCreateFeedbackToken(t *testing.T) {
+	var bodies []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "POST", r.Method)
+		require.Equal(t, "/feedback/tokens", r.URL.Path)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+		_, _ = w.Write([]byte(`{"id":"tok-1","url":"https://api.smith.langchain.com/feedback/tokens/tok-1","expires_at":"2026-01-02T03:04:05Z"}`))
+	}))
+	defer srv.Close()
+
+	cli := NewLangsmith("test-key", srv.URL).(*langsmithClient)
+	ctx := context.Background()
+
+	token, err := cli.CreateFeedbackToken(ctx, "run-1", "user_score", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "tok-1", token.ID)
+	assert.Equal(t, "https://api.smith.langchain.com/feedback/tokens/tok-1", token.URL)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), token.ExpiresAt.UTC())
+	assert.Equal(t, map[string]interface{}{"run_id": "run-1", "feedback_key": "user_score"}, bodies[0])
+
+	_, err = cli.CreateFeedbackToken(ctx, "run-1", "thumbs", &FeedbackTokenOptions{
+		ExpiresIn: 26*time.Hour + 30*time.Second,
+		Config: &FeedbackConfig{Type: "categorical", Categories: []FeedbackCategory{
+			{Value: 0, Label: "down"}, {Value: 1, Label: "up"},
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"days": 1.0, "hours": 2.0, "minutes": 1.0}, bodies[1]["expires_in"])
+	assert.Equal(t, map[string]interface{}{"type": "categorical", "categories": []interface{}{
+		map[string]interface{}{"value": 0.0, "label": "down"}, map[string]interface{}{"value": 1.0, "label": "up"},
+	}}, bodies[1]["feedback_config"])
+
+	expiresAt := time.Date(2026, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	_, err = cli.CreateFeedbackToken(ctx, "run-1", "thumbs", &FeedbackTokenOptions{ExpiresAt: expiresAt, ExpiresIn: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, "2026-05-01T10:00:00Z", bodies[2]["expires_at"])
+	assert.NotContains(t, bodies[2], "expires_in")
+
+	_, err = cli.CreateFeedbackToken(ctx, "", "thumbs", nil)
+	assert.Error(t, err)
+	assert.Len(t, bodies, 3)
+}
+
+func TestCreateFeedbackTokenForContext(t *testing.T) {
+	mCli := new(mockLangsmith)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{}}
+
+	_, err := h.CreateFeedbackToken(context.Background(), "thumbs", nil)
+	assert.ErrorIs(t, err, ErrNoRunInContext)
+
+	ctx := context.WithValue(context.Background(), langsmithStateKey{}, &LangsmithState{TraceID: "trace-1", ParentRunID: "run-1"})
+	opts := &FeedbackTokenOptions{ExpiresIn: time.Hour}
+	mCli.On("CreateFeedbackToken", mock.Anything, "run-1", "thumbs", opts).Return(&FeedbackToken{ID: "tok-1", URL: "https://x/tok-1"}, nil)
+	token, err := h.CreateFeedbackToken(ctx, "thumbs", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "https://x/tok-1", token.URL)
+}
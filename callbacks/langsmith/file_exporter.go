@@ -0,0 +1,166 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// FileRecord is one line written by FileExporter: a created run or a patch of a run.
+type FileRecord struct {
+	Op    ExportOp  `json:"op"` // ExportOpCreate or ExportOpUpdate
+	Time  time.Time `json:"time"`
+	Run   *Run      `json:"run,omitempty"`
+	RunID string    `json:"run_id,omitempty"`
+	Patch *RunPatch `json:"patch,omitempty"`
+	// Attachments of the created run, the media its inputs reference with AttachmentURLPrefix.
+	Attachments map[string]*Attachment `json:"attachments,omitempty"`
+}
+
+// FileExporterConfig configures NewFileExporter
+type FileExporterConfig struct {
+	// Path of the JSON Lines file, parent directories are created. required
+	Path string
+	// MaxBytes rotates the file once it grows over this size: it's renamed to Path.1, Path.1 to Path.2 and so on.
+	// 0 disables rotation.
+	MaxBytes int64
+	// MaxBackups is how many rotated files are kept, older ones are deleted. 0 keeps all of them.
+	MaxBackups int
+}
+
+// FileExporter is a RunExporter writing runs as JSON Lines to a local file, e.g. for air-gapped environments or to
+// analyze traces offline. Set it as Config.Exporter.
+type FileExporter struct {
+	cfg  FileExporterConfig
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// NewFileExporter opens or creates the file at cfg.Path, records are appended to existing content.
+func NewFileExporter(cfg *FileExporterConfig) (*FileExporter, error) {
+	if cfg == nil || cfg.Path == "" {
+		return nil, fmt.Errorf("file exporter path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create file exporter dir: %w", err)
+	}
+	e := &FileExporter{cfg: *cfg}
+	if err := e.open(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// CreateRun implements RunExporter
+func (e *FileExporter) CreateRun(ctx context.Context, run *Run) error {
+	return e.write(&FileRecord{Op: ExportOpCreate, Time: time.Now().UTC(), Run: run, Attachments: run.Attachments})
+}
+
+// UpdateRun implements RunExporter
+func (e *FileExporter) UpdateRun(ctx context.Context, runID string, patch *RunPatch) error {
+	return e.write(&FileRecord{Op: ExportOpUpdate, Time: time.Now().UTC(), RunID: runID, Patch: patch})
+}
+
+// Close closes the file, later writes fail.
+func (e *FileExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.f == nil {
+		return nil
+	}
+	err := e.f.Close()
+	e.f = nil
+	return err
+}
+
+func (e *FileExporter) write(rec *FileRecord) error {
+	line, err := sonic.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal file record: %w", err)
+	}
+	line = append(line, '\n')
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.f == nil {
+		return fmt.Errorf("file exporter is closed")
+	}
+	if e.cfg.MaxBytes > 0 && e.size > 0 && e.size+int64(len(line)) > e.cfg.MaxBytes {
+		if err = e.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := e.f.Write(line)
+	e.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write file record: %w", err)
+	}
+	return nil
+}
+
+func (e *FileExporter) open() error {
+	f, err := os.OpenFile(e.cfg.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open file exporter file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to stat file exporter file: %w", err)
+	}
+	e.f, e.size = f, info.Size()
+	return nil
+}
+
+// rotate shifts the backups by one and starts a new file.
+func (e *FileExporter) rotate() error {
+	if err := e.f.Close(); err != nil {
+		return fmt.Errorf("failed to close file exporter file: %w", err)
+	}
+	e.f = nil
+	backup := func(i int) string { return fmt.Sprintf("%s.%d", e.cfg.Path, i) }
+	last := e.cfg.MaxBackups
+	if last <= 0 {
+		// keep all backups: find the first free slot
+		last = 1
+		for {
+			if _, err := os.Stat(backup(last)); os.IsNotExist(err) {
+				break
+			}
+			last++
+		}
+	} else {
+		_ = os.Remove(backup(last))
+	}
+	for i := last - 1; i >= 1; i-- {
+		if err := os.Rename(backup(i), backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate file exporter file: %w", err)
+		}
+	}
+	if err := os.Rename(e.cfg.Path, backup(1)); err != nil {
+		return fmt.Errorf("failed to rotate file exporter file: %w", err)
+	}
+	return e.open()
+}
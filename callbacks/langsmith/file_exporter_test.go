@@ -0,0 +1,103 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readFileRecords(t *testing.T, path string) []*FileRecord {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var records []*FileRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		rec := &FileRecord{}
+		require.NoError(t, sonic.Unmarshal(scanner.Bytes(), rec))
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestFileExporter(t *testing.T) {
+	_, err := NewFileExporter(&FileExporterConfig{})
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "traces", "runs.jsonl")
+	exp, err := NewFileExporter(&FileExporterConfig{Path: path})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	end := time.Now().UTC()
+	image := &Attachment{ContentType: "image/png", Data: []byte("png")}
+	require.NoError(t, exp.CreateRun(ctx, &Run{ID: "run-1", Name: "chain", RunType: RunTypeChain, Attachments: map[string]*Attachment{"image_0": image}}))
+	require.NoError(t, exp.UpdateRun(ctx, "run-1", &RunPatch{EndTime: &end}))
+	require.NoError(t, exp.Close())
+	assert.Error(t, exp.CreateRun(ctx, &Run{ID: "run-2"}))
+
+	records := readFileRecords(t, path)
+	require.Len(t, records, 2)
+	assert.Equal(t, ExportOpCreate, records[0].Op)
+	assert.Equal(t, "chain", records[0].Run.Name)
+	assert.Equal(t, map[string]*Attachment{"image_0": image}, records[0].Attachments)
+	assert.Equal(t, ExportOpUpdate, records[1].Op)
+	assert.Equal(t, "run-1", records[1].RunID)
+	assert.NotNil(t, records[1].Patch.EndTime)
+}
+
+func TestFileExporterRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs.jsonl")
+	exp, err := NewFileExporter(&FileExporterConfig{Path: path, MaxBytes: 1, MaxBackups: 2})
+	require.NoError(t, err)
+	defer exp.Close()
+
+	ctx := context.Background()
+	for _, id := range []string{"run-1", "run-2", "run-3", "run-4"} {
+		require.NoError(t, exp.CreateRun(ctx, &Run{ID: id}))
+	}
+
+	assert.Equal(t, "run-4", readFileRecords(t, path)[0].Run.ID)
+	assert.Equal(t, "run-3", readFileRecords(t, path+".1")[0].Run.ID)
+	assert.Equal(t, "run-2", readFileRecords(t, path+".2")[0].Run.ID)
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFileExporterRotationKeepAll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs.jsonl")
+	exp, err := NewFileExporter(&FileExporterConfig{Path: path, MaxBytes: 1})
+	require.NoError(t, err)
+	defer exp.Close()
+
+	ctx := context.Background()
+	for _, id := range []string{"run-1", "run-2", "run-3"} {
+		require.NoError(t, exp.CreateRun(ctx, &Run{ID: id}))
+	}
+	assert.Equal(t, "run-3", readFileRecords(t, path)[0].Run.ID)
+	assert.Equal(t, "run-2", readFileRecords(t, path+".1")[0].Run.ID)
+	assert.Equal(t, "run-1", readFileRecords(t, path+".2")[0].Run.ID)
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+)
+
+// RunFilter selects the components traced by CallbackHandler, see Config.Filter.
+// No run is created for a filtered out component, its children are attached to the nearest traced ancestor.
+//
+// A run is traced if it matches no deny list and, for every non-empty allow list, one of its entries.
+type RunFilter struct {
+	AllowComponents []components.Component // e.g. components.ComponentOfChatModel, compose.ComponentOfLambda
+	DenyComponents  []components.Component
+	AllowNames      []string // matched against RunInfo.Name, i.e. node names
+	DenyNames       []string
+	AllowRunTypes   []RunType
+	DenyRunTypes    []RunType
+}
+
+// traced reports whether the run of info passes the filter, a nil filter traces everything.
+func (f *RunFilter) traced(info *callbacks.RunInfo) bool {
+	if f == nil {
+		return true
+	}
+	runType := runInfoToRunType(info)
+	if containsComponent(f.DenyComponents, info.Component) || containsString(f.DenyNames, info.Name) ||
+		containsRunType(f.DenyRunTypes, runType) {
+		return false
+	}
+	if len(f.AllowComponents) > 0 && !containsComponent(f.AllowComponents, info.Component) {
+		return false
+	}
+	if len(f.AllowNames) > 0 && !containsString(f.AllowNames, info.Name) {
+		return false
+	}
+	if len(f.AllowRunTypes) > 0 && !containsRunType(f.AllowRunTypes, runType) {
+		return false
+	}
+	return true
+}
+
+// skipRun returns a context marking the run of info as skipped: its children keep the parent state,
+// and its end or error is ignored.
+func skipRun(ctx context.Context, state *LangsmithState) context.Context {
+	skipped := *state
+	skipped.skipped = true
+	skipped.loop = nil
+	skipped.agents = nil
+	return context.WithValue(ctx, langsmithStateKey{}, &skipped)
+}
+
+func containsComponent(list []components.Component, c components.Component) bool {
+	for _, v := range list {
+		if v == c {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsRunType(list []RunType, t RunType) bool {
+	for _, v := range list {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFilter(t *testing.T) {
+	model := &callbacks.RunInfo{Name: "model", Component: components.ComponentOfChatModel}
+	lambda := &callbacks.RunInfo{Name: "health_check", Component: compose.ComponentOfLambda}
+	tool := &callbacks.RunInfo{Name: "search", Component: components.ComponentOfTool}
+
+	var f *RunFilter
+	assert.True(t, f.traced(model))
+
+	f = &RunFilter{DenyComponents: []components.Component{compose.ComponentOfLambda}}
+	assert.True(t, f.traced(model))
+	assert.False(t, f.traced(lambda))
+
+	f = &RunFilter{DenyNames: []string{"search"}}
+	assert.False(t, f.traced(tool))
+	assert.True(t, f.traced(lambda))
+
+	f = &RunFilter{AllowRunTypes: []RunType{RunTypeLLM, RunTypeTool}, DenyNames: []string{"search"}}
+	assert.True(t, f.traced(model))
+	assert.False(t, f.traced(lambda))
+	assert.False(t, f.traced(tool)) // deny wins
+
+	f = &RunFilter{AllowComponents: []components.Component{components.ComponentOfTool}, AllowNames: []string{"other"}}
+	assert.False(t, f.traced(tool))
+}
+
+// TestFilterReparent 测试被过滤节点的子节点挂到最近的祖先节点上
+func TestFilterReparent(t *testing.T) {
+	mCli := &mockLangsmith{}
+	h := &CallbackHandler{cli: mCli, cfg: &Config{
+		RunIDGen: func(ctx context.Context) string { return uuid.NewString() },
+		Filter:   &RunFilter{DenyComponents: []components.Component{compose.ComponentOfLambda}},
+	}}
+	var runs []*Run
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		runs = append(runs, args.Get(1).(*Run))
+	}).Return(nil)
+	var updated []string
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		updated = append(updated, args.String(1))
+	}).Return(nil)
+
+	graphCtx := h.OnStart(context.Background(), &callbacks.RunInfo{Name: "graph", Component: compose.ComponentOfGraph}, "in")
+	lambdaCtx := h.OnStart(graphCtx, &callbacks.RunInfo{Name: "lambda", Component: compose.ComponentOfLambda}, "in")
+	toolCtx := h.OnStart(lambdaCtx, &callbacks.RunInfo{Name: "tool", Component: components.ComponentOfTool}, "in")
+	h.OnEnd(toolCtx, &callbacks.RunInfo{Name: "tool", Component: components.ComponentOfTool}, "out")
+	h.OnError(lambdaCtx, &callbacks.RunInfo{Name: "lambda", Component: compose.ComponentOfLambda}, errors.New("boom"))
+
+	sr, sw := schema.Pipe[callbacks.CallbackOutput](1)
+	sw.Close()
+	h.OnEndWithStreamOutput(lambdaCtx, &callbacks.RunInfo{Name: "lambda", Component: compose.ComponentOfLambda}, sr)
+	in, inW := schema.Pipe[callbacks.CallbackInput](1)
+	inW.Close()
+	h.OnStartWithStreamInput(graphCtx, &callbacks.RunInfo{Name: "lambda", Component: compose.ComponentOfLambda}, in)
+	h.OnEnd(graphCtx, &callbacks.RunInfo{Name: "graph", Component: compose.ComponentOfGraph}, "out")
+
+	require.Len(t, runs, 2)
+	assert.Equal(t, "graph", runs[0].Name)
+	assert.Equal(t, "tool", runs[1].Name)
+	require.NotNil(t, runs[1].ParentRunID)
+	assert.Equal(t, runs[0].ID, *runs[1].ParentRunID)
+	assert.Equal(t, runs[0].TraceID, runs[1].TraceID)
+	assert.Equal(t, []string{runs[1].ID, runs[0].ID}, updated)
+}
@@ -18,13 +18,10 @@ package langsmith
 
 import (
 	"context"
-	"fmt"
-	"log"
 	"sync"
-	"time"
 
 	"github.com/bytedance/sonic"
-	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
 )
 
 type FlowTrace struct { // associating multiple sessions with the same trace
@@ -32,17 +29,60 @@ type FlowTrace struct { // associating multiple sessions with the same trace
 	cfg *Config
 }
 
+// NewFlowTrace creates a FlowTrace with its own langsmith API client, spans are exported synchronously. Use
+// NewClient to share the client, limits and spool with a CallbackHandler.
 func NewFlowTrace(cfg *Config) *FlowTrace {
-	cli := NewLangsmith(cfg.APIKey, cfg.APIURL)
+	cli := cfg.newClient()
 	if cfg.RunIDGen == nil {
-		cfg.RunIDGen = func(ctx context.Context) string {
-			return uuid.NewString()
-		}
+		cfg.RunIDGen = DefaultRunIDGen
 	}
 	return &FlowTrace{cli: cli, cfg: cfg}
 }
 
-func (ft *FlowTrace) StartSpan(ctx context.Context, name string, state *LangsmithState) (context.Context, string, error) {
+// SpanOption customizes the run created by StartSpan
+type SpanOption func(*spanOptions)
+
+type spanOptions struct {
+	runType  RunType
+	inputs   map[string]interface{}
+	tags     []string
+	metadata map[string]interface{}
+}
+
+// WithSpanRunType sets the run type of the span, e.g. RunTypeTool or RunTypeLLM. default: RunTypeChain
+func WithSpanRunType(runType RunType) SpanOption {
+	return func(o *spanOptions) {
+		o.runType = runType
+	}
+}
+
+// WithSpanInputs sets the inputs of the span, Config.HideInputs and Config.MaxInputBytes apply.
+func WithSpanInputs(inputs map[string]interface{}) SpanOption {
+	return func(o *spanOptions) {
+		o.inputs = inputs
+	}
+}
+
+// WithSpanTags adds tags to the span, in addition to the tags of the trace.
+func WithSpanTags(tags ...string) SpanOption {
+	return func(o *spanOptions) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// WithSpanMetadata adds metadata to the span, in addition to the metadata of the trace.
+func WithSpanMetadata(metadata map[string]interface{}) SpanOption {
+	return func(o *spanOptions) {
+		if o.metadata == nil {
+			o.metadata = map[string]interface{}{}
+		}
+		for k, v := range metadata {
+			o.metadata[k] = v
+		}
+	}
+}
+
+func (ft *FlowTrace) StartSpan(ctx context.Context, name string, state *LangsmithState, spanOpts ...SpanOption) (context.Context, string, error) {
 	opts, _ := ctx.Value(langsmithTraceOptionKey{}).(*traceOptions)
 	if opts == nil {
 		opts = &traceOptions{}
@@ -50,34 +90,42 @@ func (ft *FlowTrace) StartSpan(ctx context.Context, name string, state *Langsmit
 	if state == nil {
 		state = &LangsmithState{}
 	}
+	so := &spanOptions{runType: RunTypeChain}
+	for _, opt := range spanOpts {
+		opt(so)
+	}
 	var newMetadata = SafeDeepCopySyncMapMetadata(opts.Metadata)
+	for k, v := range so.metadata {
+		setRunMetadata(newMetadata, k, v)
+	}
+	tags := opts.Tags
+	for _, tag := range so.tags {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags[:len(tags):len(tags)], tag)
+		}
+	}
 	runID := ft.cfg.RunIDGen(ctx)
+	startTime, started := runStartTime(state.ParentDottedOrder)
 	run := &Run{
 		ID:          runID,
 		TraceID:     state.TraceID,
 		Name:        name,
-		RunType:     RunTypeChain,
-		StartTime:   time.Now().UTC(),
-		SessionName: opts.SessionName,
+		RunType:     so.runType,
+		StartTime:   startTime,
+		SessionName: ft.cfg.sessionName(opts),
 		Extra:       newMetadata,
-		Tags:        opts.Tags,
+		Tags:        tags,
+	}
+	if so.inputs != nil {
+		run.Inputs = limitRunPayload(so.inputs, "input", ft.cfg.HideInputs, ft.cfg.MaxInputBytes)
 	}
 	if state.TraceID == "" {
 		run.TraceID = runID
 	}
-	if opts.ReferenceExampleID != "" {
-		run.ReferenceExampleID = &opts.ReferenceExampleID
-	}
-	if state.ParentRunID != "" {
-		run.ParentRunID = &state.ParentRunID
-	}
-	nowTime := run.StartTime.Format("20060102T150405000000")
-	if state.ParentDottedOrder != "" {
-		run.DottedOrder = fmt.Sprintf("%s.%sZ%s", state.ParentDottedOrder, nowTime, runID)
-	} else {
-		run.DottedOrder = fmt.Sprintf("%sZ%s", nowTime, runID)
-	}
-	err := ft.cli.CreateRun(ctx, run)
+	run.ReferenceExampleID = ft.cfg.referenceExampleID(opts, state)
+	run.ParentRunID = state.parentRunID()
+	run.DottedOrder = dottedOrder(state.ParentDottedOrder, run.StartTime, runID)
+	err := createRun(ctx, ft.cli, ft.cfg, run)
 	if err != nil {
 		return nil, "", err
 	}
@@ -85,21 +133,76 @@ func (ft *FlowTrace) StartSpan(ctx context.Context, name string, state *Langsmit
 		TraceID:           run.TraceID,
 		ParentRunID:       runID,
 		ParentDottedOrder: run.DottedOrder,
+		startTime:         run.StartTime,
+		started:           started,
+		events:            &runEvents{},
+		cli:               ft.cli,
+		session:           run.SessionName,
+		annotations:       newRunAnnotations(run.Extra, run.Tags),
+		root:              state.root,
 	}
 
 	return context.WithValue(ctx, langsmithStateKey{}, newState), runID, nil
 }
 
 func (ft *FlowTrace) FinishSpan(ctx context.Context, runID string) {
-	endTime := time.Now().UTC()
+	_ = ft.finishSpan(ctx, runID, nil, nil)
+}
+
+// FinishSpanWithError ends a span as failed with err.
+func (ft *FlowTrace) FinishSpanWithError(ctx context.Context, runID string, err error) {
+	_ = ft.finishSpan(ctx, runID, nil, err)
+}
+
+// FinishSpanWithOutputs ends a span with its outputs, and as failed with err if it isn't nil, in a single update.
+// Config.HideOutputs and Config.MaxOutputBytes apply as for SetSpanOutputs. The error of the update is returned.
+func (ft *FlowTrace) FinishSpanWithOutputs(ctx context.Context, runID string, outputs map[string]interface{}, err error) error {
+	return ft.finishSpan(ctx, runID, outputs, err)
+}
+
+// finishSpan ends a span with its outputs and error in a single update, both are optional.
+func (ft *FlowTrace) finishSpan(ctx context.Context, runID string, outputs map[string]interface{}, err error) error {
+	_, state := GetState(ctx)
+	if state != nil && state.ParentRunID != runID {
+		// ctx isn't the one StartSpan returned for runID
+		state = nil
+	}
+	endTime := state.now()
 	patch := &RunPatch{
 		EndTime: &endTime,
 	}
-
-	err := ft.cli.UpdateRun(ctx, runID, patch)
+	if outputs != nil {
+		patch.Outputs = limitRunPayload(outputs, "output", ft.cfg.HideOutputs, ft.cfg.MaxOutputBytes)
+	}
 	if err != nil {
-		log.Printf("[langsmith] failed to FinishSpan: %v", err)
+		details, errStr := ft.cfg.errorDetails(err)
+		patch.Error = &errStr
+		if state != nil {
+			// the extra of the span is only known from its state
+			patch.Extra = state.annotations.createdExtra()
+			patch.Extra[ExtraErrorDetails] = details
+		}
+	}
+	if state != nil {
+		patch.Events = state.events.drain()
+		state.annotations.apply(patch)
 	}
+
+	return updateRun(ctx, ft.cli, ft.cfg, runID, patch)
+}
+
+// SetSpanInputs reports the inputs of a span, Config.HideInputs and Config.MaxInputBytes apply as for handler runs.
+func (ft *FlowTrace) SetSpanInputs(ctx context.Context, runID string, inputs map[string]interface{}) error {
+	return updateRun(ctx, ft.cli, ft.cfg, runID, &RunPatch{
+		Inputs: limitRunPayload(inputs, "input", ft.cfg.HideInputs, ft.cfg.MaxInputBytes),
+	})
+}
+
+// SetSpanOutputs reports the outputs of a span, Config.HideOutputs and Config.MaxOutputBytes apply as for handler runs.
+func (ft *FlowTrace) SetSpanOutputs(ctx context.Context, runID string, outputs map[string]interface{}) error {
+	return updateRun(ctx, ft.cli, ft.cfg, runID, &RunPatch{
+		Outputs: limitRunPayload(outputs, "output", ft.cfg.HideOutputs, ft.cfg.MaxOutputBytes),
+	})
 }
 
 // SpanToString parse ctx's LangsmithState to string
@@ -115,8 +218,10 @@ func (ft *FlowTrace) SpanToString(ctx context.Context) (string, error) {
 			return true
 		})
 	}
-	state.MarshalMetadata = tmpMetadata
-	val, err := sonic.Marshal(state)
+	// marshal a copy, the state may be read concurrently by sibling runs
+	snapshot := *state
+	snapshot.MarshalMetadata = tmpMetadata
+	val, err := sonic.Marshal(&snapshot)
 	if err != nil {
 		return "", err
 	}
@@ -18,6 +18,7 @@ package langsmith
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 
@@ -235,3 +236,58 @@ func TestFlowTrace_Integration(t *testing.T) {
 
 	mCli.AssertExpectations(t)
 }
+
+// TestFlowTraceSpanSetters 测试 span 的输入输出与错误上报
+func TestFlowTraceSpanSetters(t *testing.T) {
+	mCli := new(mockLangsmith)
+	ft := &FlowTrace{cli: mCli, cfg: &Config{
+		RunIDGen:       func(ctx context.Context) string { return "span-1" },
+		HideInputs:     true,
+		MaxOutputBytes: 28,
+	}}
+	var patches []*RunPatch
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, "span-1", mock.Anything).Run(func(args mock.Arguments) {
+		patches = append(patches, args.Get(2).(*RunPatch))
+	}).Return(nil)
+
+	ctx, runID, err := ft.StartSpan(context.Background(), "span", nil)
+	require.NoError(t, err)
+	require.NoError(t, ft.SetSpanInputs(ctx, runID, map[string]interface{}{"query": "secret"}))
+	require.NoError(t, ft.SetSpanOutputs(ctx, runID, map[string]interface{}{"answer": "a very long answer"}))
+	ft.FinishSpanWithError(ctx, runID, errors.New("boom"))
+
+	require.Len(t, patches, 3)
+	assert.Equal(t, map[string]interface{}{"input": HiddenPlaceholder}, patches[0].Inputs)
+	assert.Contains(t, patches[1].Outputs["output"], "truncated")
+	require.NotNil(t, patches[2].Error)
+	assert.Equal(t, "boom", *patches[2].Error)
+	assert.NotNil(t, patches[2].EndTime)
+}
+
+// TestStartSpanOptions 测试 StartSpan 的 run 类型、输入、标签与元数据选项
+func TestStartSpanOptions(t *testing.T) {
+	mCli := new(mockLangsmith)
+	ft := &FlowTrace{cli: mCli, cfg: &Config{RunIDGen: func(ctx context.Context) string { return "span-1" }}}
+	var created *Run
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*Run)
+	}).Return(nil)
+
+	md := &sync.Map{}
+	md.Store("metadata", map[string]interface{}{"env": "prod"})
+	ctx := SetTrace(context.Background(), AddTag("trace-tag"), SetMetadata(md))
+	_, _, err := ft.StartSpan(ctx, "search", nil,
+		WithSpanRunType(RunTypeTool),
+		WithSpanInputs(map[string]interface{}{"query": "eino"}),
+		WithSpanTags("trace-tag", "span-tag"),
+		WithSpanMetadata(map[string]interface{}{"tool": "web"}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, RunTypeTool, created.RunType)
+	assert.Equal(t, map[string]interface{}{"query": "eino"}, created.Inputs)
+	assert.Equal(t, []string{"trace-tag", "span-tag"}, created.Tags)
+	assert.Equal(t, map[string]interface{}{"env": "prod", "tool": "web"}, created.Extra["metadata"])
+	assert.Equal(t, map[string]interface{}{"env": "prod"}, SafeDeepCopySyncMapMetadata(md)["metadata"], "trace metadata is not modified")
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"sync"
+)
+
+// ForkSpan returns a context for a goroutine doing work on behalf of the run ctx is traced in. The fork holds a
+// snapshot of the trace state, so runs started in the goroutine are children of that run whatever happens to ctx
+// afterwards, and events added in the fork are kept apart until JoinSpan merges them back.
+// ctx is returned unchanged outside of a traced run.
+func ForkSpan(ctx context.Context) context.Context {
+	_, state := GetState(ctx)
+	if state == nil {
+		return ctx
+	}
+	fork := &LangsmithState{
+		TraceID:           state.TraceID,
+		ParentRunID:       state.ParentRunID,
+		ParentDottedOrder: state.ParentDottedOrder,
+		Metadata:          &sync.Map{},
+		Tags:              append([]string(nil), state.Tags...),
+		startTime:         state.startTime,
+		started:           state.started,
+		events:            &runEvents{},
+		cli:               state.cli,
+		session:           state.session,
+		annotations:       state.annotations,
+		root:              state.root,
+	}
+	if state.Metadata != nil {
+		state.Metadata.Range(func(k, v interface{}) bool {
+			fork.Metadata.Store(k, v)
+			return true
+		})
+	}
+	return context.WithValue(ctx, langsmithStateKey{}, fork)
+}
+
+// JoinSpan merges the events and metadata recorded in forks of the run ctx is traced in back into it, forks of other
+// runs are ignored. Call it once the goroutines are done, before the run ends.
+func JoinSpan(ctx context.Context, forks ...context.Context) {
+	_, state := GetState(ctx)
+	if state == nil {
+		return
+	}
+	for _, forkCtx := range forks {
+		_, fork := GetState(forkCtx)
+		if fork == nil || fork == state || fork.ParentRunID != state.ParentRunID {
+			continue
+		}
+		if state.events != nil {
+			for _, event := range fork.events.drain() {
+				state.events.add(event)
+			}
+		}
+		if fork.Metadata != nil {
+			if state.Metadata == nil {
+				state.Metadata = &sync.Map{}
+			}
+			fork.Metadata.Range(func(k, v interface{}) bool {
+				state.Metadata.LoadOrStore(k, v)
+				return true
+			})
+		}
+	}
+}
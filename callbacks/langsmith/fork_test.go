@@ -0,0 +1,85 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForkJoinSpan(t *testing.T) {
+	assert.Equal(t, context.Background(), ForkSpan(context.Background()))
+
+	mCli := new(mockLangsmith)
+	var mu sync.Mutex
+	n := 0
+	ft := &FlowTrace{cli: mCli, cfg: &Config{RunIDGen: func(ctx context.Context) string {
+		mu.Lock()
+		defer mu.Unlock()
+		n++
+		return "run-" + strconv.Itoa(n)
+	}}}
+	parents := map[string]string{}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		run := args.Get(1).(*Run)
+		if run.ParentRunID != nil {
+			mu.Lock()
+			parents[run.ID] = *run.ParentRunID
+			mu.Unlock()
+		}
+	}).Return(nil)
+	var patch *RunPatch
+	mCli.On("UpdateRun", mock.Anything, "run-1", mock.Anything).Run(func(args mock.Arguments) {
+		patch = args.Get(2).(*RunPatch)
+	}).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	ctx, runID, err := ft.StartSpan(context.Background(), "fan-out", nil)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	forks := make([]context.Context, 3)
+	for i := range forks {
+		forks[i] = ForkSpan(ctx)
+		wg.Add(1)
+		go func(forkCtx context.Context) {
+			defer wg.Done()
+			_, state := GetState(forkCtx)
+			childCtx, childID, err := ft.StartSpan(forkCtx, "worker", state)
+			if err == nil {
+				ft.FinishSpan(childCtx, childID)
+			}
+			_ = AddRunEvent(forkCtx, "worker_done", nil)
+		}(forks[i])
+	}
+	wg.Wait()
+	JoinSpan(ctx, forks...)
+	ft.FinishSpan(ctx, runID)
+
+	assert.Len(t, parents, 3)
+	for _, parent := range parents {
+		assert.Equal(t, "run-1", parent)
+	}
+	require.NotNil(t, patch)
+	assert.Len(t, patch.Events, 3)
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/compose"
+)
+
+// ExtraGraphSchema is the key of the GraphSchema attached to the extra of a root graph run.
+const ExtraGraphSchema = "graph_schema"
+
+// GraphSchema is the node and edge structure of a compiled graph, chain or workflow.
+type GraphSchema struct {
+	Name      string              `json:"name,omitempty"`
+	Nodes     []GraphSchemaNode   `json:"nodes"`
+	Edges     []GraphSchemaEdge   `json:"edges,omitempty"`      // control edges
+	DataEdges []GraphSchemaEdge   `json:"data_edges,omitempty"` // workflow data edges
+	Branches  []GraphSchemaBranch `json:"branches,omitempty"`
+}
+
+// GraphSchemaNode is a node of a GraphSchema, Subgraph is set for nested graphs.
+type GraphSchemaNode struct {
+	Key       string       `json:"key"`
+	Name      string       `json:"name,omitempty"`
+	Component string       `json:"component,omitempty"`
+	Subgraph  *GraphSchema `json:"subgraph,omitempty"`
+}
+
+// GraphSchemaEdge connects two nodes of a GraphSchema.
+type GraphSchemaEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// GraphSchemaBranch is a branch starting at From, which routes to one or more of To.
+type GraphSchemaBranch struct {
+	From string   `json:"from"`
+	To   []string `json:"to"`
+}
+
+// OnFinish implements compose.GraphCompileCallback, it records the structure of the compiled graph, which is attached
+// to the extra of the root runs of the graph as ExtraGraphSchema. Graphs are matched by the name given with
+// compose.WithGraphName, e.g.
+//
+//	runnable, err := graph.Compile(ctx, compose.WithGraphName("rag"), compose.WithGraphCompileCallbacks(handler))
+func (c *CallbackHandler) OnFinish(ctx context.Context, info *compose.GraphInfo) {
+	if info == nil {
+		return
+	}
+	c.graphs.Store(info.Name, newGraphSchema(info))
+}
+
+// graphSchema returns the schema of the graph of info, only for root runs.
+func (c *CallbackHandler) graphSchema(info *callbacks.RunInfo, state *LangsmithState) *GraphSchema {
+	if state.ParentRunID != "" {
+		return nil
+	}
+	if !isGraphComponent(info.Component) {
+		return nil
+	}
+	schema, _ := c.graphs.Load(info.Name)
+	s, _ := schema.(*GraphSchema)
+	return s
+}
+
+func newGraphSchema(info *compose.GraphInfo) *GraphSchema {
+	schema := &GraphSchema{
+		Name:      info.Name,
+		Edges:     graphSchemaEdges(info.Edges),
+		DataEdges: graphSchemaEdges(info.DataEdges),
+	}
+	for key, node := range info.Nodes {
+		n := GraphSchemaNode{Key: key, Name: node.Name, Component: string(node.Component)}
+		if node.GraphInfo != nil {
+			n.Subgraph = newGraphSchema(node.GraphInfo)
+		}
+		if n.Component == "" && node.Instance != nil {
+			if typ, ok := components.GetType(node.Instance); ok {
+				n.Component = typ
+			}
+		}
+		schema.Nodes = append(schema.Nodes, n)
+	}
+	sort.Slice(schema.Nodes, func(i, j int) bool { return schema.Nodes[i].Key < schema.Nodes[j].Key })
+
+	for from, branches := range info.Branches {
+		for _, branch := range branches {
+			b := GraphSchemaBranch{From: from}
+			for to := range branch.GetEndNode() {
+				b.To = append(b.To, to)
+			}
+			sort.Strings(b.To)
+			schema.Branches = append(schema.Branches, b)
+		}
+	}
+	sort.SliceStable(schema.Branches, func(i, j int) bool { return schema.Branches[i].From < schema.Branches[j].From })
+	return schema
+}
+
+func graphSchemaEdges(edges map[string][]string) []GraphSchemaEdge {
+	var res []GraphSchemaEdge
+	for from, tos := range edges {
+		for _, to := range tos {
+			res = append(res, GraphSchemaEdge{From: from, To: to})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].From != res[j].From {
+			return res[i].From < res[j].From
+		}
+		return res[i].To < res[j].To
+	})
+	return res
+}
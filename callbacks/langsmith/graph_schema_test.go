@@ -0,0 +1,97 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/compose"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGraphSchema 测试根图节点附带编译时的图结构
+func TestGraphSchema(t *testing.T) {
+	mCli := &mockLangsmith{}
+	h := &CallbackHandler{cli: mCli, cfg: &Config{
+		RunIDGen: func(ctx context.Context) string { return uuid.NewString() },
+	}}
+	var runs []*Run
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		runs = append(runs, args.Get(1).(*Run))
+	}).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	ctx := context.Background()
+	upper := compose.InvokableLambda(func(ctx context.Context, in string) (string, error) { return strings.ToUpper(in), nil })
+	sub := compose.NewChain[string, string]()
+	sub.AppendLambda(upper)
+
+	g := compose.NewGraph[string, string]()
+	require.NoError(t, g.AddLambdaNode("upper", upper))
+	require.NoError(t, g.AddGraphNode("sub", sub))
+	require.NoError(t, g.AddLambdaNode("lower", compose.InvokableLambda(func(ctx context.Context, in string) (string, error) {
+		return strings.ToLower(in), nil
+	})))
+	require.NoError(t, g.AddEdge(compose.START, "upper"))
+	require.NoError(t, g.AddBranch("upper", compose.NewGraphBranch(func(ctx context.Context, in string) (string, error) {
+		return "sub", nil
+	}, map[string]bool{"sub": true, "lower": true})))
+	require.NoError(t, g.AddEdge("sub", compose.END))
+	require.NoError(t, g.AddEdge("lower", compose.END))
+	r, err := g.Compile(ctx, compose.WithGraphName("pipeline"), compose.WithGraphCompileCallbacks(h))
+	require.NoError(t, err)
+
+	out, err := r.Invoke(ctx, "in", compose.WithCallbacks(h))
+	require.NoError(t, err)
+	assert.Equal(t, "IN", out)
+
+	var root *Run
+	for _, run := range runs {
+		if run.ParentRunID == nil {
+			root = run
+		} else {
+			assert.NotContains(t, run.Extra, ExtraGraphSchema)
+		}
+	}
+	require.NotNil(t, root)
+	assert.Equal(t, "pipeline", root.Name)
+	schema, ok := root.Extra[ExtraGraphSchema].(*GraphSchema)
+	require.True(t, ok)
+	assert.Equal(t, "pipeline", schema.Name)
+	require.Len(t, schema.Nodes, 3)
+	assert.Equal(t, "lower", schema.Nodes[0].Key)
+	assert.Equal(t, string(compose.ComponentOfLambda), schema.Nodes[0].Component)
+	assert.Equal(t, "sub", schema.Nodes[1].Key)
+	require.NotNil(t, schema.Nodes[1].Subgraph)
+	assert.Len(t, schema.Nodes[1].Subgraph.Nodes, 1)
+	assert.Equal(t, []GraphSchemaEdge{
+		{From: "lower", To: compose.END},
+		{From: compose.START, To: "upper"},
+		{From: "sub", To: compose.END},
+	}, schema.Edges)
+	assert.Equal(t, []GraphSchemaBranch{{From: "upper", To: []string{"lower", "sub"}}}, schema.Branches)
+
+	// unknown graph
+	_ = h.OnStart(ctx, &callbacks.RunInfo{Name: "other", Component: compose.ComponentOfGraph}, "in")
+	assert.NotContains(t, runs[len(runs)-1].Extra, ExtraGraphSchema)
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package grpctrace propagates langsmith traces across gRPC calls, so runs of the called service join the trace
+// of the caller. The trace is carried by the langsmith-trace, baggage and traceparent metadata.
+//
+// It's a module of its own, so that the langsmith module doesn't depend on gRPC.
+package grpctrace
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/cloudwego/eino-ext/callbacks/langsmith"
+)
+
+// UnaryClientInterceptor propagates the run the call context is traced in to the called service.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(inject(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor propagates the run the stream context is traced in to the called service.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(inject(ctx), desc, cc, method, opts...)
+	}
+}
+
+// UnaryServerInterceptor continues the trace of incoming calls, runs started from the handler context join it.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(extract(ctx), req)
+	}
+}
+
+// StreamServerInterceptor continues the trace of incoming streams, runs started from the stream context join it.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := extract(ss.Context())
+		if ctx == ss.Context() {
+			return handler(srv, ss)
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func inject(ctx context.Context) context.Context {
+	header := http.Header{}
+	langsmith.InjectTraceContext(ctx, header)
+	if len(header) == 0 {
+		return ctx
+	}
+	kv := make([]string, 0, 2*len(header))
+	for key := range header {
+		kv = append(kv, strings.ToLower(key), header.Get(key))
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+func extract(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	header := http.Header{}
+	for _, key := range []string{langsmith.TraceHeader, langsmith.BaggageHeader, langsmith.TraceparentHeader} {
+		if values := md.Get(key); len(values) > 0 {
+			header.Set(key, values[0])
+		}
+	}
+	return langsmith.ExtractTraceContext(ctx, header)
+}
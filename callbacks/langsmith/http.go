@@ -0,0 +1,73 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"net/http"
+)
+
+// ExtractTraceContext continues the trace carried by header: the langsmith-trace and baggage headers of the
+// langsmith SDKs take precedence, a W3C traceparent is used otherwise.
+func ExtractTraceContext(ctx context.Context, header http.Header) context.Context {
+	if header.Get(TraceHeader) != "" {
+		return ExtractHeaders(ctx, header)
+	}
+	return ExtractFromHTTPHeaders(ctx, header)
+}
+
+// InjectTraceContext writes the run ctx is traced in into the langsmith-trace, baggage and traceparent headers.
+func InjectTraceContext(ctx context.Context, header http.Header) {
+	InjectHeaders(ctx, header)
+	InjectTraceparent(ctx, header)
+}
+
+// HTTPMiddleware continues the trace of incoming requests, see ExtractTraceContext.
+// Runs started from the request context join the trace.
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := ExtractTraceContext(r.Context(), r.Header)
+		if ctx != r.Context() {
+			r = r.WithContext(ctx)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// NewHTTPTransport wraps base, http.DefaultTransport if nil, to propagate the run the request context is traced in
+// to the called service, by the langsmith-trace, baggage and traceparent headers.
+func NewHTTPTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &httpTransport{base: base}
+}
+
+type httpTransport struct {
+	base http.RoundTripper
+}
+
+func (t *httpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if _, state := GetState(ctx); state == nil {
+		return t.base.RoundTrip(req)
+	}
+	// a RoundTripper must not modify the request
+	req = req.Clone(ctx)
+	InjectTraceContext(ctx, req.Header)
+	return t.base.RoundTrip(req)
+}
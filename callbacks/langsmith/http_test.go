@@ -0,0 +1,64 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPPropagation(t *testing.T) {
+	var got *LangsmithState
+	srv := httptest.NewServer(HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, got = GetState(r.Context())
+	})))
+	defer srv.Close()
+
+	cli := &http.Client{Transport: NewHTTPTransport(nil)}
+	ctx := SetTrace(context.Background(), WithSessionName("proj"))
+	ctx = context.WithValue(ctx, langsmithStateKey{}, &LangsmithState{
+		TraceID:           "0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0",
+		ParentRunID:       "1f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f1",
+		ParentDottedOrder: "20250102T030405000000Z0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0.20250102T030405000100Z1f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f1",
+	})
+	req, err := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+	require.NoError(t, err)
+	resp, err := cli.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Empty(t, req.Header, "the original request is not modified")
+
+	require.NotNil(t, got)
+	assert.Equal(t, "0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0", got.TraceID)
+	assert.Equal(t, "1f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f1", got.ParentRunID)
+
+	// W3C traceparent only
+	got = nil
+	req, err = http.NewRequest("GET", srv.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.NotNil(t, got)
+	assert.Equal(t, "4bf92f35-77b3-4da6-a3ce-929d0e0e4736", got.TraceID)
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/indexer"
+	"github.com/cloudwego/eino/components/retriever"
+)
+
+// Metadata keys of indexer and retriever runs.
+const (
+	MetadataDocumentCount = "document_count" // number of documents written by an indexer
+	MetadataCollection    = "collection"     // vector store collection or index an indexer or retriever works on
+)
+
+// collectionExtraKeys are the keys of the callback extra vector store components report their collection under.
+var collectionExtraKeys = []string{"collection", "collection_name", "index", "index_name"}
+
+// setVectorStoreMetadata records the number of documents written by an indexer and the collection of an indexer or
+// retriever run in extra.
+func setVectorStoreMetadata(extra map[string]interface{}, info *callbacks.RunInfo, input callbacks.CallbackInput) {
+	var callbackExtra map[string]any
+	switch info.Component {
+	case components.ComponentOfIndexer:
+		in := indexer.ConvCallbackInput(input)
+		if in == nil {
+			return
+		}
+		setRunMetadata(extra, MetadataDocumentCount, len(in.Docs))
+		callbackExtra = in.Extra
+	case components.ComponentOfRetriever:
+		in := retriever.ConvCallbackInput(input)
+		if in == nil {
+			return
+		}
+		callbackExtra = in.Extra
+	default:
+		return
+	}
+	for _, key := range collectionExtraKeys {
+		if collection, ok := callbackExtra[key].(string); ok && collection != "" {
+			setRunMetadata(extra, MetadataCollection, collection)
+			return
+		}
+	}
+}
+
+// indexerInputs returns the run inputs of an indexer run: the written documents. nil for other components.
+func indexerInputs(info *callbacks.RunInfo, input callbacks.CallbackInput, limit int) map[string]interface{} {
+	if info.Component != components.ComponentOfIndexer {
+		return nil
+	}
+	in := indexer.ConvCallbackInput(input)
+	if in == nil {
+		return nil
+	}
+	return map[string]interface{}{"documents": limitPayload(lsDocuments(in.Docs), limit)}
+}
+
+// indexerOutputs returns the run outputs of an indexer run: the ids of the stored documents. nil for other components.
+func indexerOutputs(info *callbacks.RunInfo, output callbacks.CallbackOutput, limit int) map[string]interface{} {
+	if info.Component != components.ComponentOfIndexer {
+		return nil
+	}
+	out := indexer.ConvCallbackOutput(output)
+	if out == nil {
+		return nil
+	}
+	return map[string]interface{}{"ids": limitPayload(out.IDs, limit), MetadataDocumentCount: len(out.IDs)}
+}
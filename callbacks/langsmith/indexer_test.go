@@ -0,0 +1,69 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/indexer"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIndexerRunCapture 测试索引写入记录文档数量、集合名称与文档 ID
+func TestIndexerRunCapture(t *testing.T) {
+	mCli := new(mockLangsmith)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{RunIDGen: func(ctx context.Context) string { return "index-run" }}}
+	var created *Run
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*Run)
+	}).Return(nil)
+	var patched *RunPatch
+	mCli.On("UpdateRun", mock.Anything, "index-run", mock.Anything).Run(func(args mock.Arguments) {
+		patched = args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	info := &callbacks.RunInfo{Name: "milvus", Component: components.ComponentOfIndexer}
+	docs := []*schema.Document{{ID: "doc-1", Content: "eino"}, nil, {Content: "langsmith"}}
+	ctx := h.OnStart(context.Background(), info, &indexer.CallbackInput{Docs: docs, Extra: map[string]any{"collection_name": "kb"}})
+	h.OnEnd(ctx, info, &indexer.CallbackOutput{IDs: []string{"doc-1", "doc-2"}})
+
+	require.NotNil(t, created)
+	assert.Equal(t, RunTypeChain, created.RunType)
+	metadata := created.Extra["metadata"].(map[string]interface{})
+	assert.Equal(t, 3, metadata[MetadataDocumentCount])
+	assert.Equal(t, "kb", metadata[MetadataCollection])
+	written := created.Inputs["documents"].([]*lsDocument)
+	require.Len(t, written, 2)
+	assert.Equal(t, "doc-1", written[0].Metadata["id"])
+
+	require.NotNil(t, patched)
+	assert.Equal(t, []string{"doc-1", "doc-2"}, patched.Outputs["ids"])
+	assert.Equal(t, 2, patched.Outputs[MetadataDocumentCount])
+
+	// retrievers report their collection too
+	extra := map[string]interface{}{}
+	setVectorStoreMetadata(extra, &callbacks.RunInfo{Component: components.ComponentOfRetriever},
+		&retriever.CallbackInput{Query: "q", Extra: map[string]any{"index": "kb"}})
+	assert.Equal(t, "kb", extra["metadata"].(map[string]interface{})[MetadataCollection])
+}
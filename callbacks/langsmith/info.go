@@ -0,0 +1,88 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const opInfo APIOp = "info"
+
+// ServerInfo describes the langsmith deployment, as returned by its /info endpoint.
+type ServerInfo struct {
+	Version           string                 `json:"version"`
+	LicenseExpiration *time.Time             `json:"license_expiration_time,omitempty"`
+	BatchIngestConfig *BatchIngestConfig     `json:"batch_ingest_config,omitempty"`
+	InstanceFlags     map[string]interface{} `json:"instance_flags,omitempty"` // features enabled on the deployment
+}
+
+// BatchIngestConfig holds the ingestion limits of the deployment.
+type BatchIngestConfig struct {
+	SizeLimit      int `json:"size_limit"`       // max runs per batch
+	SizeLimitBytes int `json:"size_limit_bytes"` // max size of a request body
+}
+
+// InfoClient reads the info of the langsmith deployment, it's implemented by the client returned by NewLangsmith.
+type InfoClient interface {
+	Info(ctx context.Context) (*ServerInfo, error)
+}
+
+// Info reads the version and limits of the langsmith deployment, it's also a cheap connectivity check.
+func (c *langsmithClient) Info(ctx context.Context) (*ServerInfo, error) {
+	info := &ServerInfo{}
+	if err := c.doJSON(ctx, opInfo, "GET", "/info", nil, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// DefaultVerifyTimeout bounds the connectivity check of Config.VerifyConnection.
+const DefaultVerifyTimeout = 5 * time.Second
+
+// verify checks the connectivity to langsmith and adapts the config to the limits of the deployment:
+// unset payload limits are derived from the request size limit, so oversized runs are truncated instead of rejected.
+func (c *Config) verify(cli Langsmith) (*ServerInfo, error) {
+	api, err := apiClient[InfoClient](cli, "info")
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultVerifyTimeout)
+	defer cancel()
+	info, err := api.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to langsmith: %w", err)
+	}
+	if info.BatchIngestConfig != nil && info.BatchIngestConfig.SizeLimitBytes > 0 {
+		// inputs and outputs share the request with the rest of the run
+		limit := info.BatchIngestConfig.SizeLimitBytes / 2
+		if c.MaxInputBytes == 0 {
+			c.MaxInputBytes = limit
+		}
+		if c.MaxOutputBytes == 0 {
+			c.MaxOutputBytes = limit
+		}
+	}
+	c.logger().Debug(ctx, "connected to langsmith", "version", info.Version)
+	return info, nil
+}
+
+// ServerInfo returns the langsmith deployment info read by Config.VerifyConnection, nil if it's not set.
+func (c *CallbackHandler) ServerInfo() *ServerInfo {
+	return c.info
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/info", r.URL.Path)
+		if r.Header.Get("x-api-key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"version":"0.10.1","batch_ingest_config":{"size_limit":100,"size_limit_bytes":20000},"instance_flags":{"search_enabled":true}}`))
+	}))
+	defer srv.Close()
+
+	info, err := NewLangsmith("key", srv.URL).(*langsmithClient).Info(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "0.10.1", info.Version)
+	assert.Equal(t, 100, info.BatchIngestConfig.SizeLimit)
+	assert.Equal(t, true, info.InstanceFlags["search_enabled"])
+
+	cfg := &Config{APIKey: "key", APIURL: srv.URL, VerifyConnection: true, MaxOutputBytes: 100}
+	h, err := NewLangsmithHandler(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "0.10.1", h.ServerInfo().Version)
+	assert.Equal(t, 10000, cfg.MaxInputBytes)
+	assert.Equal(t, 100, cfg.MaxOutputBytes)
+
+	_, err = NewLangsmithHandler(&Config{APIKey: "wrong", APIURL: srv.URL, VerifyConnection: true})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	h, err = NewLangsmithHandler(&Config{APIKey: "wrong", APIURL: srv.URL})
+	require.NoError(t, err)
+	assert.Nil(t, h.ServerInfo())
+}
@@ -0,0 +1,270 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+)
+
+// DefaultLangfuseHost is the langfuse cloud host used when LangfuseConfig.Host is empty.
+const DefaultLangfuseHost = "https://cloud.langfuse.com"
+
+// LangfuseConfig configures the langfuse export, set it as Config.Langfuse to write every run to both langsmith and
+// langfuse, or use NewLangfuseExporter directly as a RunExporter.
+type LangfuseConfig struct {
+	Host      string // default: DefaultLangfuseHost
+	PublicKey string // required
+	SecretKey string // required
+	// HTTPClient sends the ingestion requests, default timeout is 10s
+	HTTPClient *http.Client
+}
+
+// LangfuseExporter is a RunExporter mapping runs to the langfuse data model: the root run of a trace becomes a
+// langfuse trace, every run becomes an observation in it, a generation for llm runs and a span otherwise.
+// Runs are sent to the langfuse ingestion API.
+type LangfuseExporter struct {
+	host       string
+	auth       string
+	httpClient *http.Client
+
+	mu   sync.Mutex
+	runs map[string]*langfuseRun // unfinished runs by run id
+}
+
+type langfuseRun struct {
+	traceID string
+	root    bool
+	kind    string // "span" or "generation"
+}
+
+// NewLangfuseExporter creates a LangfuseExporter.
+func NewLangfuseExporter(cfg *LangfuseConfig) (*LangfuseExporter, error) {
+	if cfg == nil || cfg.PublicKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("langfuse public key and secret key are required")
+	}
+	host := strings.TrimRight(cfg.Host, "/")
+	if host == "" {
+		host = DefaultLangfuseHost
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	req, _ := http.NewRequest(http.MethodPost, host, nil)
+	req.SetBasicAuth(cfg.PublicKey, cfg.SecretKey)
+	return &LangfuseExporter{
+		host:       host,
+		auth:       req.Header.Get("Authorization"),
+		httpClient: httpClient,
+		runs:       map[string]*langfuseRun{},
+	}, nil
+}
+
+// langfuseEvent is one item of an ingestion batch.
+type langfuseEvent struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	Timestamp time.Time              `json:"timestamp"`
+	Body      map[string]interface{} `json:"body"`
+}
+
+// CreateRun implements RunExporter
+func (e *LangfuseExporter) CreateRun(ctx context.Context, run *Run) error {
+	lr := &langfuseRun{traceID: run.TraceID, root: run.ParentRunID == nil, kind: "span"}
+	if lr.traceID == "" {
+		lr.traceID = run.ID
+	}
+	if run.RunType == RunTypeLLM {
+		lr.kind = "generation"
+	}
+	if run.EndTime == nil {
+		e.mu.Lock()
+		e.runs[run.ID] = lr
+		e.mu.Unlock()
+	}
+
+	var events []*langfuseEvent
+	if lr.root {
+		trace := map[string]interface{}{
+			"id":        lr.traceID,
+			"name":      run.Name,
+			"timestamp": run.StartTime,
+			"input":     run.Inputs,
+		}
+		if len(run.Tags) > 0 {
+			trace["tags"] = run.Tags
+		}
+		if md := langfuseMetadata(run.Extra); md != nil {
+			trace["metadata"] = md
+		}
+		if run.Outputs != nil {
+			trace["output"] = run.Outputs
+		}
+		events = append(events, newLangfuseEvent("trace-create", trace))
+	}
+
+	obs := map[string]interface{}{
+		"id":        run.ID,
+		"traceId":   lr.traceID,
+		"name":      run.Name,
+		"startTime": run.StartTime,
+		"input":     run.Inputs,
+	}
+	if run.ParentRunID != nil {
+		obs["parentObservationId"] = *run.ParentRunID
+	}
+	if md := langfuseMetadata(run.Extra); md != nil {
+		obs["metadata"] = md
+	}
+	if lr.kind == "generation" {
+		setLangfuseGeneration(obs, run.Extra)
+	}
+	setLangfuseEnd(obs, run.EndTime, run.Outputs, run.Error)
+	events = append(events, newLangfuseEvent(lr.kind+"-create", obs))
+	return e.ingest(ctx, events)
+}
+
+// UpdateRun implements RunExporter, patches of runs created before the exporter existed are ignored.
+func (e *LangfuseExporter) UpdateRun(ctx context.Context, runID string, patch *RunPatch) error {
+	e.mu.Lock()
+	lr, ok := e.runs[runID]
+	if ok && patch.EndTime != nil {
+		delete(e.runs, runID)
+	}
+	e.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	obs := map[string]interface{}{
+		"id":      runID,
+		"traceId": lr.traceID,
+	}
+	if patch.Inputs != nil {
+		obs["input"] = patch.Inputs
+	}
+	if md := langfuseMetadata(patch.Extra); md != nil {
+		obs["metadata"] = md
+	}
+	if lr.kind == "generation" {
+		setLangfuseGeneration(obs, patch.Extra)
+	}
+	setLangfuseEnd(obs, patch.EndTime, patch.Outputs, patch.Error)
+	events := []*langfuseEvent{newLangfuseEvent(lr.kind+"-update", obs)}
+	if lr.root && patch.Outputs != nil {
+		// trace-create upserts, this sets the output of the trace
+		events = append(events, newLangfuseEvent("trace-create", map[string]interface{}{
+			"id":     lr.traceID,
+			"output": patch.Outputs,
+		}))
+	}
+	return e.ingest(ctx, events)
+}
+
+func newLangfuseEvent(typ string, body map[string]interface{}) *langfuseEvent {
+	return &langfuseEvent{ID: uuid.NewString(), Type: typ, Timestamp: time.Now().UTC(), Body: body}
+}
+
+func setLangfuseEnd(obs map[string]interface{}, endTime *time.Time, outputs map[string]interface{}, errMsg *string) {
+	if endTime != nil {
+		obs["endTime"] = *endTime
+	}
+	if outputs != nil {
+		obs["output"] = outputs
+	}
+	if errMsg != nil {
+		obs["level"] = "ERROR"
+		obs["statusMessage"] = *errMsg
+	}
+}
+
+func langfuseMetadata(extra map[string]interface{}) map[string]interface{} {
+	md, _ := extra["metadata"].(map[string]interface{})
+	if len(md) == 0 {
+		return nil
+	}
+	return md
+}
+
+// setLangfuseGeneration maps the ls_* metadata conventions and usage to the langfuse generation fields.
+func setLangfuseGeneration(obs map[string]interface{}, extra map[string]interface{}) {
+	md := langfuseMetadata(extra)
+	if name, ok := md["ls_model_name"].(string); ok && name != "" {
+		obs["model"] = name
+	}
+	if params, ok := extra["invocation_params"].(map[string]interface{}); ok && len(params) > 0 {
+		obs["modelParameters"] = params
+	}
+	if usage, ok := md["usage_metadata"].(map[string]int); ok {
+		obs["usage"] = map[string]interface{}{
+			"input":  usage["input_tokens"],
+			"output": usage["output_tokens"],
+			"total":  usage["total_tokens"],
+			"unit":   "TOKENS",
+		}
+	}
+}
+
+type langfuseIngestionResponse struct {
+	Errors []struct {
+		ID      string      `json:"id"`
+		Status  int         `json:"status"`
+		Message interface{} `json:"message"`
+	} `json:"errors"`
+}
+
+// ingest sends a batch to the ingestion API, which answers 207 with the per event errors.
+func (e *LangfuseExporter) ingest(ctx context.Context, events []*langfuseEvent) error {
+	data, err := sonic.Marshal(map[string]interface{}{"batch": events})
+	if err != nil {
+		return fmt.Errorf("failed to marshal langfuse batch: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.host+"/api/public/ingestion", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", e.auth)
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("failed to ingest langfuse events, status: %s, body: %s", resp.Status, string(body))
+	}
+	result := &langfuseIngestionResponse{}
+	if len(body) > 0 && sonic.Unmarshal(body, result) == nil && len(result.Errors) > 0 {
+		first := result.Errors[0]
+		return fmt.Errorf("failed to ingest %d langfuse events, first: status %d, %v", len(result.Errors), first.Status, first.Message)
+	}
+	return nil
+}
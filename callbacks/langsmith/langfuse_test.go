@@ -0,0 +1,135 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type langfuseServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	batches [][]*langfuseEvent
+	resp    string
+}
+
+func newLangfuseServer(t *testing.T) *langfuseServer {
+	s := &langfuseServer{resp: `{"successes":[],"errors":[]}`}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/public/ingestion", r.URL.Path)
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "pk", user)
+		assert.Equal(t, "sk", pass)
+		body, _ := io.ReadAll(r.Body)
+		req := struct {
+			Batch []*langfuseEvent `json:"batch"`
+		}{}
+		assert.NoError(t, sonic.Unmarshal(body, &req))
+		s.mu.Lock()
+		s.batches = append(s.batches, req.Batch)
+		resp := s.resp
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusMultiStatus)
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestLangfuseExporter(t *testing.T) {
+	_, err := NewLangfuseExporter(&LangfuseConfig{PublicKey: "pk"})
+	assert.Error(t, err)
+
+	srv := newLangfuseServer(t)
+	exp, err := NewLangfuseExporter(&LangfuseConfig{Host: srv.URL + "/", PublicKey: "pk", SecretKey: "sk"})
+	require.NoError(t, err)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	root := "root"
+
+	require.NoError(t, exp.CreateRun(ctx, &Run{ID: "root", TraceID: "root", Name: "graph", RunType: RunTypeChain,
+		StartTime: now, Inputs: map[string]interface{}{"input": "hi"}, Tags: []string{"t"}}))
+	require.NoError(t, exp.CreateRun(ctx, &Run{ID: "llm", TraceID: "root", ParentRunID: &root, Name: "ChatModel",
+		RunType: RunTypeLLM, StartTime: now, Extra: map[string]interface{}{
+			"metadata":          map[string]interface{}{"ls_model_name": "gpt-4o"},
+			"invocation_params": map[string]interface{}{"temperature": 0.5},
+		}}))
+	extra := map[string]interface{}{}
+	(&Config{}).reportUsage(extra, &model.TokenUsage{PromptTokens: 3, CompletionTokens: 4, TotalTokens: 7})
+	errMsg := "boom"
+	require.NoError(t, exp.UpdateRun(ctx, "llm", &RunPatch{EndTime: &now, Extra: extra, Error: &errMsg}))
+	require.NoError(t, exp.UpdateRun(ctx, "root", &RunPatch{EndTime: &now, Outputs: map[string]interface{}{"output": "bye"}}))
+	// finished and unknown runs are ignored
+	require.NoError(t, exp.UpdateRun(ctx, "root", &RunPatch{EndTime: &now}))
+	assert.Empty(t, exp.runs)
+
+	require.Len(t, srv.batches, 4)
+	trace, rootSpan := srv.batches[0][0], srv.batches[0][1]
+	assert.Equal(t, "trace-create", trace.Type)
+	assert.Equal(t, "root", trace.Body["id"])
+	assert.Equal(t, "graph", trace.Body["name"])
+	assert.Equal(t, "span-create", rootSpan.Type)
+	assert.Equal(t, "root", rootSpan.Body["traceId"])
+
+	gen := srv.batches[1][0]
+	assert.Equal(t, "generation-create", gen.Type)
+	assert.Equal(t, "root", gen.Body["parentObservationId"])
+	assert.Equal(t, "gpt-4o", gen.Body["model"])
+	assert.Equal(t, map[string]interface{}{"temperature": 0.5}, gen.Body["modelParameters"])
+
+	genEnd := srv.batches[2][0]
+	assert.Equal(t, "generation-update", genEnd.Type)
+	assert.Equal(t, "ERROR", genEnd.Body["level"])
+	assert.Equal(t, "boom", genEnd.Body["statusMessage"])
+	assert.Equal(t, map[string]interface{}{"input": float64(3), "output": float64(4), "total": float64(7), "unit": "TOKENS"}, genEnd.Body["usage"])
+
+	require.Len(t, srv.batches[3], 2)
+	assert.Equal(t, "span-update", srv.batches[3][0].Type)
+	assert.Equal(t, "trace-create", srv.batches[3][1].Type)
+	assert.Equal(t, map[string]interface{}{"output": "bye"}, srv.batches[3][1].Body["output"])
+
+	srv.mu.Lock()
+	srv.resp = `{"errors":[{"id":"1","status":400,"message":"invalid"}]}`
+	srv.mu.Unlock()
+	assert.ErrorContains(t, exp.CreateRun(ctx, &Run{ID: "other", StartTime: now}), "invalid")
+}
+
+func TestLangfuseDualExport(t *testing.T) {
+	srv := newLangfuseServer(t)
+	mCli := &mockLangsmith{}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil).Once()
+	cfg := &Config{Exporter: mCli, Langfuse: &LangfuseConfig{Host: srv.URL, PublicKey: "pk", SecretKey: "sk"}}
+	require.NoError(t, cfg.newClient().CreateRun(context.Background(), &Run{ID: "root", StartTime: time.Now()}))
+	mCli.AssertExpectations(t)
+	assert.Len(t, srv.batches, 1)
+
+	_, err := NewLangsmithHandler(&Config{Langfuse: &LangfuseConfig{}})
+	assert.Error(t, err)
+}
@@ -18,45 +18,324 @@ package langsmith
 
 import (
 	"context"
-	"fmt"
 	"io"
-	"log"
 	"runtime/debug"
 	"sync"
 	"time"
 
-	"github.com/bytedance/sonic"
 	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
-	"github.com/google/uuid"
 )
 
 // Config LangsmithHandler configuration
 type Config struct {
 	APIKey   string                           // langsmith api key
 	APIURL   string                           // langsmith api url, default:https://api.smith.langchain.com
-	RunIDGen func(ctx context.Context) string // langsmith run_id generator
+	RunIDGen func(ctx context.Context) string // langsmith run_id generator, default: DefaultRunIDGen, see NewSequentialRunIDGen
+
+	// IngestURL is the url runs are written to, for deployments with an ingest host separate from the api one.
+	// Reads, datasets and feedback still go to APIURL. default: APIURL
+	IngestURL string
+	// WorkspaceID selects the workspace of all requests, for API keys having access to several workspaces. The
+	// workspace of a trace set by WithWorkspaceID takes precedence. default: the workspace of the API key
+	WorkspaceID string
+	// AuthProvider authenticates every request, e.g. with a bearer token or an HMAC signature required by a gateway in
+	// front of langsmith, see BearerAuth. default: the x-api-key header only
+	AuthProvider AuthProvider
+
+	// SessionName is the default langsmith project (session) name, used when the trace doesn't set one by WithSessionName.
+	SessionName string
+	// AutoCreateProject creates the project of a run on first use with these defaults if it doesn't exist, instead of
+	// letting its runs land in the default project. Runs exported by Exporter don't create projects. default: disabled
+	AutoCreateProject *ProjectDefaults
+	// Disabled turns the handler into a no-op, eino skips it entirely.
+	Disabled bool
+
+	// HideInputs replaces run inputs with HiddenPlaceholder, while keeping run structure, timings and model metadata.
+	HideInputs bool
+	// HideOutputs replaces run outputs with HiddenPlaceholder, while keeping run structure, timings and token usage.
+	HideOutputs bool
+
+	// MaxInputBytes limits the serialized size of run inputs, oversized payloads keep their head and tail. 0 means no limit.
+	MaxInputBytes int
+	// MaxOutputBytes limits the serialized size of run outputs, oversized payloads keep their head and tail. 0 means no limit.
+	MaxOutputBytes int
+
+	// Filter skips tracing of some components, node names or run types, e.g. all output parsers. default: trace all
+	Filter *RunFilter
+	// Sampler decides which traces are exported, it's called once per trace on its root run, see NewRatioSampler.
+	// default: export all
+	Sampler Sampler
+
+	// RootOnly exports only the outermost run traced by the handler, with the token usage, cost, number of nested
+	// components and their errors aggregated in its metadata. It drastically reduces the API volume of high-traffic
+	// endpoints where the node level detail isn't needed.
+	RootOnly bool
+
+	// DisableUsageRollup stops aggregating the token usage and cost of model runs into the metadata of their ancestor
+	// runs, under MetadataRollupUsage and MetadataRollupCost. With the rollup, the root run shows the total tokens of
+	// a request. Usage of model streams still being read when an ancestor ends is missing from it.
+	DisableUsageRollup bool
+	// TraceBudget bounds the tokens and cost of the model runs of each trace, the root run of a trace exceeding it is
+	// tagged TagBudgetExceeded and TraceBudget.OnExceeded is called. default: unbounded
+	TraceBudget *TraceBudget
+	// LatencyBreakdown reports the time spent in the llm, tool, retriever and other runs of each trace, and the
+	// queueing gaps between them, in the MetadataLatencyBreakdown metadata of the root run.
+	LatencyBreakdown bool
+
+	// TraceMetadataOnRoot records the metadata set by the trace options, e.g. SetMetadata or WithUserID, on the run
+	// they are first applied to, usually the root of the trace, instead of repeating it on every child run. It saves
+	// the payload of large trace metadata, at the cost of filtering child runs by it in langsmith.
+	TraceMetadataOnRoot bool
+
+	// AgentGraphNames are the names of the agent graphs whose runs are grouped by iteration: every chat model call
+	// starts an "iteration N" chain run holding the model and the tool runs that follow it. default: DefaultAgentGraphNames
+	AgentGraphNames []string
+	// FlatAgentLoops traces the runs of agent graphs as direct children of the agent run instead.
+	FlatAgentLoops bool
+	// MultiAgentGraphNames are the names of the host multi-agent graphs whose host and sub-agent runs are annotated
+	// with the agent name, role and handoff arguments, see MetadataAgentRole. default: DefaultMultiAgentGraphNames
+	MultiAgentGraphNames []string
+
+	// ErrorStackTrace adds a stack trace to the ErrorDetails of failed runs: the one recorded by the error, e.g. by
+	// github.com/pkg/errors, or else the stack the error is reported from.
+	ErrorStackTrace bool
+
+	// InlineMedia keeps the base64 images, audio, videos and files of chat model inputs inline in the run inputs.
+	// By default they are uploaded as run attachments, and their data urls replaced with AttachmentURLPrefix+name.
+	InlineMedia bool
+
+	// FullEmbeddings records the full vectors returned by embedding components, e.g. to debug them. By default only
+	// the dimensions and the first EmbeddingPreviewSize values of each vector are recorded.
+	FullEmbeddings bool
+
+	// SchemaVersion pins the format of the runs sent to langsmith, e.g. SchemaV1 for older self-hosted servers.
+	// default: the latest version
+	SchemaVersion SchemaVersion
+
+	// RunNameFunc overrides the run names shown in langsmith, e.g. to prefix them with the graph name or map node keys
+	// to human-friendly labels. An empty result falls back to the default: RunInfo.Name, or Type+Component if unnamed.
+	RunNameFunc func(ctx context.Context, info *callbacks.RunInfo) string
+	// NodePathRunNames names runs after their node path, e.g. "agent/tools/ChatModel" instead of "ChatModel", so runs
+	// of sub-graphs stay readable in the run tree. RunNameFunc takes precedence. The path is always recorded in the
+	// MetadataNodePath metadata.
+	NodePathRunNames bool
+	// NodeMetadata attaches static tags and metadata to the runs of graph nodes, by node name. It takes precedence
+	// over WithNodeMetadata.
+	NodeMetadata map[string]NodeMetadata
+
+	// Serializer converts component inputs and outputs into run inputs and outputs. default: sonic
+	Serializer Serializer
+
+	// VerifyConnection makes NewLangsmithHandler check the connectivity to langsmith with the /info endpoint, and fail
+	// if it's unreachable or the API key is rejected. Unset MaxInputBytes and MaxOutputBytes are derived from the
+	// request size limit of the deployment.
+	VerifyConnection bool
+
+	// Logger receives errors and dropped-run warnings of the handler, FlowTrace and client. default: standard library log
+	Logger Logger
+
+	// Marshaler encodes and decodes the langsmith API requests, e.g. to plug in encoding/json. default: sonic
+	Marshaler Marshaler
+
+	// RateLimit limits the requests sent to langsmith per second, with bursts of up to RateBurst requests, so a burst of
+	// traced traffic doesn't trip the langsmith rate limits. 0 means no limit. RateBurst defaults to 1.
+	RateLimit float64
+	RateBurst int
+	// MaxInFlightRequests caps the requests sent to langsmith concurrently. 0 means no cap.
+	MaxInFlightRequests int
+
+	// CircuitBreakerThreshold opens the circuit breaker after this many consecutive failed requests: while open, runs
+	// are dropped, or spooled with SpoolDir, without calling langsmith, until a probe request succeeds after
+	// CircuitBreakerCooldown. 0 disables the breaker. CircuitBreakerCooldown defaults to DefaultCircuitBreakerCooldown.
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
+
+	// MaxRetries is how many times a failed langsmith request is retried before giving up. default: 0
+	MaxRetries int
+	// OnError is called when creating or updating a run ultimately fails, after retries.
+	// for a failed update, the run only carries the id and the patched fields.
+	OnError func(ctx context.Context, run *Run, err error)
+	// Metrics receives export counters and latencies, see NewExpvarMetrics for a ready-made implementation.
+	Metrics Metrics
+
+	// SpoolDir enables the disk spool of CallbackHandler: requests that ultimately failed are appended to
+	// JSON Lines files in this directory and re-submitted in background once the endpoint is healthy again.
+	// Requests rejected by langsmith, e.g. with 400 or 413, aren't spooled since they can't succeed later.
+	// The files are only readable by the owner, they hold the API keys set with WithAPIKey.
+	SpoolDir string
+	// SpoolReplayInterval is how often the spool is replayed. default: DefaultSpoolReplayInterval
+	SpoolReplayInterval time.Duration
+
+	// ExportTimeout bounds the export of every run, including retries. Exports are detached from the cancellation of
+	// the traced context, so the final update of a run isn't lost when the caller cancels it right after the graph
+	// returns. default: DefaultExportTimeout, a negative value disables the timeout
+	ExportTimeout time.Duration
+	// PropagateDeadline exports runs with the deadline and cancellation of the traced context instead.
+	PropagateDeadline bool
+
+	// Blocking makes CallbackHandler export every run synchronously inside the callback, e.g. OnEnd returns only after
+	// the run update is acknowledged. Useful for batch evaluation jobs where completeness matters more than latency.
+	// By default runs are exported asynchronously and never add latency to the traced graph.
+	Blocking bool
+	// QueueSize is the capacity of the async export queue, runs are dropped when it's full. default: DefaultQueueSize
+	QueueSize int
+	// PatchCoalesceWindow holds the updates of runs in the async export queue for this long, and merges the later
+	// updates of the same run into them, e.g. the partial and final outputs of a stream, saving API requests at the
+	// cost of the export latency. 0 disables coalescing.
+	PatchCoalesceWindow time.Duration
+
+	// PendingRunTTL terminates the runs that didn't end within it with an "abandoned" error, e.g. runs of crashed
+	// requests, so they don't stay pending in langsmith forever. They are checked every half PendingRunTTL and on
+	// Flush. Runs still pending at Shutdown are terminated regardless. 0 disables the check, only the runs of the
+	// graphs in progress are tracked then, to terminate the runs of their panicked nodes and at Shutdown.
+	PendingRunTTL time.Duration
+
+	// StreamUpdateChunks and StreamUpdateInterval enable partial updates of long streamed outputs: the run is patched
+	// with the output concatenated so far every StreamUpdateChunks chunks or, when a chunk arrives, if
+	// StreamUpdateInterval elapsed since the last update. 0 disables the respective trigger. default: disabled
+	StreamUpdateChunks   int
+	StreamUpdateInterval time.Duration
+	// MaxConcurrentStreams bounds the streamed inputs and outputs consumed concurrently in the background, so
+	// thousands of concurrent streams can't explode the number of goroutines. StreamOverflow decides what happens to
+	// the streams beyond it. 0 means no limit.
+	MaxConcurrentStreams int
+	StreamOverflow       StreamOverflowPolicy
+	// CaptureStreamChunks keeps up to CaptureStreamChunks chunks of streamed outputs as timestamped new_token events
+	// of the run, with the content and tool call deltas of each chunk, to debug malformed streaming deltas. The total
+	// number of chunks is reported in the stream_chunks metadata. default: 0, only the first chunk time is reported
+	CaptureStreamChunks int
+
+	// Exporter receives the traced runs instead of the langsmith API, e.g. langsmithtest.Exporter in unit tests.
+	// feedback, datasets and other API requests still go to the langsmith API.
+	Exporter RunExporter
+	// Langfuse additionally writes every run to langfuse, next to langsmith or Exporter. default: disabled
+	Langfuse *LangfuseConfig
+	// DryRun validates and serializes the runs as they would be sent, then drops them: nothing is sent to langsmith
+	// or langfuse. The request bodies are logged at debug level, and the runs handed to Exporter if it's set, e.g.
+	// to check the tracing integration in CI. Runs failing ValidateRun are logged as warnings. The other requests,
+	// e.g. the feedback of Evaluators, AddRunToDataset or the requests of Client.API, are logged the same way and
+	// answered locally: created resources get a generated id, reads find nothing, and urls fail with ErrNotSupported.
+	// AutoCreateProject, VerifyConnection and SpoolDir are ignored.
+	DryRun bool
+
+	// ReferenceExampleOnAllRuns links every run of a trace to the example set by WithReferenceExampleID, instead of the
+	// root run only, as langsmith expects for experiments. Kept for evaluators relying on the former behavior.
+	ReferenceExampleOnAllRuns bool
+
+	// Evaluators score every root run traced by the handler once it ended, in the background, their feedback is
+	// attached to the run. Each evaluator runs within its own ExportTimeout, Flush and Shutdown wait for them. Errors
+	// are logged.
+	Evaluators []RunEvaluator
+
+	// Pricing maps model names to prices used to compute the cost of model runs, entries take precedence over
+	// DefaultModelPrices. Model names are matched by the longest prefix.
+	Pricing map[string]ModelPrice
 }
 
+// HiddenPlaceholder is reported instead of the real payload when Config.HideInputs or Config.HideOutputs is set.
+const HiddenPlaceholder = "[hidden]"
+
+// UnserializablePlaceholder is reported instead of the outputs of a run that Config.Serializer fails to serialize, the
+// run is still ended with the error in its "serialize_error" output.
+const UnserializablePlaceholder = "[unserializable]"
+
 // CallbackHandler implements eino's Handler interface
 type CallbackHandler struct {
-	cli Langsmith
-	cfg *Config
+	cli   Langsmith
+	cfg   *Config
+	spool *spooledLangsmith
+	async *asyncExporter // nil in blocking mode
+	info  *ServerInfo    // set with Config.VerifyConnection
+
+	pending *pendingRuns // runs started but not ended yet, shared by the handlers of a Client
+	// evaluators and dataset examples of ended root runs, shared by the handlers of a Client
+	background *backgroundTasks
+	streams    semaphore // bounds the streams consumed concurrently, shared by the handlers of a Client
+	graphs     sync.Map  // graph name -> *GraphSchema, recorded by OnFinish
 }
 
-// NewLangsmithHandler creates a new CallbackHandler
+// NewLangsmithHandler creates a new CallbackHandler with its own Client, use NewClient to share the connection
+// with a FlowTrace.
 func NewLangsmithHandler(cfg *Config) (*CallbackHandler, error) {
-	// default run id generator
-	if cfg.RunIDGen == nil {
-		cfg.RunIDGen = func(ctx context.Context) string {
-			return uuid.NewString()
+	client, err := NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return client.Handler(), nil
+}
+
+// FlowTrace returns a FlowTrace sharing the client of the handler.
+func (c *CallbackHandler) FlowTrace() *FlowTrace {
+	return &FlowTrace{cli: c.cli, cfg: c.cfg}
+}
+
+// Flush terminates the runs pending for longer than Config.PendingRunTTL, then blocks until the evaluators and
+// AddRunToDataset of the ended root runs are done and all runs queued so far are exported, or ctx is done.
+func (c *CallbackHandler) Flush(ctx context.Context) error {
+	c.reapExpired(ctx)
+	if err := c.background.wait(ctx); err != nil {
+		return err
+	}
+	if c.async == nil {
+		return nil
+	}
+	return c.async.flush(ctx)
+}
+
+// Shutdown terminates the runs still pending with an error tagged TagAbandoned, waits for the evaluators and
+// AddRunToDataset of the ended root runs, exports queued runs and stops background workers of the handler, runs
+// reported afterwards are dropped. Spooled requests are kept on disk.
+func (c *CallbackHandler) Shutdown(ctx context.Context) error {
+	c.reapAll(ctx)
+	err := c.background.wait(ctx)
+	if c.async != nil {
+		if asyncErr := c.async.shutdown(ctx); err == nil {
+			err = asyncErr
 		}
 	}
-	cli := NewLangsmith(cfg.APIKey, cfg.APIURL)
-	return &CallbackHandler{
-		cli: cli,
-		cfg: cfg,
-	}, nil
+	if c.spool != nil {
+		c.spool.close()
+	}
+	return err
+}
+
+func (c *CallbackHandler) createRun(ctx context.Context, run *Run) {
+	if c.async == nil {
+		_ = createRun(ctx, c.cli, c.cfg, run)
+		return
+	}
+	c.async.submit(newCreateTask(ctx, run))
+}
+
+func (c *CallbackHandler) updateRun(ctx context.Context, runID string, patch *RunPatch) {
+	if c.async == nil {
+		_ = updateRun(ctx, c.cli, c.cfg, runID, patch)
+		return
+	}
+	c.async.submit(newUpdateTask(ctx, runID, patch))
+}
+
+// admit reports why a request of op for runID would be dropped by the export queue, its payload is then not
+// serialized. always nil in blocking mode.
+func (c *CallbackHandler) admit(op ExportOp, runID string) error {
+	if c.async == nil {
+		return nil
+	}
+	return c.async.admit(op, runID)
+}
+
+// reject drops the request t refused by admit, it's reported like a request dropped by the queue.
+func (c *CallbackHandler) reject(t *exportTask, err error) {
+	c.cfg.metrics().ExportStarted(t.op)
+	c.async.drop(t, err)
+}
+
+// Needed implements callbacks.TimingChecker, a disabled handler is never called.
+func (c *CallbackHandler) Needed(ctx context.Context, info *callbacks.RunInfo, timing callbacks.CallbackTiming) bool {
+	return !c.cfg.Disabled
 }
 
 // LangsmithState maintains Langsmith call chain state
@@ -67,6 +346,43 @@ type LangsmithState struct {
 	Metadata          *sync.Map              `json:"metadata"`
 	Tags              []string               `json:"tags"`
 	MarshalMetadata   map[string]interface{} `json:"marshal_metadata"`
+
+	startTime   time.Time       // start time of the parent run, used for streaming timings
+	started     time.Time       // monotonic clock reading taken at startTime
+	events      *runEvents      // events added to the parent run by AddRunEvent
+	skipped     bool            // the current component is filtered out by Config.Filter or Config.RootOnly
+	sampledOut  bool            // the trace is dropped by Config.Sampler
+	summary     *runSummary     // aggregates nested components of the root run in Config.RootOnly mode
+	cli         Langsmith       // client of the handler or FlowTrace that created the parent run, used by TraceURL
+	session     string          // project of the parent run
+	annotations *runAnnotations // metadata and tags added to the parent run by UpdateCurrentRunMetadata and AddCurrentRunTag
+	loop        *agentLoop      // iterations of the parent run if it's an agent graph
+	agents      *multiAgent     // agents of the parent run if it's a multi-agent graph
+	host        *multiAgent     // agents the parent run hands off to if it's the host of a multi-agent graph
+	root        *rootRun        // root run of the trace, nil if it's traced by another process
+	trace       *traceScope     // trace options of the parent run, inherited by its children
+	path        string          // node path of the parent run, see MetadataNodePath
+	usage       *usageRollup    // usage of the model runs nested in the parent run
+	timing      *runTiming      // children of the parent run, for Config.LatencyBreakdown
+}
+
+// now returns the current time on the clock of the parent run: its start time plus the monotonic time elapsed since,
+// so durations stay right when the wall clock jumps while the run is in progress.
+func (s *LangsmithState) now() time.Time {
+	if s == nil || s.started.IsZero() {
+		return time.Now().UTC()
+	}
+	return s.startTime.Add(time.Since(s.started))
+}
+
+// parentRunID returns a copy of the parent run id for Run.ParentRunID, nil at the root of a trace. Runs must not point
+// into the state, it's shared by all the sibling runs started concurrently from the same context.
+func (s *LangsmithState) parentRunID() *string {
+	if s.ParentRunID == "" {
+		return nil
+	}
+	id := s.ParentRunID
+	return &id
 }
 
 type langsmithStateKey struct{}
@@ -78,62 +394,78 @@ func (c *CallbackHandler) OnStart(ctx context.Context, info *callbacks.RunInfo,
 	}
 
 	ctx, state := GetOrInitState(ctx)
+	if state.sampledOut {
+		return ctx
+	}
+	if !c.cfg.sampled(ctx, info, state) {
+		return sampleOut(ctx, state)
+	}
+	if state.summary != nil || !c.cfg.Filter.traced(info) {
+		state.summary.addRun()
+		return skipRun(ctx, state)
+	}
+	state = c.agentIteration(ctx, info, state)
 	runID := c.cfg.RunIDGen(ctx)
 
-	opts, _ := ctx.Value(langsmithTraceOptionKey{}).(*traceOptions)
-	if opts == nil {
-		opts = &traceOptions{}
+	scope := c.cfg.traceScope(ctx, state)
+	var (
+		inputs      map[string]interface{}
+		attachments map[string]*Attachment
+	)
+	// the run is still traced when its creation is dropped, so its children and end keep their parent
+	admitErr := c.admit(ExportOpCreate, runID)
+	if admitErr == nil {
+		var err error
+		input, attachments = c.cfg.modelInputAttachments(info, input)
+		inputs, err = c.runInputs(info, input)
+		if err != nil {
+			c.cfg.logger().Error(ctx, "marshal input error", "err", err, "run_info", info)
+			return ctx
+		}
 	}
-	in, err := sonic.MarshalString(input)
-	if err != nil {
-		log.Printf("marshal input error: %v, runinfo: %+v", err, info)
-		return ctx
+	path := nodePath(state, info)
+	var metaData = c.cfg.runExtra(scope, state)
+	setRunMetadata(metaData, MetadataNodePath, path)
+	tags := c.cfg.nodeMetadata(info).apply(metaData, scope.tags)
+	setToolCallID(ctx, info, metaData)
+	host := setAgentMetadata(info, state, metaData)
+	if schema := c.graphSchema(info, state); schema != nil {
+		metaData[ExtraGraphSchema] = schema
 	}
-	var metaData = SafeDeepCopySyncMapMetadata(opts.Metadata)
-	if input != nil {
-		modelConf, _, _, _ := extractModelInput(convModelCallbackInput([]callbacks.CallbackInput{input}))
-		if modelConf != nil {
-			var tmp = metaData["metadata"].(map[string]interface{})
-			tmp["ls_model_name"] = modelConf.Model
-			tmp["ls_max_tokens"] = modelConf.MaxTokens
-			tmp["model_conf"] = modelConf
-			metaData["metadata"] = tmp
-		}
+	// only the config is needed, extractModelInput would concatenate the messages too
+	if in := model.ConvCallbackInput(input); in != nil && in.Config != nil {
+		setModelMetadata(metaData, info, in.Config)
 	}
+	setVectorStoreMetadata(metaData, info, input)
 
+	startTime, started := runStartTime(state.ParentDottedOrder)
+	state.timing.childStarted(startTime)
 	run := &Run{
 		ID:          runID,
 		TraceID:     state.TraceID,
-		Name:        runInfoToName(info),
+		Name:        c.cfg.runName(ctx, info, path),
 		RunType:     runInfoToRunType(info),
-		StartTime:   time.Now().UTC(),
-		Inputs:      map[string]interface{}{"input": in},
-		SessionName: opts.SessionName,
+		StartTime:   startTime,
+		Inputs:      inputs,
+		SessionName: scope.session,
 		Extra:       metaData,
-		Tags:        opts.Tags,
+		Tags:        tags,
+		Attachments: attachments,
 	}
 	if state.TraceID == "" {
 		run.TraceID = runID
 	}
 
-	if opts.ReferenceExampleID != "" {
-		run.ReferenceExampleID = &opts.ReferenceExampleID
-	}
-	if state.ParentRunID != "" {
-		run.ParentRunID = &state.ParentRunID
-	}
-	nowTime := run.StartTime.Format("20060102T150405000000")
-	if state.ParentDottedOrder != "" {
-		run.DottedOrder = fmt.Sprintf("%s.%sZ%s", state.ParentDottedOrder, nowTime, runID)
-	} else {
-		run.DottedOrder = fmt.Sprintf("%sZ%s", nowTime, runID)
-	}
+	run.ReferenceExampleID = c.cfg.referenceExampleID(scope.opts, state)
+	run.ParentRunID = state.parentRunID()
+	run.DottedOrder = dottedOrder(state.ParentDottedOrder, run.StartTime, runID)
 
-	err = c.cli.CreateRun(ctx, run)
-	if err != nil {
-		log.Printf("[langsmith] failed to create run: %v", err)
+	if admitErr != nil {
+		c.reject(newCreateTask(ctx, run), admitErr)
+	} else {
+		c.createRun(ctx, run)
+		c.cfg.logger().Debug(ctx, "run created", "run", run)
 	}
-	fmt.Printf("[langsmith] runinfo: %+v\n", run)
 	var newSyncMap = &sync.Map{}
 	for k, v := range run.Extra {
 		newSyncMap.Store(k, v)
@@ -144,7 +476,24 @@ func (c *CallbackHandler) OnStart(ctx context.Context, info *callbacks.RunInfo,
 		ParentDottedOrder: run.DottedOrder,
 		Metadata:          newSyncMap,
 		Tags:              run.Tags,
+		startTime:         run.StartTime,
+		started:           started,
+		events:            &runEvents{},
+		summary:           c.cfg.newRunSummary(),
+		cli:               c.cli,
+		session:           run.SessionName,
+		annotations:       newRunAnnotations(run.Extra, run.Tags),
+		loop:              c.cfg.newAgentLoop(info),
+		agents:            c.cfg.newMultiAgent(info),
+		host:              host,
+		root:              newRootRun(state, run),
+		trace:             scope,
+		path:              path,
+		usage:             c.cfg.newUsageRollup(state.usage),
+		timing:            c.cfg.newRunTiming(state.timing, startTime),
 	}
+	newState.usage.bindRoot(newState)
+	c.pending.add(newState, state.ParentRunID, isGraphComponent(info.Component))
 	return context.WithValue(ctx, langsmithStateKey{}, newState)
 }
 
@@ -155,28 +504,129 @@ func (c *CallbackHandler) OnEnd(ctx context.Context, info *callbacks.RunInfo, ou
 	}
 	state, ok := ctx.Value(langsmithStateKey{}).(*LangsmithState)
 	if !ok || state == nil {
-		log.Printf("[langsmith] no state in context on OnEnd, runinfo: %+v", info)
+		c.cfg.logger().Warn(ctx, "no state in context on OnEnd, recovered as an orphan run", "run_info", info)
+		c.recoverOrphanEnd(ctx, info, output)
 		return ctx
 	}
-	out, err := sonic.MarshalString(output)
-	if err != nil {
-		log.Printf("marshal output error: %v, runinfo: %+v", err, info)
+	if state.skipped {
+		state.summary.addModelOutputs(c.cfg, info, []callbacks.CallbackOutput{output})
+		state.usage.addSkippedModel(ctx, c.cfg, info, output)
 		return ctx
 	}
+	c.pending.done(state.ParentRunID)
+	var outputs map[string]interface{}
+	admitErr := c.admit(ExportOpUpdate, state.ParentRunID)
+	if admitErr == nil {
+		var err error
+		outputs, err = c.runOutputs(info, output)
+		if err != nil {
+			// the run is ended anyway, it's no longer tracked as pending
+			c.cfg.logger().Error(ctx, "marshal output error", "err", err, "run_info", info)
+			outputs = map[string]interface{}{"output": UnserializablePlaceholder, "serialize_error": err.Error()}
+		}
+	}
+	c.finishAgentLoop(ctx, state, nil)
+	state.host.recordHandoffs(modelToolCalls(info, output))
 
-	endTime := time.Now().UTC()
+	endTime := state.now()
 	patch := &RunPatch{
 		EndTime: &endTime,
-		Outputs: map[string]interface{}{"output": out},
+		Outputs: outputs,
+		Events:  state.events.drain(),
+	}
+	if usage := modelUsage(info, output); usage != nil {
+		extra := SafeDeepCopySyncMapMetadata(state.Metadata)
+		cost, priced := c.cfg.reportUsage(extra, usage)
+		state.usage.addModel(ctx, c.cfg, usage, cost, priced)
+		patch.Extra = extra
+	}
+	if state.summary != nil || state.usage.nested() {
+		if patch.Extra == nil {
+			patch.Extra = SafeDeepCopySyncMapMetadata(state.Metadata)
+		}
+		state.summary.report(patch.Extra)
+		state.usage.report(patch.Extra)
 	}
+	if state.timing.finish(runInfoToRunType(info), state.startTime, endTime) {
+		if patch.Extra == nil {
+			patch.Extra = SafeDeepCopySyncMapMetadata(state.Metadata)
+		}
+		state.timing.report(patch.Extra, endTime.Sub(state.startTime))
+	}
+	state.annotations.apply(patch)
 
-	err = c.cli.UpdateRun(ctx, state.ParentRunID, patch)
-	if err != nil {
-		log.Printf("[langsmith] failed to update run: %v", err)
+	if admitErr != nil {
+		// evaluators and datasets of the root run would miss its outputs
+		c.reject(newUpdateTask(ctx, state.ParentRunID, patch), admitErr)
+		return ctx
 	}
+	c.updateRun(ctx, state.ParentRunID, patch)
+	c.finishRoot(ctx, state, patch)
 	return ctx
 }
 
+// runInputs converts the input of a component into run inputs, known component inputs are reported structurally.
+func (c *CallbackHandler) runInputs(info *callbacks.RunInfo, input callbacks.CallbackInput) (map[string]interface{}, error) {
+	if c.cfg.HideInputs {
+		return map[string]interface{}{"input": HiddenPlaceholder}, nil
+	}
+	if in := promptInput(info, input); in != nil {
+		return map[string]interface{}{
+			"variables": limitPayload(in.Variables, c.cfg.MaxInputBytes),
+			"templates": promptTemplates(in.Templates),
+		}, nil
+	}
+	if messages := multimodalInput(info, input); messages != nil {
+		return map[string]interface{}{"messages": limitPayload(messages, c.cfg.MaxInputBytes)}, nil
+	}
+	if inputs := toolInputs(info, input, c.cfg.MaxInputBytes); inputs != nil {
+		return inputs, nil
+	}
+	if inputs := embeddingInputs(info, input, c.cfg.MaxInputBytes); inputs != nil {
+		return inputs, nil
+	}
+	if inputs := indexerInputs(info, input, c.cfg.MaxInputBytes); inputs != nil {
+		return inputs, nil
+	}
+	in, err := c.cfg.serializer().Serialize(info, input)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"input": truncatePayload(in, c.cfg.MaxInputBytes)}, nil
+}
+
+// runOutputs converts the output of a component into run outputs, known component outputs are reported structurally.
+func (c *CallbackHandler) runOutputs(info *callbacks.RunInfo, output callbacks.CallbackOutput) (map[string]interface{}, error) {
+	if c.cfg.HideOutputs {
+		return map[string]interface{}{"output": HiddenPlaceholder}, nil
+	}
+	if docs := retrieverDocuments(info, output); docs != nil {
+		return map[string]interface{}{"documents": limitPayload(docs, c.cfg.MaxOutputBytes)}, nil
+	}
+	if out := promptOutput(info, output); out != nil {
+		return map[string]interface{}{"messages": limitPayload(out.Result, c.cfg.MaxOutputBytes)}, nil
+	}
+	if outputs := toolOutputs(info, output, c.cfg.MaxOutputBytes); outputs != nil {
+		return outputs, nil
+	}
+	if outputs := embeddingOutputs(info, output, c.cfg.FullEmbeddings, c.cfg.MaxOutputBytes); outputs != nil {
+		return outputs, nil
+	}
+	if outputs := indexerOutputs(info, output, c.cfg.MaxOutputBytes); outputs != nil {
+		return outputs, nil
+	}
+	out, err := c.cfg.serializer().Serialize(info, output)
+	if err != nil {
+		return nil, err
+	}
+	outputs := map[string]interface{}{"output": truncatePayload(out, c.cfg.MaxOutputBytes)}
+	// tool calls are reported structurally, their ids link to the tool_call_id metadata of the tool runs
+	if toolCalls := modelToolCalls(info, output); len(toolCalls) > 0 {
+		outputs["tool_calls"] = toolCalls
+	}
+	return outputs, nil
+}
+
 // OnError handles call failure event
 func (c *CallbackHandler) OnError(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
 	if info == nil {
@@ -184,21 +634,39 @@ func (c *CallbackHandler) OnError(ctx context.Context, info *callbacks.RunInfo,
 	}
 	state, ok := ctx.Value(langsmithStateKey{}).(*LangsmithState)
 	if !ok || state == nil {
-		log.Printf("[langsmith] no state in context on OnError, runinfo: %+v", info)
+		c.cfg.logger().Warn(ctx, "no state in context on OnError, recovered as an orphan run", "run_info", info)
+		c.recoverOrphanError(ctx, info, err)
 		return ctx
 	}
+	if state.skipped {
+		state.summary.addError()
+		return ctx
+	}
+
+	if isPanicError(err) {
+		c.terminateRuns(ctx, c.pending.removeDescendants(state.ParentRunID), err, TagPanic)
+	}
+	c.pending.done(state.ParentRunID)
+	c.finishAgentLoop(ctx, state, err)
 
-	endTime := time.Now().UTC()
-	errStr := err.Error()
+	endTime := state.now()
+	details, errStr := c.cfg.errorDetails(err)
 	patch := &RunPatch{
 		EndTime: &endTime,
 		Error:   &errStr,
+		Events:  state.events.drain(),
+		Extra:   SafeDeepCopySyncMapMetadata(state.Metadata),
 	}
-
-	updateErr := c.cli.UpdateRun(ctx, state.ParentRunID, patch)
-	if updateErr != nil {
-		log.Printf("[langsmith] failed to update run with error: %v", updateErr)
+	patch.Extra[ExtraErrorDetails] = details
+	state.summary.report(patch.Extra)
+	state.usage.report(patch.Extra)
+	if state.timing.finish(runInfoToRunType(info), state.startTime, endTime) {
+		state.timing.report(patch.Extra, endTime.Sub(state.startTime))
 	}
+	state.annotations.apply(patch)
+
+	c.updateRun(ctx, state.ParentRunID, patch)
+	c.finishRoot(ctx, state, patch)
 	return ctx
 }
 
@@ -209,60 +677,78 @@ func (c *CallbackHandler) OnStartWithStreamInput(ctx context.Context, info *call
 		return ctx
 	}
 	ctx, state := GetOrInitState(ctx)
+	if state.sampledOut {
+		input.Close()
+		return ctx
+	}
+	if !c.cfg.sampled(ctx, info, state) {
+		input.Close()
+		return sampleOut(ctx, state)
+	}
+	if state.summary != nil || !c.cfg.Filter.traced(info) {
+		state.summary.addRun()
+		input.Close()
+		return skipRun(ctx, state)
+	}
+	state = c.agentIteration(ctx, info, state)
 	runID := c.cfg.RunIDGen(ctx)
 
-	opts, _ := ctx.Value(langsmithTraceOptionKey{}).(*traceOptions)
-	if opts == nil {
-		opts = &traceOptions{}
-	}
+	scope := c.cfg.traceScope(ctx, state)
+	path := nodePath(state, info)
 
+	startTime, started := runStartTime(state.ParentDottedOrder)
+	state.timing.childStarted(startTime)
 	run := &Run{
 		ID:          runID,
 		TraceID:     state.TraceID,
-		Name:        runInfoToName(info),
+		Name:        c.cfg.runName(ctx, info, path),
 		RunType:     runInfoToRunType(info),
-		StartTime:   time.Now().UTC(),
-		SessionName: opts.SessionName,
-		Tags:        opts.Tags,
+		StartTime:   startTime,
+		SessionName: scope.session,
 	}
 	if state.TraceID == "" {
 		run.TraceID = runID
 	}
-	nowTime := run.StartTime.Format("20060102T150405000000")
-	if state.ParentDottedOrder != "" {
-		run.DottedOrder = fmt.Sprintf("%s.%sZ%s", state.ParentDottedOrder, nowTime, runID)
-	} else {
-		run.DottedOrder = fmt.Sprintf("%sZ%s", nowTime, runID)
+	run.DottedOrder = dottedOrder(state.ParentDottedOrder, run.StartTime, runID)
+	var metaData = c.cfg.runExtra(scope, state)
+	setRunMetadata(metaData, MetadataNodePath, path)
+	run.Tags = c.cfg.nodeMetadata(info).apply(metaData, scope.tags)
+	setToolCallID(ctx, info, metaData)
+	host := setAgentMetadata(info, state, metaData)
+	if schema := c.graphSchema(info, state); schema != nil {
+		metaData[ExtraGraphSchema] = schema
 	}
-	var metaData = SafeDeepCopySyncMapMetadata(opts.Metadata)
 	var newSyncMap = &sync.Map{}
 	for k, v := range metaData {
 		newSyncMap.Store(k, v)
 	}
-	// start goroutine to handle stream input
-	go func() {
+	// run is completed and created by the goroutine, the state must not read it
+	annotations := newRunAnnotations(nil, run.Tags)
+	root := newRootRun(state, nil)
+	// consume the stream input in the background, a dropped stream is closed unread
+	c.consumeStream(ctx, info, func(dropped bool) {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("[langsmith] recovered in OnStartWithStreamInput: %v\n%s", r, debug.Stack())
+				c.cfg.logger().Error(ctx, "recovered in OnStartWithStreamInput", "panic", r, "stack", string(debug.Stack()))
 			}
 			input.Close()
 		}()
 
 		var inputs []callbacks.CallbackInput
-		for {
+		for !dropped {
 			chunk, err := input.Recv()
 			if err == io.EOF {
 				break
 			}
 			if err != nil {
-				log.Printf("[langsmith] error receiving stream input: %v", err)
+				c.cfg.logger().Error(ctx, "error receiving stream input", "err", err)
 				break
 			}
 			inputs = append(inputs, chunk)
 		}
 		modelConf, inMessage, extra, err_ := extractModelInput(convModelCallbackInput(inputs))
 		if err_ != nil {
-			log.Printf("extract stream model input error: %v, runinfo: %+v", err_, info)
+			c.cfg.logger().Error(ctx, "extract stream model input error", "err", err_, "run_info", info)
 			return
 		}
 
@@ -272,28 +758,35 @@ func (c *CallbackHandler) OnStartWithStreamInput(ctx context.Context, info *call
 			}
 		}
 		if modelConf != nil {
-			var tmp = metaData["metadata"].(map[string]interface{})
-			tmp["ls_model_name"] = modelConf.Model
-			tmp["ls_max_tokens"] = modelConf.MaxTokens
-			tmp["model_conf"] = modelConf
-			metaData["metadata"] = tmp
-			newSyncMap.Store("metadata", tmp)
+			setModelMetadata(metaData, info, modelConf)
+			newSyncMap.Store("metadata", metaData["metadata"])
+			newSyncMap.Store("invocation_params", metaData["invocation_params"])
 		}
 
-		if opts.ReferenceExampleID != "" {
-			run.ReferenceExampleID = &opts.ReferenceExampleID
-		}
-		if state.ParentRunID != "" {
-			run.ParentRunID = &state.ParentRunID
-		}
+		run.ReferenceExampleID = c.cfg.referenceExampleID(scope.opts, state)
+		run.ParentRunID = state.parentRunID()
 
-		run.Inputs = map[string]interface{}{"stream_inputs": inMessage}
+		if dropped {
+			run.Inputs = map[string]interface{}{"stream_inputs": StreamDroppedPlaceholder}
+		} else if c.cfg.HideInputs {
+			run.Inputs = map[string]interface{}{"stream_inputs": HiddenPlaceholder}
+		} else {
+			if !c.cfg.inlineMedia() {
+				inMessage, run.Attachments = messageAttachments(inMessage)
+			}
+			if messages := multimodalMessages(inMessage); messages != nil {
+				run.Inputs = map[string]interface{}{"messages": limitPayload(messages, c.cfg.MaxInputBytes)}
+			} else {
+				run.Inputs = map[string]interface{}{"stream_inputs": limitPayload(inMessage, c.cfg.MaxInputBytes)}
+			}
+		}
 		run.Extra = metaData
-		err := c.cli.CreateRun(ctx, run)
-		if err != nil {
-			log.Printf("[langsmith] failed to create run for stream: %v", err)
+		annotations.setExtra(metaData)
+		if state.ParentRunID == "" {
+			root.set(run)
 		}
-	}()
+		c.createRun(ctx, run)
+	})
 
 	newState := &LangsmithState{
 		TraceID:           run.TraceID,
@@ -301,7 +794,24 @@ func (c *CallbackHandler) OnStartWithStreamInput(ctx context.Context, info *call
 		ParentDottedOrder: run.DottedOrder,
 		Metadata:          newSyncMap,
 		Tags:              run.Tags,
+		startTime:         run.StartTime,
+		started:           started,
+		events:            &runEvents{},
+		summary:           c.cfg.newRunSummary(),
+		cli:               c.cli,
+		session:           run.SessionName,
+		annotations:       annotations,
+		loop:              c.cfg.newAgentLoop(info),
+		agents:            c.cfg.newMultiAgent(info),
+		host:              host,
+		root:              root,
+		trace:             scope,
+		path:              path,
+		usage:             c.cfg.newUsageRollup(state.usage),
+		timing:            c.cfg.newRunTiming(state.timing, startTime),
 	}
+	newState.usage.bindRoot(newState)
+	c.pending.add(newState, state.ParentRunID, isGraphComponent(info.Component))
 	return context.WithValue(ctx, langsmithStateKey{}, newState)
 }
 
@@ -313,64 +823,114 @@ func (c *CallbackHandler) OnEndWithStreamOutput(ctx context.Context, info *callb
 	}
 	state, ok := ctx.Value(langsmithStateKey{}).(*LangsmithState)
 	if !ok || state == nil {
-		log.Printf("[langsmith] no state in context on OnEndWithStreamOutput, runinfo: %+v", info)
-		output.Close()
+		c.cfg.logger().Warn(ctx, "no state in context on OnEndWithStreamOutput, recovered as an orphan run", "run_info", info)
+		c.recoverOrphanStream(ctx, info, output)
 		return ctx
 	}
+	if state.skipped {
+		if state.summary == nil || info.Component != components.ComponentOfChatModel {
+			output.Close()
+			return ctx
+		}
+		c.consumeStream(ctx, info, func(dropped bool) {
+			c.summarizeStream(ctx, info, state.summary, output, dropped)
+		})
+		return ctx
+	}
+	c.pending.done(state.ParentRunID)
 	var metaData = SafeDeepCopySyncMapMetadata(state.Metadata)
-	go func() {
+	streamStart := state.now()
+	runStart := state.startTime
+	if runStart.IsZero() {
+		runStart = streamStart
+	}
+	c.consumeStream(ctx, info, func(dropped bool) {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("[langsmith] recovered in OnEndWithStreamOutput: %v\n%s", r, debug.Stack())
+				c.cfg.logger().Error(ctx, "recovered in OnEndWithStreamOutput", "panic", r, "stack", string(debug.Stack()))
 			}
 			output.Close()
 		}()
 
-		var outputs []callbacks.CallbackOutput
-		for {
+		outputs := make([]callbacks.CallbackOutput, 0, streamChunksHint)
+		var firstChunkTime time.Time
+		partial := c.newPartialUpdater()
+		capture := c.newChunkCapture(info)
+		for !dropped {
 			chunk, err := output.Recv()
 			if err == io.EOF {
 				break
 			}
 			if err != nil {
-				log.Printf("[langsmith] error receiving stream output: %v", err)
+				c.cfg.logger().Error(ctx, "error receiving stream output", "err", err)
 				break
 			}
+			now := state.now()
+			if firstChunkTime.IsZero() {
+				firstChunkTime = now
+			}
+			capture.add(chunk, now)
 			outputs = append(outputs, chunk)
+			if partial.due() {
+				c.updatePartialOutput(ctx, info, state.ParentRunID, partial, outputs)
+			}
 		}
+		c.finishAgentLoop(ctx, state, nil)
 		usage, outMessage, extra, err_ := extractModelOutput(convModelCallbackOutput(outputs))
 		if err_ != nil {
-			log.Printf("extract stream model output error: %v, runinfo: %+v", err_, info)
+			c.cfg.logger().Error(ctx, "extract stream model output error", "err", err_, "run_info", info)
 			return
 		}
+		if outMessage != nil {
+			state.host.recordHandoffs(outMessage.ToolCalls)
+		}
 		if extra != nil {
 			for k, v := range extra {
 				metaData[k] = v
 			}
 		}
 		if usage != nil {
-			var tmp = metaData["metadata"].(map[string]interface{})
-			var langsmithUsage = map[string]int{
-				"input_tokens":  usage.PromptTokens,
-				"output_tokens": usage.CompletionTokens,
-				"total_tokens":  usage.TotalTokens,
+			cost, priced := c.cfg.reportUsage(metaData, usage)
+			state.usage.addModel(ctx, c.cfg, usage, cost, priced)
+		}
+		state.summary.report(metaData)
+		state.usage.report(metaData)
+		endTime := state.now()
+		if state.timing.finish(runInfoToRunType(info), state.startTime, endTime) {
+			state.timing.report(metaData, endTime.Sub(state.startTime))
+		}
+		var events []RunEvent
+		var tmp = metaData["metadata"].(map[string]interface{})
+		tmp["streaming_duration"] = endTime.Sub(streamStart).Seconds()
+		if !firstChunkTime.IsZero() {
+			tmp["time_to_first_token"] = firstChunkTime.Sub(runStart).Seconds()
+			// langsmith derives the first token time of the run from the first new_token event
+			if capture == nil {
+				events = append(events, RunEvent{Name: RunEventNewToken, Time: firstChunkTime})
 			}
-			tmp["usage_metadata"] = langsmithUsage
-			metaData["metadata"] = tmp
 		}
-		endTime := time.Now().UTC()
+		if capture != nil {
+			tmp["stream_chunks"] = len(outputs)
+			events = append(events, capture.events...)
+		}
+		events = append(events, state.events.drain()...)
+		metaData["metadata"] = tmp
 		patch := &RunPatch{
 			EndTime: &endTime,
-			Outputs: map[string]interface{}{"stream_outputs": outMessage},
+			Outputs: map[string]interface{}{"stream_outputs": limitPayload(outMessage, c.cfg.MaxOutputBytes)},
 			Extra:   metaData,
+			Events:  events,
 		}
-
-		// 使用后台 context
-		err := c.cli.UpdateRun(context.Background(), state.ParentRunID, patch)
-		if err != nil {
-			log.Printf("[langsmith] failed to update run with stream output: %v", err)
+		if dropped {
+			patch.Outputs = map[string]interface{}{"stream_outputs": StreamDroppedPlaceholder}
+		} else if c.cfg.HideOutputs {
+			patch.Outputs = map[string]interface{}{"stream_outputs": HiddenPlaceholder}
 		}
-	}()
+		state.annotations.apply(patch)
+
+		c.updateRun(ctx, state.ParentRunID, patch)
+		c.finishRoot(ctx, state, patch)
+	})
 
 	return ctx
 }
@@ -19,13 +19,20 @@ package langsmith
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // mockLangsmith 实现 Langsmith 接口，用于注入可控行为
@@ -43,6 +50,119 @@ func (m *mockLangsmith) UpdateRun(ctx context.Context, runID string, patch *RunP
 	return args.Error(0)
 }
 
+func (m *mockLangsmith) CreateFeedback(ctx context.Context, feedback *Feedback) (*Feedback, error) {
+	args := m.Called(ctx, feedback)
+	fb, _ := args.Get(0).(*Feedback)
+	return fb, args.Error(1)
+}
+
+func (m *mockLangsmith) UpdateFeedback(ctx context.Context, feedbackID string, update *FeedbackUpdate) error {
+	args := m.Called(ctx, feedbackID, update)
+	return args.Error(0)
+}
+
+func (m *mockLangsmith) DeleteFeedback(ctx context.Context, feedbackID string) error {
+	args := m.Called(ctx, feedbackID)
+	return args.Error(0)
+}
+
+func (m *mockLangsmith) CreateFeedbackToken(ctx context.Context, runID, feedbackKey string, opts *FeedbackTokenOptions) (*FeedbackToken, error) {
+	args := m.Called(ctx, runID, feedbackKey, opts)
+	token, _ := args.Get(0).(*FeedbackToken)
+	return token, args.Error(1)
+}
+
+func (m *mockLangsmith) CreateDataset(ctx context.Context, dataset *Dataset) (*Dataset, error) {
+	args := m.Called(ctx, dataset)
+	ds, _ := args.Get(0).(*Dataset)
+	return ds, args.Error(1)
+}
+
+func (m *mockLangsmith) ReadDataset(ctx context.Context, name string) (*Dataset, error) {
+	args := m.Called(ctx, name)
+	ds, _ := args.Get(0).(*Dataset)
+	return ds, args.Error(1)
+}
+
+func (m *mockLangsmith) CreateExample(ctx context.Context, example *Example) (*Example, error) {
+	args := m.Called(ctx, example)
+	ex, _ := args.Get(0).(*Example)
+	return ex, args.Error(1)
+}
+
+func (m *mockLangsmith) ListExamples(ctx context.Context, opts *ListExamplesOptions) ([]*Example, error) {
+	args := m.Called(ctx, opts)
+	exs, _ := args.Get(0).([]*Example)
+	return exs, args.Error(1)
+}
+
+func (m *mockLangsmith) UpdateExample(ctx context.Context, exampleID string, update *ExampleUpdate) error {
+	args := m.Called(ctx, exampleID, update)
+	return args.Error(0)
+}
+
+func (m *mockLangsmith) PullPrompt(ctx context.Context, identifier string) (*PromptCommit, error) {
+	args := m.Called(ctx, identifier)
+	pc, _ := args.Get(0).(*PromptCommit)
+	return pc, args.Error(1)
+}
+
+func (m *mockLangsmith) PushPrompt(ctx context.Context, identifier string, manifest map[string]interface{}, opts *PushPromptOptions) (string, error) {
+	args := m.Called(ctx, identifier, manifest, opts)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockLangsmith) CreateProject(ctx context.Context, project *Project) (*Project, error) {
+	args := m.Called(ctx, project)
+	p, _ := args.Get(0).(*Project)
+	return p, args.Error(1)
+}
+
+func (m *mockLangsmith) ReadProject(ctx context.Context, name string) (*Project, error) {
+	args := m.Called(ctx, name)
+	p, _ := args.Get(0).(*Project)
+	return p, args.Error(1)
+}
+
+func (m *mockLangsmith) ListProjects(ctx context.Context, opts *ListProjectsOptions) ([]*Project, error) {
+	args := m.Called(ctx, opts)
+	ps, _ := args.Get(0).([]*Project)
+	return ps, args.Error(1)
+}
+
+func (m *mockLangsmith) ReadRun(ctx context.Context, runID string) (*Run, error) {
+	args := m.Called(ctx, runID)
+	r, _ := args.Get(0).(*Run)
+	return r, args.Error(1)
+}
+
+func (m *mockLangsmith) ListRuns(ctx context.Context, opts *ListRunsOptions) (*RunsPage, error) {
+	args := m.Called(ctx, opts)
+	p, _ := args.Get(0).(*RunsPage)
+	return p, args.Error(1)
+}
+
+func (m *mockLangsmith) ShareRun(ctx context.Context, runID string) (string, error) {
+	args := m.Called(ctx, runID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockLangsmith) UnshareRun(ctx context.Context, runID string) error {
+	args := m.Called(ctx, runID)
+	return args.Error(0)
+}
+
+func (m *mockLangsmith) GetRunURL(ctx context.Context, projectName, runID, traceID string) (string, error) {
+	args := m.Called(ctx, projectName, runID, traceID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockLangsmith) Info(ctx context.Context) (*ServerInfo, error) {
+	args := m.Called(ctx)
+	info, _ := args.Get(0).(*ServerInfo)
+	return info, args.Error(1)
+}
+
 // TestNewLangsmithHandler 测试构造函数
 func TestNewLangsmithHandler(t *testing.T) {
 	cfg := &Config{APIKey: "test-key", APIURL: "http://test"}
@@ -108,7 +228,7 @@ func TestOnError(t *testing.T) {
 // TestOnStartWithStreamInput 测试流式输入
 func TestOnStartWithStreamInput(t *testing.T) {
 	mCli := new(mockLangsmith)
-	cfg := &Config{APIKey: "test-key", APIURL: "http://test"}
+	cfg := &Config{APIKey: "test-key", APIURL: "http://test", Exporter: mCli}
 	h, _ := NewLangsmithHandler(cfg)
 
 	ctx := context.Background()
@@ -119,20 +239,22 @@ func TestOnStartWithStreamInput(t *testing.T) {
 	sw.Close() // 立即关闭，模拟无数据流
 
 	// 期望 CreateRun 被调用一次
-	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	created := make(chan struct{})
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		close(created)
+	}).Return(nil).Once()
 
 	newCtx := h.OnStartWithStreamInput(ctx, info, sr)
 	assert.NotNil(t, newCtx)
 
 	// 等待 goroutine 完成
-	time.Sleep(100 * time.Millisecond)
-
+	<-created
 }
 
 // TestOnEndWithStreamOutput 测试流式输出
 func TestOnEndWithStreamOutput(t *testing.T) {
 	mCli := new(mockLangsmith)
-	cfg := &Config{APIKey: "test-key", APIURL: "http://test"}
+	cfg := &Config{APIKey: "test-key", APIURL: "http://test", Exporter: mCli}
 	h, _ := NewLangsmithHandler(cfg)
 
 	ctx := context.WithValue(context.Background(), langsmithStateKey{}, &LangsmithState{
@@ -144,11 +266,199 @@ func TestOnEndWithStreamOutput(t *testing.T) {
 	sr, sw := schema.Pipe[callbacks.CallbackOutput](1)
 	sw.Close() // 立即关闭，模拟无数据流
 
-	mCli.On("UpdateRun", mock.Anything, "run-123", mock.Anything).Return(nil)
+	updated := make(chan struct{})
+	mCli.On("UpdateRun", mock.Anything, "run-123", mock.Anything).Run(func(args mock.Arguments) {
+		close(updated)
+	}).Return(nil).Once()
 
 	newCtx := h.OnEndWithStreamOutput(ctx, info, sr)
 	assert.NotNil(t, newCtx)
 
 	// 等待 goroutine 完成
-	time.Sleep(100 * time.Millisecond)
+	<-updated
+}
+
+// TestStreamTimings 测试流式输出的首 token 耗时与流式耗时
+func TestStreamTimings(t *testing.T) {
+	mCli := new(mockLangsmith)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{}}
+	patched := make(chan *RunPatch, 1)
+	mCli.On("UpdateRun", mock.Anything, "run-123", mock.Anything).Run(func(args mock.Arguments) {
+		patched <- args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	runStart := time.Now().UTC().Add(-time.Second)
+	ctx := context.WithValue(context.Background(), langsmithStateKey{}, &LangsmithState{
+		ParentRunID: "run-123",
+		startTime:   runStart,
+	})
+	sr, sw := schema.Pipe[callbacks.CallbackOutput](2)
+	h.OnEndWithStreamOutput(ctx, &callbacks.RunInfo{Component: "test"}, sr)
+	time.Sleep(10 * time.Millisecond)
+	sw.Send(callbacks.CallbackOutput("a"), nil)
+	sw.Send(callbacks.CallbackOutput("b"), nil)
+	sw.Close()
+
+	patch := <-patched
+	metadata := patch.Extra["metadata"].(map[string]interface{})
+	assert.GreaterOrEqual(t, metadata["time_to_first_token"].(float64), 1.0)
+	assert.GreaterOrEqual(t, metadata["streaming_duration"].(float64), 0.01)
+	require.Len(t, patch.Events, 1)
+	assert.Equal(t, RunEventNewToken, patch.Events[0].Name)
+	assert.True(t, patch.Events[0].Time.After(runStart))
+}
+
+// TestHideInputsOutputs 测试 HideInputs / HideOutputs 脱敏
+func TestHideInputsOutputs(t *testing.T) {
+	mCli := new(mockLangsmith)
+	h := &CallbackHandler{
+		cli: mCli,
+		cfg: &Config{
+			HideInputs:  true,
+			HideOutputs: true,
+			RunIDGen: func(ctx context.Context) string {
+				return "run-123"
+			},
+		},
+	}
+	info := &callbacks.RunInfo{Component: "test"}
+
+	var createdRun *Run
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		createdRun = args.Get(1).(*Run)
+	}).Return(nil)
+	var patched *RunPatch
+	mCli.On("UpdateRun", mock.Anything, "run-123", mock.Anything).Run(func(args mock.Arguments) {
+		patched = args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	ctx := h.OnStart(context.Background(), info, callbacks.CallbackInput("secret input"))
+	h.OnEnd(ctx, info, callbacks.CallbackOutput("secret output"))
+
+	assert.Equal(t, HiddenPlaceholder, createdRun.Inputs["input"])
+	assert.Equal(t, HiddenPlaceholder, patched.Outputs["output"])
+	assert.NotNil(t, patched.EndTime)
+}
+
+// TestParallelBranches 测试并发分支共享父 context 时各自的 run 层级正确，且不互相写入状态
+func TestParallelBranches(t *testing.T) {
+	mCli := &mockLangsmith{}
+	h := &CallbackHandler{cli: mCli, cfg: &Config{RunIDGen: DefaultRunIDGen}}
+	var mu sync.Mutex
+	runs := map[string]*Run{}
+	patches := map[string]*RunPatch{}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		run := args.Get(1).(*Run)
+		runs[run.Name] = run
+	}).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		patches[args.String(1)] = args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	const branches = 8
+	g := compose.NewGraph[string, map[string]any]()
+	for i := 0; i < branches; i++ {
+		key := fmt.Sprintf("branch_%d", i)
+		require.NoError(t, g.AddLambdaNode(key, compose.InvokableLambda(func(ctx context.Context, in string) (map[string]any, error) {
+			if err := UpdateCurrentRunMetadata(ctx, map[string]interface{}{"branch": key}); err != nil {
+				return nil, err
+			}
+			if err := AddRunEvent(ctx, "visited", map[string]interface{}{"branch": key}); err != nil {
+				return nil, err
+			}
+			_, state := GetState(ctx)
+			spanCtx, spanID, err := h.FlowTrace().StartSpan(ctx, key+"_span", state)
+			if err != nil {
+				return nil, err
+			}
+			if _, err = h.FlowTrace().SpanToString(spanCtx); err != nil {
+				return nil, err
+			}
+			h.FlowTrace().FinishSpan(spanCtx, spanID)
+			return map[string]any{key: in}, nil
+		}), compose.WithNodeName(key)))
+		require.NoError(t, g.AddEdge(compose.START, key))
+		require.NoError(t, g.AddEdge(key, compose.END))
+	}
+	r, err := g.Compile(context.Background(), compose.WithGraphName("fan_out"))
+	require.NoError(t, err)
+	out, err := r.Invoke(context.Background(), "in", compose.WithCallbacks(h))
+	require.NoError(t, err)
+	require.Len(t, out, branches)
+
+	root := runs["fan_out"]
+	require.NotNil(t, root)
+	parents := map[*string]bool{}
+	for i := 0; i < branches; i++ {
+		key := fmt.Sprintf("branch_%d", i)
+		branch, span := runs[key], runs[key+"_span"]
+		require.NotNil(t, branch, key)
+		require.NotNil(t, span, key)
+		assert.Equal(t, root.ID, branch.TraceID)
+		assert.Equal(t, root.ID, *branch.ParentRunID)
+		assert.False(t, parents[branch.ParentRunID], "sibling runs share the parent run id")
+		parents[branch.ParentRunID] = true
+		assert.Equal(t, root.ID, span.TraceID)
+		assert.Equal(t, branch.ID, *span.ParentRunID)
+		assert.True(t, strings.HasPrefix(span.DottedOrder, branch.DottedOrder+"."))
+
+		patch := patches[branch.ID]
+		require.NotNil(t, patch, key)
+		assert.Equal(t, key, patch.Extra["metadata"].(map[string]interface{})["branch"])
+		require.Len(t, patch.Events, 1)
+		assert.Equal(t, key, patch.Events[0].Kwargs["branch"])
+	}
+	assert.Nil(t, patches[root.ID].Events)
+}
+
+// nopLangsmith 丢弃所有 run，用于基准测试，避免 mock 的开销
+type nopLangsmith struct {
+	Langsmith
+	patches chan *RunPatch
+}
+
+func (n *nopLangsmith) CreateRun(ctx context.Context, run *Run) error { return nil }
+
+func (n *nopLangsmith) UpdateRun(ctx context.Context, runID string, patch *RunPatch) error {
+	if n.patches != nil {
+		n.patches <- patch
+	}
+	return nil
+}
+
+func BenchmarkOnStart(b *testing.B) {
+	h := &CallbackHandler{cli: &nopLangsmith{}, cfg: &Config{RunIDGen: DefaultRunIDGen}}
+	info := &callbacks.RunInfo{Name: "model", Type: "OpenAI", Component: components.ComponentOfChatModel}
+	input := &model.CallbackInput{Messages: []*schema.Message{schema.UserMessage("hello")}}
+	ctx, _ := GetOrInitState(SetTrace(context.Background(), WithSessionName("bench")))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runCtx := h.OnStart(ctx, info, input)
+		h.OnEnd(runCtx, info, &model.CallbackOutput{Message: schema.AssistantMessage("hi", nil)})
+	}
+}
+
+func BenchmarkStreamEnd(b *testing.B) {
+	cli := &nopLangsmith{patches: make(chan *RunPatch, 1)}
+	h := &CallbackHandler{cli: cli, cfg: &Config{RunIDGen: DefaultRunIDGen}}
+	info := &callbacks.RunInfo{Name: "model", Type: "OpenAI", Component: components.ComponentOfChatModel}
+	input := &model.CallbackInput{Messages: []*schema.Message{schema.UserMessage("hello")}}
+	ctx, _ := GetOrInitState(context.Background())
+	ctx = h.OnStart(ctx, info, input)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sr, sw := schema.Pipe[callbacks.CallbackOutput](8)
+		for _, s := range []string{"a", "b", "c", "d"} {
+			sw.Send(&model.CallbackOutput{Message: schema.AssistantMessage(s, nil)}, nil)
+		}
+		sw.Close()
+		h.OnEndWithStreamOutput(ctx, info, sr)
+		<-cli.patches
+	}
 }
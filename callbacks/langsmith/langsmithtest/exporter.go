@@ -0,0 +1,239 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package langsmithtest provides an in-memory exporter to assert on the runs traced by the langsmith handler in tests.
+package langsmithtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino-ext/callbacks/langsmith"
+)
+
+// Exporter is an in-memory langsmith.RunExporter recording every run and patch, set it as langsmith.Config.Exporter.
+type Exporter struct {
+	mu      sync.Mutex
+	changed chan struct{} // closed and replaced on every recorded request
+	runs    map[string]*langsmith.Run
+	order   []string
+	patches map[string][]*langsmith.RunPatch
+}
+
+// NewExporter creates an empty Exporter
+func NewExporter() *Exporter {
+	return &Exporter{
+		changed: make(chan struct{}),
+		runs:    map[string]*langsmith.Run{},
+		patches: map[string][]*langsmith.RunPatch{},
+	}
+}
+
+// CreateRun implements langsmith.RunExporter
+func (e *Exporter) CreateRun(ctx context.Context, run *langsmith.Run) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	cp := *run
+	if _, ok := e.runs[run.ID]; !ok {
+		e.order = append(e.order, run.ID)
+	}
+	e.runs[run.ID] = &cp
+	e.notifyLocked()
+	return nil
+}
+
+// UpdateRun implements langsmith.RunExporter, the patch is merged into the recorded run.
+func (e *Exporter) UpdateRun(ctx context.Context, runID string, patch *langsmith.RunPatch) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.patches[runID] = append(e.patches[runID], patch)
+	run, ok := e.runs[runID]
+	if !ok {
+		// the update may be exported before the create of a streamed run
+		run = &langsmith.Run{ID: runID}
+		e.runs[runID] = run
+		e.order = append(e.order, runID)
+	}
+	if patch.EndTime != nil {
+		run.EndTime = patch.EndTime
+	}
+	if patch.Inputs != nil {
+		run.Inputs = patch.Inputs
+	}
+	if patch.Outputs != nil {
+		run.Outputs = patch.Outputs
+	}
+	if patch.Error != nil {
+		run.Error = patch.Error
+	}
+	if patch.Extra != nil {
+		run.Extra = patch.Extra
+	}
+	if patch.Tags != nil {
+		run.Tags = patch.Tags
+	}
+	run.Events = append(run.Events, patch.Events...)
+	e.notifyLocked()
+	return nil
+}
+
+func (e *Exporter) notifyLocked() {
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+// Runs returns copies of the recorded runs with their patches applied, in creation order.
+func (e *Exporter) Runs() []*langsmith.Run {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	runs := make([]*langsmith.Run, 0, len(e.order))
+	for _, id := range e.order {
+		cp := *e.runs[id]
+		runs = append(runs, &cp)
+	}
+	return runs
+}
+
+// Patches returns the patches received for a run, in order.
+func (e *Exporter) Patches(runID string) []*langsmith.RunPatch {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*langsmith.RunPatch(nil), e.patches[runID]...)
+}
+
+// FindRunByName returns the first run named name, nil if there is none.
+func (e *Exporter) FindRunByName(name string) *langsmith.Run {
+	for _, run := range e.Runs() {
+		if run.Name == name {
+			return run
+		}
+	}
+	return nil
+}
+
+// Reset drops all recorded runs.
+func (e *Exporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.runs = map[string]*langsmith.Run{}
+	e.order = nil
+	e.patches = map[string][]*langsmith.RunPatch{}
+}
+
+// WaitForRuns blocks until at least n runs are finished, i.e. have an end time, or timeout elapses.
+// runs are exported asynchronously, use it instead of sleeping before assertions.
+func (e *Exporter) WaitForRuns(n int, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		e.mu.Lock()
+		finished := 0
+		for _, run := range e.runs {
+			if run.EndTime != nil {
+				finished++
+			}
+		}
+		changed := e.changed
+		e.mu.Unlock()
+		if finished >= n {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return fmt.Errorf("timeout waiting for %d finished runs, got %d", n, finished)
+		}
+	}
+}
+
+// RunTree renders the recorded runs as a tree of names, children are indented by two spaces and sorted by start time.
+func (e *Exporter) RunTree() string {
+	runs := e.Runs()
+	children := map[string][]*langsmith.Run{}
+	known := map[string]bool{}
+	for _, run := range runs {
+		known[run.ID] = true
+	}
+	var roots []*langsmith.Run
+	for _, run := range runs {
+		if run.ParentRunID != nil && known[*run.ParentRunID] {
+			children[*run.ParentRunID] = append(children[*run.ParentRunID], run)
+		} else {
+			roots = append(roots, run)
+		}
+	}
+	sb := &strings.Builder{}
+	var render func(runs []*langsmith.Run, depth int)
+	render = func(runs []*langsmith.Run, depth int) {
+		sort.SliceStable(runs, func(i, j int) bool { return runs[i].StartTime.Before(runs[j].StartTime) })
+		for _, run := range runs {
+			sb.WriteString(strings.Repeat("  ", depth))
+			sb.WriteString(run.Name)
+			sb.WriteString("\n")
+			render(children[run.ID], depth+1)
+		}
+	}
+	render(roots, 0)
+	return sb.String()
+}
+
+// TestingT is the subset of testing.TB used by the assertion helpers.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertRunTree asserts that RunTree equals expected, the common indentation and surrounding blank lines of expected
+// are ignored, so it can be written as an indented raw string literal.
+func AssertRunTree(t TestingT, e *Exporter, expected string) bool {
+	t.Helper()
+	want := normalizeTree(expected)
+	got := e.RunTree()
+	if got != want {
+		t.Errorf("unexpected run tree:\nwant:\n%s\ngot:\n%s", want, got)
+		return false
+	}
+	return true
+}
+
+func normalizeTree(tree string) string {
+	var lines []string
+	for _, line := range strings.Split(tree, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimRight(line, " \t"))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	indent := -1
+	for _, line := range lines {
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	sb := &strings.Builder{}
+	for _, line := range lines {
+		sb.WriteString(strings.ReplaceAll(line[indent:], "\t", "  "))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmithtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudwego/eino-ext/callbacks/langsmith"
+)
+
+func TestExporter(t *testing.T) {
+	exp := NewExporter()
+	h, err := langsmith.NewLangsmithHandler(&langsmith.Config{Exporter: exp})
+	require.NoError(t, err)
+
+	chain := compose.NewChain[map[string]any, string]().
+		AppendChatTemplate(prompt.FromMessages(schema.FString, schema.UserMessage("hello {name}")), compose.WithNodeName("greeting")).
+		AppendLambda(compose.InvokableLambda(func(ctx context.Context, msgs []*schema.Message) (string, error) {
+			return msgs[0].Content, nil
+		}), compose.WithNodeName("first"))
+	r, err := chain.Compile(context.Background(), compose.WithGraphName("greeter"))
+	require.NoError(t, err)
+
+	out, err := r.Invoke(context.Background(), map[string]any{"name": "eino"}, compose.WithCallbacks(h))
+	require.NoError(t, err)
+	assert.Equal(t, "hello eino", out)
+
+	require.NoError(t, exp.WaitForRuns(3, time.Second))
+	AssertRunTree(t, exp, `
+		greeter
+		  greeting
+		  first
+	`)
+
+	run := exp.FindRunByName("greeting")
+	require.NotNil(t, run)
+	assert.Equal(t, langsmith.RunTypePrompt, run.RunType)
+	assert.NotNil(t, run.EndTime)
+	assert.Len(t, exp.Patches(run.ID), 1)
+	assert.Nil(t, exp.FindRunByName("missing"))
+
+	assert.Error(t, exp.WaitForRuns(4, 10*time.Millisecond))
+	exp.Reset()
+	assert.Empty(t, exp.Runs())
+}
+
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, format)
+}
+
+func TestAssertRunTreeMismatch(t *testing.T) {
+	exp := NewExporter()
+	parent := "root-id"
+	now := time.Now()
+	_ = exp.CreateRun(context.Background(), &langsmith.Run{ID: parent, Name: "root", StartTime: now})
+	_ = exp.CreateRun(context.Background(), &langsmith.Run{ID: "child-id", Name: "child", ParentRunID: &parent, StartTime: now.Add(time.Millisecond)})
+	assert.Equal(t, "root\n  child\n", exp.RunTree())
+
+	rt := &recordingT{}
+	assert.False(t, AssertRunTree(rt, exp, "root"))
+	assert.Len(t, rt.errors, 1)
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmithtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/eino/compose"
+
+	"github.com/cloudwego/eino-ext/callbacks/langsmith"
+)
+
+// UpdateGoldenEnv is the environment variable making AssertGolden write the golden files instead of comparing them
+// when it's not empty, e.g. LANGSMITH_UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "LANGSMITH_UPDATE_GOLDEN"
+
+// GoldenRun is a run of a golden run tree, it only keeps the fields stable across executions. Parentage is given by
+// the nesting of Children, and the ids and start times of DottedOrder are replaced by the position of the run and of
+// its ancestors among their siblings, e.g. 1.2 is the second child of the first root run.
+type GoldenRun struct {
+	Name        string            `json:"name"`
+	RunType     langsmith.RunType `json:"run_type"`
+	DottedOrder string            `json:"dotted_order"`
+	Error       bool              `json:"error,omitempty"`
+	Unfinished  bool              `json:"unfinished,omitempty"`
+	Children    []*GoldenRun      `json:"children,omitempty"`
+}
+
+// Graph is implemented by compose.Graph, compose.Chain and compose.Workflow.
+type Graph[I, O any] interface {
+	Compile(ctx context.Context, opts ...compose.GraphCompileOption) (compose.Runnable[I, O], error)
+}
+
+// RunGraph compiles g with opts and invokes it with input, traced by a handler created from a copy of cfg exporting
+// synchronously to a new Exporter, cfg may be nil. The exporter is returned with the output even if the invocation
+// fails, so the run tree of the failure can be checked.
+func RunGraph[I, O any](ctx context.Context, g Graph[I, O], input I, cfg *langsmith.Config,
+	opts ...compose.GraphCompileOption) (O, *Exporter, error) {
+	var out O
+	exp := NewExporter()
+	c := langsmith.Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+	c.Exporter = exp
+	c.Blocking = true
+	h, err := langsmith.NewLangsmithHandler(&c)
+	if err != nil {
+		return out, exp, err
+	}
+	r, err := g.Compile(ctx, opts...)
+	if err != nil {
+		return out, exp, err
+	}
+	out, err = r.Invoke(ctx, input, compose.WithCallbacks(h))
+	if flushErr := h.Flush(ctx); err == nil {
+		err = flushErr
+	}
+	return out, exp, err
+}
+
+// GoldenTree returns the recorded runs as golden run trees, siblings are sorted by dotted order. An error is returned
+// if the runs aren't consistent: a parent run missing, a trace id other than the id of the root run, or a dotted
+// order not extending the dotted order of the parent with the run id.
+func (e *Exporter) GoldenTree() ([]*GoldenRun, error) {
+	runs := e.Runs()
+	byID := make(map[string]*langsmith.Run, len(runs))
+	for _, run := range runs {
+		byID[run.ID] = run
+	}
+	children := map[string][]*langsmith.Run{}
+	var roots []*langsmith.Run
+	var problems []string
+	for _, run := range runs {
+		if run.ParentRunID == nil {
+			roots = append(roots, run)
+			continue
+		}
+		if _, ok := byID[*run.ParentRunID]; !ok {
+			problems = append(problems, fmt.Sprintf("run %q: parent %s wasn't recorded", run.Name, *run.ParentRunID))
+			continue
+		}
+		children[*run.ParentRunID] = append(children[*run.ParentRunID], run)
+	}
+
+	var build func(runs []*langsmith.Run, parent *langsmith.Run, root *langsmith.Run, prefix string) []*GoldenRun
+	build = func(runs []*langsmith.Run, parent *langsmith.Run, root *langsmith.Run, prefix string) []*GoldenRun {
+		sort.SliceStable(runs, func(i, j int) bool { return runs[i].DottedOrder < runs[j].DottedOrder })
+		golden := make([]*GoldenRun, 0, len(runs))
+		for i, run := range runs {
+			traceRoot := root
+			if traceRoot == nil {
+				traceRoot = run
+			}
+			if p := checkRun(run, parent, traceRoot); p != "" {
+				problems = append(problems, p)
+			}
+			order := prefix + strconv.Itoa(i+1)
+			golden = append(golden, &GoldenRun{
+				Name:        run.Name,
+				RunType:     run.RunType,
+				DottedOrder: order,
+				Error:       run.Error != nil,
+				Unfinished:  run.EndTime == nil,
+				Children:    build(children[run.ID], run, traceRoot, order+"."),
+			})
+		}
+		return golden
+	}
+	tree := build(roots, nil, nil, "")
+	if len(problems) > 0 {
+		return tree, fmt.Errorf("inconsistent run tree: %s", strings.Join(problems, "; "))
+	}
+	return tree, nil
+}
+
+// checkRun returns why run isn't consistent with its parent and the root run of its trace, empty if it is.
+func checkRun(run, parent, root *langsmith.Run) string {
+	if run.TraceID != root.ID {
+		return fmt.Sprintf("run %q: trace id %s, want the root run id %s", run.Name, run.TraceID, root.ID)
+	}
+	if !strings.HasSuffix(run.DottedOrder, "Z"+run.ID) {
+		return fmt.Sprintf("run %q: dotted order %q doesn't end with the run id", run.Name, run.DottedOrder)
+	}
+	segment := run.DottedOrder
+	if parent != nil {
+		if !strings.HasPrefix(run.DottedOrder, parent.DottedOrder+".") {
+			return fmt.Sprintf("run %q: dotted order %q doesn't extend the parent's %q",
+				run.Name, run.DottedOrder, parent.DottedOrder)
+		}
+		segment = run.DottedOrder[len(parent.DottedOrder)+1:]
+	}
+	if strings.Contains(segment, ".") {
+		return fmt.Sprintf("run %q: dotted order %q has more segments than ancestors", run.Name, run.DottedOrder)
+	}
+	return ""
+}
+
+// AssertGolden asserts that the recorded runs are consistent and that their GoldenTree equals the JSON of the golden
+// file at path. The file is written instead when UpdateGoldenEnv is set, review its diff before committing it.
+func AssertGolden(t TestingT, e *Exporter, path string) bool {
+	t.Helper()
+	tree, err := e.GoldenTree()
+	if err != nil {
+		t.Errorf("%v", err)
+		return false
+	}
+	got, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		t.Errorf("marshal run tree: %v", err)
+		return false
+	}
+	got = append(got, '\n')
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+			err = os.WriteFile(path, got, 0o644)
+		}
+		if err != nil {
+			t.Errorf("update golden file: %v", err)
+			return false
+		}
+		return true
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("read golden file, set %s=1 to create it: %v", UpdateGoldenEnv, err)
+		return false
+	}
+	if string(want) != string(got) {
+		t.Errorf("run tree differs from golden file %s, set %s=1 to update it:\nwant:\n%s\ngot:\n%s",
+			path, UpdateGoldenEnv, want, got)
+		return false
+	}
+	return true
+}
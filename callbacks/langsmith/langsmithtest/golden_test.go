@@ -0,0 +1,113 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmithtest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudwego/eino-ext/callbacks/langsmith"
+)
+
+func newAgentGraph(t *testing.T, failStep bool) *compose.Graph[string, string] {
+	inner := compose.NewChain[string, string]().
+		AppendLambda(compose.InvokableLambda(func(ctx context.Context, in string) (string, error) {
+			if failStep {
+				return "", errors.New("step failed")
+			}
+			return in + "!", nil
+		}), compose.WithNodeName("step"))
+
+	g := compose.NewGraph[string, string]()
+	require.NoError(t, g.AddLambdaNode("prepare", compose.InvokableLambda(func(ctx context.Context, in string) (string, error) {
+		return strings.ToUpper(in), nil
+	}), compose.WithNodeName("prepare")))
+	require.NoError(t, g.AddGraphNode("inner", inner, compose.WithNodeName("inner"), compose.WithGraphCompileOptions(compose.WithGraphName("inner"))))
+	require.NoError(t, g.AddEdge(compose.START, "prepare"))
+	require.NoError(t, g.AddEdge("prepare", "inner"))
+	require.NoError(t, g.AddEdge("inner", compose.END))
+	return g
+}
+
+// TestGolden 测试运行图并与 golden 文件比对
+func TestGolden(t *testing.T) {
+	ctx := context.Background()
+	out, exp, err := RunGraph[string, string](ctx, newAgentGraph(t, false), "hi", nil, compose.WithGraphName("agent"))
+	require.NoError(t, err)
+	assert.Equal(t, "HI!", out)
+	AssertGolden(t, exp, filepath.Join("testdata", "agent.golden.json"))
+
+	_, exp, err = RunGraph[string, string](ctx, newAgentGraph(t, true), "hi", &langsmith.Config{SessionName: "test"},
+		compose.WithGraphName("agent"))
+	require.Error(t, err)
+	AssertGolden(t, exp, filepath.Join("testdata", "agent_error.golden.json"))
+}
+
+// TestGoldenMismatch 测试 golden 文件不一致或缺失时报错
+func TestGoldenMismatch(t *testing.T) {
+	_, exp, err := RunGraph[string, string](context.Background(), newAgentGraph(t, false), "hi", nil,
+		compose.WithGraphName("renamed"))
+	require.NoError(t, err)
+
+	rt := &recordingT{}
+	assert.False(t, AssertGolden(rt, exp, filepath.Join("testdata", "agent.golden.json")))
+	assert.False(t, AssertGolden(rt, exp, filepath.Join(t.TempDir(), "missing.json")))
+	assert.Len(t, rt.errors, 2)
+
+	path := filepath.Join(t.TempDir(), "nested", "renamed.json")
+	t.Setenv(UpdateGoldenEnv, "1")
+	assert.True(t, AssertGolden(rt, exp, path))
+	t.Setenv(UpdateGoldenEnv, "")
+	assert.True(t, AssertGolden(rt, exp, path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\"name\": \"renamed\"")
+}
+
+// TestGoldenTreeInconsistent 测试父子关系与 dotted order 不一致时报错
+func TestGoldenTreeInconsistent(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	root, missing := "root-id", "missing-id"
+	exp := NewExporter()
+	_ = exp.CreateRun(ctx, &langsmith.Run{ID: root, TraceID: root, Name: "root", StartTime: now,
+		DottedOrder: "20250102T030405000000Z" + root})
+	_ = exp.CreateRun(ctx, &langsmith.Run{ID: "child-id", TraceID: root, Name: "child", ParentRunID: &root,
+		StartTime: now, DottedOrder: "20250102T030405000001Zchild-id"})
+	_ = exp.CreateRun(ctx, &langsmith.Run{ID: "orphan-id", TraceID: root, Name: "orphan", ParentRunID: &missing,
+		StartTime: now})
+
+	tree, err := exp.GoldenTree()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "\"child\": dotted order \"20250102T030405000001Zchild-id\" doesn't extend")
+	assert.Contains(t, err.Error(), "\"orphan\": parent missing-id wasn't recorded")
+	require.Len(t, tree, 1)
+	assert.Equal(t, "1.1", tree[0].Children[0].DottedOrder)
+	assert.True(t, tree[0].Unfinished)
+
+	rt := &recordingT{}
+	assert.False(t, AssertGolden(rt, exp, filepath.Join("testdata", "agent.golden.json")))
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"sync"
+	"time"
+)
+
+// MetadataLatencyBreakdown is the metadata key of the latency breakdown of a trace, reported on its root run when
+// Config.LatencyBreakdown is set, e.g.
+//
+//	{"llm": 2.1, "tool": 0.4, "retriever": 0.2, "chain": 2.9, "queueing": 0.05, "total": 3.1}
+//
+// Per run type, it's the time in seconds spent in the nested runs of the type, overlapping runs such as parallel
+// tool calls or a chain and its children are all counted. queueing is the time the runs of the trace waited for their
+// next child to start while they had none running, and total the duration of the root run.
+const MetadataLatencyBreakdown = "latency_breakdown"
+
+// latencyBreakdown aggregates the durations of the runs of a trace.
+type latencyBreakdown struct {
+	mu        sync.Mutex
+	durations map[RunType]time.Duration
+	queueing  time.Duration
+}
+
+// runTiming tracks the children of a run for the latency breakdown of its trace.
+type runTiming struct {
+	trace  *latencyBreakdown
+	parent *runTiming
+
+	mu        sync.Mutex
+	active    int       // children running
+	idleSince time.Time // end of the last child, or start of the run, while no child is running
+}
+
+// newRunTiming returns the timing of a run started at start, nil unless Config.LatencyBreakdown is set.
+func (c *Config) newRunTiming(parent *runTiming, start time.Time) *runTiming {
+	if !c.LatencyBreakdown {
+		return nil
+	}
+	t := &runTiming{parent: parent, idleSince: start}
+	if parent != nil {
+		t.trace = parent.trace
+	} else {
+		t.trace = &latencyBreakdown{durations: map[RunType]time.Duration{}}
+	}
+	return t
+}
+
+// childStarted records a child run starting, the time since the run had no child running is queueing.
+func (t *runTiming) childStarted(start time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.active == 0 && start.After(t.idleSince) {
+		t.trace.add("", start.Sub(t.idleSince))
+	}
+	t.active++
+}
+
+// finish records the run of t ending, it reports whether it's the root run of the trace.
+func (t *runTiming) finish(runType RunType, start, end time.Time) bool {
+	if t == nil {
+		return false
+	}
+	if t.parent == nil {
+		return true
+	}
+	t.trace.add(runType, end.Sub(start))
+	p := t.parent
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active > 0 {
+		p.active--
+	}
+	if p.active == 0 && end.After(p.idleSince) {
+		p.idleSince = end
+	}
+	return false
+}
+
+// add adds the duration of a run of runType, or queueing if runType is empty.
+func (b *latencyBreakdown) add(runType RunType, d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if runType == "" {
+		b.queueing += d
+		return
+	}
+	b.durations[runType] += d
+}
+
+// report sets the latency breakdown of the trace into extra.metadata, total is the duration of the root run.
+func (t *runTiming) report(extra map[string]interface{}, total time.Duration) {
+	b := t.trace
+	b.mu.Lock()
+	breakdown := make(map[string]float64, len(b.durations)+2)
+	for runType, d := range b.durations {
+		breakdown[string(runType)] = d.Seconds()
+	}
+	breakdown["queueing"] = b.queueing.Seconds()
+	breakdown["total"] = total.Seconds()
+	b.mu.Unlock()
+	setRunMetadata(extra, MetadataLatencyBreakdown, breakdown)
+}
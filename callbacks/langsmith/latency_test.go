@@ -0,0 +1,71 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLatencyBreakdown 测试根运行按运行类型记录耗时分布与排队间隔
+func TestLatencyBreakdown(t *testing.T) {
+	mCli := new(mockLangsmith)
+	h, err := NewLangsmithHandler(&Config{Exporter: mCli, Blocking: true, LatencyBreakdown: true})
+	require.NoError(t, err)
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	patches := map[string]*RunPatch{}
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		patches[args.String(1)] = args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	run := func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput, d time.Duration) {
+		ctx = h.OnStart(ctx, info, input)
+		time.Sleep(d)
+		h.OnEnd(ctx, info, "output")
+	}
+	rootInfo := &callbacks.RunInfo{Name: "root", Component: "Graph"}
+	rootCtx := h.OnStart(context.Background(), rootInfo, "input")
+	run(rootCtx, &callbacks.RunInfo{Name: "model", Component: components.ComponentOfChatModel}, &model.CallbackInput{}, 20*time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	run(rootCtx, &callbacks.RunInfo{Name: "search", Component: components.ComponentOfTool}, "{}", 10*time.Millisecond)
+	h.OnEnd(rootCtx, rootInfo, "output")
+
+	root := rootCtx.Value(langsmithStateKey{}).(*LangsmithState)
+	patch := patches[root.ParentRunID]
+	require.NotNil(t, patch)
+	breakdown := patch.Extra["metadata"].(map[string]interface{})[MetadataLatencyBreakdown].(map[string]float64)
+	assert.GreaterOrEqual(t, breakdown["llm"], 0.02)
+	assert.GreaterOrEqual(t, breakdown["tool"], 0.01)
+	assert.GreaterOrEqual(t, breakdown["queueing"], 0.01)
+	assert.GreaterOrEqual(t, breakdown["total"], breakdown["llm"]+breakdown["tool"]+breakdown["queueing"])
+	assert.NotContains(t, breakdown, "chain")
+
+	// nested runs don't report the breakdown
+	for id, p := range patches {
+		if id != root.ParentRunID && p.Extra != nil {
+			assert.NotContains(t, p.Extra["metadata"], MetadataLatencyBreakdown)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Logger is used by the handler, FlowTrace and client to report errors, retries and dropped runs.
+// keysAndValues are alternating key/value pairs, which makes it easy to adapt structured loggers such as slog or zap.
+type Logger interface {
+	Debug(ctx context.Context, msg string, keysAndValues ...interface{})
+	Warn(ctx context.Context, msg string, keysAndValues ...interface{})
+	Error(ctx context.Context, msg string, keysAndValues ...interface{})
+}
+
+// NewStdLogger creates a Logger writing to the standard library log package, debug messages are dropped unless debug is true.
+func NewStdLogger(debug bool) Logger {
+	return &stdLogger{debug: debug}
+}
+
+var defaultLogger = NewStdLogger(false)
+
+type stdLogger struct {
+	debug bool
+}
+
+func (l *stdLogger) Debug(_ context.Context, msg string, keysAndValues ...interface{}) {
+	if l.debug {
+		l.output("DEBUG", msg, keysAndValues)
+	}
+}
+
+func (l *stdLogger) Warn(_ context.Context, msg string, keysAndValues ...interface{}) {
+	l.output("WARN", msg, keysAndValues)
+}
+
+func (l *stdLogger) Error(_ context.Context, msg string, keysAndValues ...interface{}) {
+	l.output("ERROR", msg, keysAndValues)
+}
+
+func (l *stdLogger) output(level, msg string, keysAndValues []interface{}) {
+	sb := &strings.Builder{}
+	sb.WriteString("[langsmith] ")
+	sb.WriteString(level)
+	sb.WriteString(" ")
+	sb.WriteString(msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 < len(keysAndValues) {
+			sb.WriteString(fmt.Sprintf(" %v=%+v", keysAndValues[i], keysAndValues[i+1]))
+		} else {
+			sb.WriteString(fmt.Sprintf(" %+v", keysAndValues[i]))
+		}
+	}
+	log.Print(sb.String())
+}
+
+// logger returns the configured Logger, falling back to the standard library logger.
+func (c *Config) logger() Logger {
+	if c == nil || c.Logger == nil {
+		return defaultLogger
+	}
+	return c.Logger
+}
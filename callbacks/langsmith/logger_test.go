@@ -0,0 +1,83 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// recordLogger 记录所有日志，用于断言
+type recordLogger struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *recordLogger) record(level, msg string, keysAndValues []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, fmt.Sprintf("%s %s %v", level, msg, keysAndValues))
+}
+
+func (l *recordLogger) Debug(_ context.Context, msg string, keysAndValues ...interface{}) {
+	l.record("DEBUG", msg, keysAndValues)
+}
+
+func (l *recordLogger) Warn(_ context.Context, msg string, keysAndValues ...interface{}) {
+	l.record("WARN", msg, keysAndValues)
+}
+
+func (l *recordLogger) Error(_ context.Context, msg string, keysAndValues ...interface{}) {
+	l.record("ERROR", msg, keysAndValues)
+}
+
+func TestStdLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+
+	l := NewStdLogger(false)
+	l.Debug(context.Background(), "hidden")
+	l.Error(context.Background(), "failed", "err", "boom", "dangling")
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), "[langsmith] ERROR failed err=boom dangling")
+
+	NewStdLogger(true).Debug(context.Background(), "shown", "k", 1)
+	assert.Contains(t, buf.String(), "[langsmith] DEBUG shown k=1")
+}
+
+func TestConfigLogger(t *testing.T) {
+	var nilCfg *Config
+	assert.Equal(t, defaultLogger, nilCfg.logger())
+
+	l := &recordLogger{}
+	mCli := &mockLangsmith{}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{Logger: l, RunIDGen: DefaultRunIDGen}}
+	h.OnEnd(context.Background(), &callbacks.RunInfo{Name: "node"}, "out")
+	assert.Len(t, l.entries, 1)
+	assert.Contains(t, l.entries[0], "WARN no state in context on OnEnd")
+}
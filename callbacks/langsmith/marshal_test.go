@@ -0,0 +1,81 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingMarshaler struct {
+	marshaled, unmarshaled int
+}
+
+func (m *countingMarshaler) Marshal(v interface{}) ([]byte, error) {
+	m.marshaled++
+	return json.Marshal(v)
+}
+
+func (m *countingMarshaler) Unmarshal(data []byte, v interface{}) error {
+	m.unmarshaled++
+	return json.Unmarshal(data, v)
+}
+
+func TestWithMarshaler(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"run-1"}`))
+	}))
+	defer srv.Close()
+
+	m := &countingMarshaler{}
+	cli := NewLangsmith("key", srv.URL, WithMarshaler(m), WithRunReadBack(true))
+	require.NoError(t, cli.CreateRun(context.Background(), &Run{ID: "run-1"}))
+	require.NoError(t, cli.UpdateRun(context.Background(), "run-1", &RunPatch{}))
+	assert.Equal(t, 2, m.marshaled)
+	assert.Equal(t, 1, m.unmarshaled)
+}
+
+func TestReadBody(t *testing.T) {
+	body, err := readBody(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Nil(t, body)
+
+	large := bytes.Repeat([]byte("a"), maxPooledBufferSize+1)
+	body, err = readBody(bytes.NewReader(large))
+	require.NoError(t, err)
+	assert.Equal(t, large, body)
+
+	body, err = readBody(strings.NewReader("ok"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ok"), body)
+}
+
+func BenchmarkReadBody(b *testing.B) {
+	data := bytes.Repeat([]byte("a"), 4096)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = readBody(bytes.NewReader(data))
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// DeferredParentHeader marks a message whose consumer finishes the producer run, see WithDeferredParent.
+const DeferredParentHeader = "langsmith-deferred-parent"
+
+// MessageCarrier adapts the headers of a message queue client, e.g. kafka or rocketmq message headers,
+// to carry the trace across an async pipeline.
+type MessageCarrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+// MapCarrier is a MessageCarrier backed by a map, it can be embedded into a json payload when the broker has no headers.
+type MapCarrier map[string]string
+
+// Get implements MessageCarrier
+func (m MapCarrier) Get(key string) string {
+	return m[key]
+}
+
+// Set implements MessageCarrier
+func (m MapCarrier) Set(key, value string) {
+	m[key] = value
+}
+
+// MessageOption customizes InjectMessage
+type MessageOption func(*messageOptions)
+
+type messageOptions struct {
+	deferredParent bool
+}
+
+// WithDeferredParent hands the run ctx is traced in over to the consumer: the producer doesn't finish it, and the
+// consumer calls FlowTrace.FinishDeferredParent once the message is processed, so the producer span covers the
+// asynchronous processing and the consumer runs are nested under it.
+func WithDeferredParent() MessageOption {
+	return func(o *messageOptions) {
+		o.deferredParent = true
+	}
+}
+
+// InjectMessage writes the run ctx is traced in into the headers of a produced message.
+func InjectMessage(ctx context.Context, carrier MessageCarrier, opts ...MessageOption) {
+	options := &messageOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	header := http.Header{}
+	InjectTraceContext(ctx, header)
+	if len(header) == 0 {
+		return
+	}
+	for key := range header {
+		carrier.Set(strings.ToLower(key), header.Get(key))
+	}
+	if options.deferredParent {
+		carrier.Set(DeferredParentHeader, CurrentRunID(ctx))
+	}
+}
+
+type deferredParentKey struct{}
+
+// ExtractMessage continues the trace of a consumed message, runs started from the returned context are children
+// of the producer run, pass the state of GetState to FlowTrace.StartSpan. ctx is returned unchanged when the message
+// isn't traced.
+func ExtractMessage(ctx context.Context, carrier MessageCarrier) context.Context {
+	header := http.Header{}
+	for _, key := range []string{TraceHeader, BaggageHeader, TraceparentHeader} {
+		if value := carrier.Get(key); value != "" {
+			header.Set(key, value)
+		}
+	}
+	newCtx := ExtractTraceContext(ctx, header)
+	if newCtx == ctx {
+		return ctx
+	}
+	if runID := carrier.Get(DeferredParentHeader); runID != "" {
+		newCtx = context.WithValue(newCtx, deferredParentKey{}, runID)
+	}
+	return newCtx
+}
+
+// FinishDeferredParent finishes the producer run of a message injected WithDeferredParent, it's a no-op for other
+// messages. ctx is the context returned by ExtractMessage, or any context derived from it.
+func (ft *FlowTrace) FinishDeferredParent(ctx context.Context) {
+	if runID, _ := ctx.Value(deferredParentKey{}).(string); runID != "" {
+		ft.FinishSpan(ctx, runID)
+	}
+}
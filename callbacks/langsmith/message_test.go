@@ -0,0 +1,71 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessagePropagation(t *testing.T) {
+	mCli := new(mockLangsmith)
+	ids := []string{"producer-run", "consumer-run"}
+	ft := &FlowTrace{cli: mCli, cfg: &Config{RunIDGen: func(ctx context.Context) string {
+		id := ids[0]
+		ids = ids[1:]
+		return id
+	}}}
+	var consumerRun *Run
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if run := args.Get(1).(*Run); run.ID == "consumer-run" {
+			consumerRun = run
+		}
+	}).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, "consumer-run", mock.Anything).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, "producer-run", mock.Anything).Return(nil)
+
+	// producer
+	producerCtx, _, err := ft.StartSpan(context.Background(), "enqueue", nil)
+	require.NoError(t, err)
+	carrier := MapCarrier{}
+	InjectMessage(producerCtx, carrier, WithDeferredParent())
+	assert.NotEmpty(t, carrier[TraceHeader])
+	assert.Equal(t, "producer-run", carrier[DeferredParentHeader])
+
+	// consumer
+	ctx := ExtractMessage(context.Background(), carrier)
+	assert.Equal(t, "producer-run", CurrentRunID(ctx))
+	_, state := GetState(ctx)
+	spanCtx, runID, err := ft.StartSpan(ctx, "consume", state)
+	require.NoError(t, err)
+	require.NotNil(t, consumerRun.ParentRunID)
+	assert.Equal(t, "producer-run", *consumerRun.ParentRunID)
+	assert.Equal(t, "producer-run", consumerRun.TraceID)
+	ft.FinishSpan(spanCtx, runID)
+	ft.FinishDeferredParent(spanCtx)
+	mCli.AssertCalled(t, "UpdateRun", mock.Anything, "producer-run", mock.Anything)
+
+	// untraced message
+	empty := MapCarrier{}
+	InjectMessage(context.Background(), empty)
+	assert.Empty(t, empty)
+	assert.Equal(t, context.Background(), ExtractMessage(context.Background(), empty))
+}
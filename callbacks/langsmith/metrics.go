@@ -0,0 +1,168 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// ExportOp identifies the kind of run export request sent to langsmith.
+type ExportOp string
+
+const (
+	ExportOpCreate ExportOp = "create" // POST /runs
+	ExportOpUpdate ExportOp = "update" // PATCH /runs/{run_id}
+)
+
+// APIOp identifies the kind of the other requests sent to langsmith, e.g. "feedback", "dataset" or "project".
+type APIOp string
+
+// requestOp is the kind of a request, ExportOp or APIOp, notified to Metrics when it's retried.
+type requestOp interface {
+	retried(m Metrics)
+}
+
+func (op ExportOp) retried(m Metrics) {
+	m.ExportRetried(op)
+}
+
+func (op APIOp) retried(m Metrics) {
+	if api, ok := m.(APIMetrics); ok {
+		api.APIRetried(op)
+	}
+}
+
+// Metrics receives exporter events, implement it to forward them to prometheus, statsd or any other system.
+// Methods are called concurrently and must not block.
+type Metrics interface {
+	// ExportStarted is called when a run is handed to the exporter.
+	ExportStarted(op ExportOp)
+	// ExportFinished is called when the export ultimately succeeded or failed, latency covers queueing and retries.
+	ExportFinished(op ExportOp, latency time.Duration, err error)
+	// ExportRetried is called every time a run export request is retried.
+	ExportRetried(op ExportOp)
+}
+
+// APIMetrics can be implemented along with Metrics to count the retries of the langsmith API requests that don't
+// export runs, e.g. feedback, datasets or prompts, they aren't reported to Metrics.
+type APIMetrics interface {
+	// APIRetried is called every time an API request is retried.
+	APIRetried(op APIOp)
+}
+
+// latencyBuckets are the upper bounds of the export latency histogram of ExpvarMetrics.
+var latencyBuckets = []time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// ExpvarMetrics is a Metrics implementation backed by expvar, exposed at /debug/vars when published.
+type ExpvarMetrics struct {
+	vars *expvar.Map
+
+	runsCreated expvar.Int
+	runsUpdated expvar.Int
+	failures    expvar.Int
+	retries     expvar.Int
+	apiRetries  expvar.Int
+	queueDepth  expvar.Int
+	latency     *expvar.Map
+
+	inflight int64
+}
+
+// NewExpvarMetrics creates an ExpvarMetrics, and publishes it under name unless name is empty.
+// like expvar.Publish, it panics if name is already registered.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	m := &ExpvarMetrics{
+		vars:    new(expvar.Map).Init(),
+		latency: new(expvar.Map).Init(),
+	}
+	m.vars.Set("runs_created", &m.runsCreated)
+	m.vars.Set("runs_updated", &m.runsUpdated)
+	m.vars.Set("failures", &m.failures)
+	m.vars.Set("retries", &m.retries)
+	m.vars.Set("api_retries", &m.apiRetries)
+	m.vars.Set("queue_depth", &m.queueDepth)
+	m.vars.Set("export_latency", m.latency)
+	if name != "" {
+		expvar.Publish(name, m.vars)
+	}
+	return m
+}
+
+// Vars returns the underlying expvar map.
+func (m *ExpvarMetrics) Vars() *expvar.Map {
+	return m.vars
+}
+
+func (m *ExpvarMetrics) ExportStarted(ExportOp) {
+	m.queueDepth.Set(atomic.AddInt64(&m.inflight, 1))
+}
+
+func (m *ExpvarMetrics) ExportFinished(op ExportOp, latency time.Duration, err error) {
+	m.queueDepth.Set(atomic.AddInt64(&m.inflight, -1))
+	m.latency.Add(latencyBucket(latency), 1)
+	if err != nil {
+		m.failures.Add(1)
+		return
+	}
+	switch op {
+	case ExportOpCreate:
+		m.runsCreated.Add(1)
+	case ExportOpUpdate:
+		m.runsUpdated.Add(1)
+	}
+}
+
+func (m *ExpvarMetrics) ExportRetried(ExportOp) {
+	m.retries.Add(1)
+}
+
+func (m *ExpvarMetrics) APIRetried(APIOp) {
+	m.apiRetries.Add(1)
+}
+
+func latencyBucket(latency time.Duration) string {
+	for _, b := range latencyBuckets {
+		if latency <= b {
+			return "le_" + b.String()
+		}
+	}
+	return "le_inf"
+}
+
+// nopMetrics is used when Config.Metrics is not set.
+type nopMetrics struct{}
+
+func (nopMetrics) ExportStarted(ExportOp)                        {}
+func (nopMetrics) ExportFinished(ExportOp, time.Duration, error) {}
+func (nopMetrics) ExportRetried(ExportOp)                        {}
+
+func (c *Config) metrics() Metrics {
+	if c == nil || c.Metrics == nil {
+		return nopMetrics{}
+	}
+	return c.Metrics
+}
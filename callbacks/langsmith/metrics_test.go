@@ -0,0 +1,79 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExpvarMetrics(t *testing.T) {
+	m := NewExpvarMetrics("")
+
+	m.ExportStarted(ExportOpCreate)
+	m.ExportStarted(ExportOpUpdate)
+	assert.Equal(t, "2", m.Vars().Get("queue_depth").String())
+
+	m.ExportFinished(ExportOpCreate, 5*time.Millisecond, nil)
+	m.ExportFinished(ExportOpUpdate, 2*time.Second, errors.New("failed"))
+	m.ExportRetried(ExportOpUpdate)
+	m.APIRetried(opFeedback)
+
+	assert.Equal(t, "0", m.Vars().Get("queue_depth").String())
+	assert.Equal(t, "1", m.Vars().Get("runs_created").String())
+	assert.Equal(t, "0", m.Vars().Get("runs_updated").String())
+	assert.Equal(t, "1", m.Vars().Get("failures").String())
+	assert.Equal(t, "1", m.Vars().Get("retries").String())
+	assert.Equal(t, "1", m.Vars().Get("api_retries").String())
+	assert.JSONEq(t, `{"le_10ms": 1, "le_5s": 1}`, m.Vars().Get("export_latency").String())
+}
+
+func TestExportMetrics(t *testing.T) {
+	mCli := new(mockLangsmith)
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	m := NewExpvarMetrics("")
+	cfg := &Config{Metrics: m}
+
+	err := createRun(context.Background(), mCli, cfg, &Run{ID: "run-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", m.Vars().Get("runs_created").String())
+}
+
+func TestClientRetryMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	m := NewExpvarMetrics("")
+	cli := NewLangsmith("test-key", srv.URL, WithMaxRetries(2, time.Millisecond), WithClientMetrics(m))
+	assert.Error(t, cli.UpdateRun(context.Background(), "run-1", &RunPatch{}))
+	assert.Equal(t, "2", m.Vars().Get("retries").String())
+
+	// the other API requests aren't run exports
+	_, err := cli.(FeedbackClient).CreateFeedback(context.Background(), &Feedback{RunID: "run-1", Key: "score"})
+	assert.Error(t, err)
+	assert.Equal(t, "2", m.Vars().Get("retries").String())
+	assert.Equal(t, "2", m.Vars().Get("api_retries").String())
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"sync"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Metadata of the runs of multi-agent systems. Langsmith has no sub-agent run type, sub-agents keep the run type of
+// their component and are told apart by MetadataAgentRole.
+const (
+	MetadataAgentRole        = "agent_role"        // AgentRoleHost or AgentRoleSubAgent
+	MetadataAgentName        = "agent_name"        // name of the agent node
+	MetadataDelegatedBy      = "delegated_by"      // name of the host that handed off to a sub-agent
+	MetadataHandoffArguments = "handoff_arguments" // arguments of the tool call of the host that selected the sub-agent
+
+	AgentRoleHost     = "host"
+	AgentRoleSubAgent = "sub_agent"
+)
+
+// specialistLambdaType is the RunInfo.Type of the specialists of eino host multi-agents implemented as lambdas.
+const specialistLambdaType = "Specialist"
+
+// DefaultMultiAgentGraphNames are the multi-agent graphs detected when Config.MultiAgentGraphNames is empty, the
+// default name of eino host multi-agents.
+var DefaultMultiAgentGraphNames = []string{"host multi agent"}
+
+// multiAgent tracks the agents of a host multi-agent graph: the host is its first chat model, which hands off to the
+// specialists by calling them as tools, every other chat model or specialist node is a sub-agent.
+type multiAgent struct {
+	mu       sync.Mutex
+	host     string
+	handoffs map[string]string // sub-agent name -> arguments of the host tool call
+}
+
+// newMultiAgent returns the agents of info if it's a multi-agent graph, see Config.MultiAgentGraphNames.
+func (c *Config) newMultiAgent(info *callbacks.RunInfo) *multiAgent {
+	if info.Component != compose.ComponentOfGraph {
+		return nil
+	}
+	names := c.MultiAgentGraphNames
+	if len(names) == 0 {
+		names = DefaultMultiAgentGraphNames
+	}
+	if !containsString(names, info.Name) {
+		return nil
+	}
+	return &multiAgent{handoffs: map[string]string{}}
+}
+
+// setAgentMetadata sets the multi-agent metadata of the run of info started in state. The agents are returned for
+// the host run, which records its handoffs in them.
+func setAgentMetadata(info *callbacks.RunInfo, state *LangsmithState, extra map[string]interface{}) *multiAgent {
+	specialist := info.Component == compose.ComponentOfLambda && info.Type == specialistLambdaType
+	agents := state.agents
+	if agents == nil {
+		// a specialist outside of the graph of its multi-agent, e.g. a renamed one
+		if specialist {
+			setRunMetadata(extra, MetadataAgentRole, AgentRoleSubAgent)
+			setRunMetadata(extra, MetadataAgentName, info.Name)
+		}
+		return nil
+	}
+	if !specialist && info.Component != components.ComponentOfChatModel {
+		return nil
+	}
+
+	agents.mu.Lock()
+	defer agents.mu.Unlock()
+	if agents.host == "" && !specialist {
+		agents.host = info.Name
+		setRunMetadata(extra, MetadataAgentRole, AgentRoleHost)
+		setRunMetadata(extra, MetadataAgentName, info.Name)
+		return agents
+	}
+	if info.Name == agents.host {
+		return nil
+	}
+	setRunMetadata(extra, MetadataAgentRole, AgentRoleSubAgent)
+	setRunMetadata(extra, MetadataAgentName, info.Name)
+	setRunMetadata(extra, MetadataDelegatedBy, agents.host)
+	if args, ok := agents.handoffs[info.Name]; ok {
+		setRunMetadata(extra, MetadataHandoffArguments, args)
+	}
+	return nil
+}
+
+// recordHandoffs records the sub-agents the host selected with toolCalls, a is nil for other runs.
+func (a *multiAgent) recordHandoffs(toolCalls []schema.ToolCall) {
+	if a == nil || len(toolCalls) == 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, tc := range toolCalls {
+		a.handoffs[tc.Function.Name] = tc.Function.Arguments
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/flow/agent/multiagent/host"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMultiAgent 测试 host 多智能体中 host 与 sub-agent 的识别及 handoff 参数
+func TestMultiAgent(t *testing.T) {
+	mCli := &mockLangsmith{}
+	h := &CallbackHandler{cli: mCli, cfg: &Config{
+		RunIDGen: func(ctx context.Context) string { return uuid.NewString() },
+	}}
+	var mu sync.Mutex
+	runs := map[string]*Run{}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		run := args.Get(1).(*Run)
+		runs[run.Name] = run
+	}).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	ctx := context.Background()
+	handoff := schema.ToolCall{ID: "call-1", Function: schema.FunctionCall{Name: "writer", Arguments: `{"reason":"needs a poem"}`}}
+	ma, err := host.NewMultiAgent(ctx, &host.MultiAgentConfig{
+		Host: host.Host{ToolCallingModel: &scriptedModel{messages: []*schema.Message{
+			schema.AssistantMessage("", []schema.ToolCall{handoff}),
+		}}},
+		Specialists: []*host.Specialist{
+			{
+				AgentMeta: host.AgentMeta{Name: "writer", IntendedUse: "write poems"},
+				Invokable: func(ctx context.Context, input []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+					return schema.AssistantMessage("roses are red", nil), nil
+				},
+			},
+			{
+				AgentMeta: host.AgentMeta{Name: "critic", IntendedUse: "review poems"},
+				ChatModel: &scriptedModel{},
+			},
+		},
+	})
+	require.NoError(t, err)
+	out, err := ma.Generate(ctx, []*schema.Message{schema.UserMessage("a poem please")},
+		agent.WithComposeOptions(compose.WithCallbacks(h)))
+	require.NoError(t, err)
+	assert.Equal(t, "roses are red", out.Content)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Contains(t, runs, "host")
+	md := runs["host"].Extra["metadata"].(map[string]interface{})
+	assert.Equal(t, AgentRoleHost, md[MetadataAgentRole])
+	assert.Equal(t, "host", md[MetadataAgentName])
+
+	require.Contains(t, runs, "writer")
+	md = runs["writer"].Extra["metadata"].(map[string]interface{})
+	assert.Equal(t, AgentRoleSubAgent, md[MetadataAgentRole])
+	assert.Equal(t, "writer", md[MetadataAgentName])
+	assert.Equal(t, "host", md[MetadataDelegatedBy])
+	assert.Equal(t, `{"reason":"needs a poem"}`, md[MetadataHandoffArguments])
+	assert.NotContains(t, runs, "critic")
+
+	for name, run := range runs {
+		if name != "host" && name != "writer" {
+			assert.NotContains(t, run.Extra["metadata"], MetadataAgentRole, name)
+		}
+	}
+}
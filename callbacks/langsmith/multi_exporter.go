@@ -0,0 +1,115 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// MultiExporter is a RunExporter sending every run to several backends, e.g. the langsmith API and a FileExporter,
+// or two langsmith workspaces:
+//
+//	cfg.Exporter = NewMultiExporter(NewLangsmith(apiKey, apiURL), fileExporter)
+//
+// Backends are called concurrently and isolated from each other: a failing, panicking or slow backend doesn't prevent
+// the others from receiving the run. Failures are returned as *MultiExportError.
+type MultiExporter struct {
+	exporters []RunExporter
+}
+
+// NewMultiExporter creates a MultiExporter over exporters, nil exporters are skipped.
+func NewMultiExporter(exporters ...RunExporter) *MultiExporter {
+	m := &MultiExporter{}
+	for _, e := range exporters {
+		if e != nil {
+			m.exporters = append(m.exporters, e)
+		}
+	}
+	return m
+}
+
+// ExporterError is the failure of one backend of a MultiExporter.
+type ExporterError struct {
+	Index int // index of the backend in NewMultiExporter
+	Err   error
+}
+
+// MultiExportError is returned by MultiExporter when at least one backend failed.
+type MultiExportError struct {
+	Errors []ExporterError
+}
+
+func (e *MultiExportError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, fmt.Sprintf("exporter %d: %v", err.Index, err.Err))
+	}
+	return fmt.Sprintf("%d of the exporters failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// CreateRun implements RunExporter
+func (m *MultiExporter) CreateRun(ctx context.Context, run *Run) error {
+	return m.each(func(e RunExporter) error { return e.CreateRun(ctx, run) })
+}
+
+// UpdateRun implements RunExporter
+func (m *MultiExporter) UpdateRun(ctx context.Context, runID string, patch *RunPatch) error {
+	return m.each(func(e RunExporter) error { return e.UpdateRun(ctx, runID, patch) })
+}
+
+// Close closes the backends implementing io.Closer, e.g. FileExporter.
+func (m *MultiExporter) Close() error {
+	return m.each(func(e RunExporter) error {
+		if c, ok := e.(io.Closer); ok {
+			return c.Close()
+		}
+		return nil
+	})
+}
+
+func (m *MultiExporter) each(fn func(e RunExporter) error) error {
+	errs := make([]error, len(m.exporters))
+	wg := sync.WaitGroup{}
+	for i, e := range m.exporters {
+		wg.Add(1)
+		go func(i int, e RunExporter) {
+			defer wg.Done()
+			defer func() {
+				if p := recover(); p != nil {
+					errs[i] = fmt.Errorf("exporter panic: %v", p)
+				}
+			}()
+			errs[i] = fn(e)
+		}(i, e)
+	}
+	wg.Wait()
+
+	var failed []ExporterError
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, ExporterError{Index: i, Err: err})
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &MultiExportError{Errors: failed}
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type panicExporter struct{}
+
+func (panicExporter) CreateRun(ctx context.Context, run *Run) error { panic("boom") }
+
+func (panicExporter) UpdateRun(ctx context.Context, runID string, patch *RunPatch) error {
+	panic("boom")
+}
+
+func TestMultiExporter(t *testing.T) {
+	ctx := context.Background()
+	ok1, ok2, failing := &mockLangsmith{}, &mockLangsmith{}, &mockLangsmith{}
+	run := &Run{ID: "run-1"}
+	patch := &RunPatch{}
+	for _, m := range []*mockLangsmith{ok1, ok2} {
+		m.On("CreateRun", mock.Anything, run).Return(nil).Once()
+		m.On("UpdateRun", mock.Anything, "run-1", patch).Return(nil).Once()
+	}
+	failing.On("CreateRun", mock.Anything, run).Return(errors.New("down")).Once()
+	failing.On("UpdateRun", mock.Anything, "run-1", patch).Return(nil).Once()
+
+	m := NewMultiExporter(ok1, nil, failing, panicExporter{}, ok2)
+	err := m.CreateRun(ctx, run)
+	var multiErr *MultiExportError
+	require.True(t, errors.As(err, &multiErr))
+	require.Len(t, multiErr.Errors, 2)
+	assert.Equal(t, 1, multiErr.Errors[0].Index)
+	assert.EqualError(t, multiErr.Errors[0].Err, "down")
+	assert.Equal(t, 2, multiErr.Errors[1].Index)
+	assert.Contains(t, err.Error(), "2 of the exporters failed")
+
+	assert.Error(t, m.UpdateRun(ctx, "run-1", patch)) // panicExporter still fails
+	for _, m := range []*mockLangsmith{ok1, ok2, failing} {
+		m.AssertExpectations(t)
+	}
+
+	assert.NoError(t, NewMultiExporter(ok1).Close())
+}
+
+func TestMultiExporterClose(t *testing.T) {
+	exp, err := NewFileExporter(&FileExporterConfig{Path: t.TempDir() + "/runs.jsonl"})
+	require.NoError(t, err)
+	m := NewMultiExporter(exp, NewConsoleExporter(nil))
+	require.NoError(t, m.Close())
+	assert.Error(t, exp.CreateRun(context.Background(), &Run{ID: "run-1"}))
+}
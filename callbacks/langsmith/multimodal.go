@@ -0,0 +1,134 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// lsMessage is a chat message in the openai format langsmith renders in the run view, its content is a string or,
+// for multimodal messages, a list of lsContentPart.
+type lsMessage struct {
+	Role       string            `json:"role"`
+	Content    interface{}       `json:"content"`
+	Name       string            `json:"name,omitempty"`
+	ToolCalls  []schema.ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string            `json:"tool_call_id,omitempty"`
+}
+
+// lsContentPart is a part of a multimodal lsMessage. Images use the openai image_url part, which langsmith previews,
+// audio, videos and files keep the eino part type with their url and mime type.
+type lsContentPart struct {
+	Type     string      `json:"type"`
+	Text     string      `json:"text,omitempty"`
+	ImageURL *lsMediaURL `json:"image_url,omitempty"`
+	AudioURL *lsMediaURL `json:"audio_url,omitempty"`
+	VideoURL *lsMediaURL `json:"video_url,omitempty"`
+	FileURL  *lsMediaURL `json:"file_url,omitempty"`
+}
+
+type lsMediaURL struct {
+	URL      string `json:"url"`
+	Detail   string `json:"detail,omitempty"`
+	MIMEType string `json:"mime_type,omitempty"`
+	Name     string `json:"name,omitempty"`
+}
+
+// multimodalInput returns the messages of a chat model input in the langsmith format if any of them is multimodal,
+// nil otherwise, text-only inputs keep their default serialization.
+func multimodalInput(info *callbacks.RunInfo, input callbacks.CallbackInput) []*lsMessage {
+	if info.Component != components.ComponentOfChatModel {
+		return nil
+	}
+	in := model.ConvCallbackInput(input)
+	if in == nil {
+		return nil
+	}
+	return multimodalMessages(in.Messages)
+}
+
+// multimodalMessages converts messages to the langsmith format if any of them is multimodal, nil otherwise.
+func multimodalMessages(messages []*schema.Message) []*lsMessage {
+	multimodal := false
+	for _, msg := range messages {
+		if msg != nil && len(msg.MultiContent) > 0 {
+			multimodal = true
+			break
+		}
+	}
+	if !multimodal {
+		return nil
+	}
+	res := make([]*lsMessage, 0, len(messages))
+	for _, msg := range messages {
+		if msg != nil {
+			res = append(res, newLSMessage(msg))
+		}
+	}
+	return res
+}
+
+func newLSMessage(msg *schema.Message) *lsMessage {
+	m := &lsMessage{
+		Role:       string(msg.Role),
+		Content:    msg.Content,
+		Name:       msg.Name,
+		ToolCalls:  msg.ToolCalls,
+		ToolCallID: msg.ToolCallID,
+	}
+	if len(msg.MultiContent) == 0 {
+		return m
+	}
+	parts := make([]*lsContentPart, 0, len(msg.MultiContent)+1)
+	if msg.Content != "" {
+		parts = append(parts, &lsContentPart{Type: string(schema.ChatMessagePartTypeText), Text: msg.Content})
+	}
+	for _, part := range msg.MultiContent {
+		p := &lsContentPart{Type: string(part.Type), Text: part.Text}
+		switch {
+		case part.ImageURL != nil:
+			p.ImageURL = &lsMediaURL{
+				URL:      mediaURL(part.ImageURL.URL, part.ImageURL.URI),
+				Detail:   string(part.ImageURL.Detail),
+				MIMEType: part.ImageURL.MIMEType,
+			}
+		case part.AudioURL != nil:
+			p.AudioURL = &lsMediaURL{URL: mediaURL(part.AudioURL.URL, part.AudioURL.URI), MIMEType: part.AudioURL.MIMEType}
+		case part.VideoURL != nil:
+			p.VideoURL = &lsMediaURL{URL: mediaURL(part.VideoURL.URL, part.VideoURL.URI), MIMEType: part.VideoURL.MIMEType}
+		case part.FileURL != nil:
+			p.FileURL = &lsMediaURL{
+				URL:      mediaURL(part.FileURL.URL, part.FileURL.URI),
+				MIMEType: part.FileURL.MIMEType,
+				Name:     part.FileURL.Name,
+			}
+		}
+		parts = append(parts, p)
+	}
+	m.Content = parts
+	return m
+}
+
+func mediaURL(url, uri string) string {
+	if url != "" {
+		return url
+	}
+	return uri
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMultimodalInput 测试多模态消息按 langsmith 的 openai 消息格式上报
+func TestMultimodalInput(t *testing.T) {
+	h := &CallbackHandler{cfg: &Config{InlineMedia: true}}
+	info := &callbacks.RunInfo{Name: "model", Component: components.ComponentOfChatModel}
+	input := &model.CallbackInput{Messages: []*schema.Message{
+		schema.SystemMessage("describe"),
+		{Role: schema.User, Content: "what is it?", MultiContent: []schema.ChatMessagePart{
+			{Type: schema.ChatMessagePartTypeImageURL, ImageURL: &schema.ChatMessageImageURL{URL: "https://example.com/cat.png", Detail: schema.ImageURLDetailHigh}},
+			{Type: schema.ChatMessagePartTypeAudioURL, AudioURL: &schema.ChatMessageAudioURL{URI: "s3://bucket/a.wav", MIMEType: "audio/wav"}},
+		}},
+	}}
+
+	inputs, err := h.runInputs(info, input)
+	require.NoError(t, err)
+	got, err := sonic.MarshalString(inputs)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"messages":[
+		{"role":"system","content":"describe"},
+		{"role":"user","content":[
+			{"type":"text","text":"what is it?"},
+			{"type":"image_url","image_url":{"url":"https://example.com/cat.png","detail":"high"}},
+			{"type":"audio_url","audio_url":{"url":"s3://bucket/a.wav","mime_type":"audio/wav"}}
+		]}
+	]}`, got)
+
+	// text-only inputs keep their default serialization
+	inputs, err = h.runInputs(info, &model.CallbackInput{Messages: []*schema.Message{schema.UserMessage("hi")}})
+	require.NoError(t, err)
+	assert.Contains(t, inputs, "input")
+	assert.Nil(t, multimodalInput(&callbacks.RunInfo{Component: components.ComponentOfTool}, input))
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"sync"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/compose"
+)
+
+// NodeMetadata holds static tags and metadata of a graph node, merged into the runs of the node.
+type NodeMetadata struct {
+	Tags     []string
+	Metadata map[string]interface{}
+}
+
+// nodeMetadataRegistry holds the NodeMetadata registered by WithNodeMetadata, by node name.
+var nodeMetadataRegistry sync.Map
+
+// WithNodeMetadata names a graph node like compose.WithNodeName, and attaches static tags and metadata to its runs
+// when the graph is built:
+//
+//	graph.AddChatModelNode("model", cm, langsmith.WithNodeMetadata("planner", langsmith.NodeMetadata{
+//		Tags:     []string{"planning"},
+//		Metadata: map[string]interface{}{"prompt_version": "v3"},
+//	}))
+//
+// Runs are matched by node name, so the name should be unique among the graphs of the process. Config.NodeMetadata
+// takes precedence for a handler.
+func WithNodeMetadata(name string, md NodeMetadata) compose.GraphAddNodeOpt {
+	nodeMetadataRegistry.Store(name, &md)
+	return compose.WithNodeName(name)
+}
+
+// nodeMetadata returns the static metadata of the node of info, nil if there's none.
+func (c *Config) nodeMetadata(info *callbacks.RunInfo) *NodeMetadata {
+	if info.Name == "" {
+		return nil
+	}
+	if md, ok := c.NodeMetadata[info.Name]; ok {
+		return &md
+	}
+	md, _ := nodeMetadataRegistry.Load(info.Name)
+	m, _ := md.(*NodeMetadata)
+	return m
+}
+
+// apply merges md into the extra and tags of a run, tags are copied since they may be shared by runs.
+func (md *NodeMetadata) apply(extra map[string]interface{}, tags []string) []string {
+	if md == nil {
+		return tags
+	}
+	for k, v := range md.Metadata {
+		setRunMetadata(extra, k, v)
+	}
+	if len(md.Tags) == 0 {
+		return tags
+	}
+	merged := make([]string, 0, len(tags)+len(md.Tags))
+	merged = append(merged, tags...)
+	for _, tag := range md.Tags {
+		if !containsString(merged, tag) {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
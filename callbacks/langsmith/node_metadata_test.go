@@ -0,0 +1,74 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNodeMetadata 测试构图时通过节点选项附加的静态元数据与标签
+func TestNodeMetadata(t *testing.T) {
+	mCli := new(mockLangsmith)
+	var mu sync.Mutex
+	runs := map[string]*Run{}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		run := args.Get(1).(*Run)
+		runs[run.Name] = run
+	}).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	h, err := NewLangsmithHandler(&Config{Exporter: mCli, Blocking: true, NodeMetadata: map[string]NodeMetadata{
+		"formatter": {Metadata: map[string]interface{}{"owner": "config"}},
+	}})
+	require.NoError(t, err)
+
+	echo := func(ctx context.Context, in string) (string, error) { return in, nil }
+	g := compose.NewGraph[string, string]()
+	require.NoError(t, g.AddLambdaNode("plan", compose.InvokableLambda(echo), WithNodeMetadata("test_planner", NodeMetadata{
+		Tags:     []string{"planning"},
+		Metadata: map[string]interface{}{"prompt_version": "v3"},
+	})))
+	require.NoError(t, g.AddLambdaNode("format", compose.InvokableLambda(echo), WithNodeMetadata("formatter", NodeMetadata{
+		Metadata: map[string]interface{}{"owner": "option"},
+	})))
+	require.NoError(t, g.AddEdge(compose.START, "plan"))
+	require.NoError(t, g.AddEdge("plan", "format"))
+	require.NoError(t, g.AddEdge("format", compose.END))
+	r, err := g.Compile(context.Background())
+	require.NoError(t, err)
+
+	ctx := SetTrace(context.Background(), AddTag("trace"))
+	_, err = r.Invoke(ctx, "in", compose.WithCallbacks(h))
+	require.NoError(t, err)
+
+	planner := runs["test_planner"]
+	require.NotNil(t, planner)
+	assert.Equal(t, []string{"trace", "planning"}, planner.Tags)
+	assert.Equal(t, "v3", planner.Extra["metadata"].(map[string]interface{})["prompt_version"])
+	// Config.NodeMetadata takes precedence
+	assert.Equal(t, "config", runs["formatter"].Extra["metadata"].(map[string]interface{})["owner"])
+	// the tags of the trace aren't modified
+	assert.Equal(t, []string{"trace"}, runs["formatter"].Tags)
+}
@@ -0,0 +1,38 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"github.com/cloudwego/eino/callbacks"
+)
+
+// MetadataNodePath is the metadata key of the path of a run in the graph hierarchy: the names of the traced runs from
+// the root of the trace down to the run, separated by NodePathSeparator, e.g. "agent/tools/ChatModel".
+const MetadataNodePath = "node_path"
+
+// NodePathSeparator separates the names of the runs in a node path.
+const NodePathSeparator = "/"
+
+// nodePath returns the node path of a run of info under state. Unnamed components are named after their
+// implementation and component kind, see RunInfo, runs dropped by Config.Filter aren't part of the path.
+func nodePath(state *LangsmithState, info *callbacks.RunInfo) string {
+	name := runInfoToName(info)
+	if state == nil || state.path == "" {
+		return name
+	}
+	return state.path + NodePathSeparator + name
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNodePath 测试子图中的运行记录节点路径，并可按路径命名
+func TestNodePath(t *testing.T) {
+	for _, pathNames := range []bool{false, true} {
+		mCli := new(mockLangsmith)
+		var runs []*Run
+		mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			runs = append(runs, args.Get(1).(*Run))
+		}).Return(nil)
+		h, err := NewLangsmithHandler(&Config{Exporter: mCli, Blocking: true, NodePathRunNames: pathNames,
+			Filter: &RunFilter{DenyComponents: []components.Component{components.ComponentOfPrompt}}})
+		require.NoError(t, err)
+
+		ctx := h.OnStart(context.Background(), &callbacks.RunInfo{Name: "agent", Component: "Graph"}, "input")
+		ctx = h.OnStart(ctx, &callbacks.RunInfo{Name: "planner", Component: "Graph"}, "input")
+		// filtered out components aren't part of the path
+		ctx = h.OnStart(ctx, &callbacks.RunInfo{Type: "Default", Component: components.ComponentOfPrompt}, "input")
+		h.OnStart(ctx, &callbacks.RunInfo{Type: "OpenAI", Component: components.ComponentOfChatModel}, "input")
+
+		require.Len(t, runs, 3)
+		paths := make([]interface{}, 0, len(runs))
+		names := make([]string, 0, len(runs))
+		for _, run := range runs {
+			paths = append(paths, run.Extra["metadata"].(map[string]interface{})[MetadataNodePath])
+			names = append(names, run.Name)
+		}
+		assert.Equal(t, []interface{}{"agent", "agent/planner", "agent/planner/OpenAIChatModel"}, paths)
+		if pathNames {
+			assert.Equal(t, []string{"agent", "agent/planner", "agent/planner/OpenAIChatModel"}, names)
+		} else {
+			assert.Equal(t, []string{"agent", "planner", "OpenAIChatModel"}, names)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+
+	"github.com/cloudwego/eino/callbacks"
+)
+
+// HandlerOption configures the handler created by NewHandler.
+type HandlerOption func(*Config)
+
+// NewHandler creates a CallbackHandler for apiKey configured by opts, e.g.
+//
+//	handler, err := langsmith.NewHandler(apiKey,
+//		langsmith.HandlerWithProject("my-app"), langsmith.HandlerWithQueueSize(4096))
+//
+// Options keep working as Config grows, NewLangsmithHandler takes the full Config.
+func NewHandler(apiKey string, opts ...HandlerOption) (*CallbackHandler, error) {
+	cfg := &Config{APIKey: apiKey}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return NewLangsmithHandler(cfg)
+}
+
+// HandlerWithEndpoint sets the langsmith api url, see Config.APIURL.
+func HandlerWithEndpoint(apiURL string) HandlerOption {
+	return func(cfg *Config) {
+		cfg.APIURL = apiURL
+	}
+}
+
+// HandlerWithIngestEndpoint sets the url runs are written to, see Config.IngestURL.
+func HandlerWithIngestEndpoint(ingestURL string) HandlerOption {
+	return func(cfg *Config) {
+		cfg.IngestURL = ingestURL
+	}
+}
+
+// HandlerWithWorkspace sets the workspace of all requests, see Config.WorkspaceID.
+func HandlerWithWorkspace(workspaceID string) HandlerOption {
+	return func(cfg *Config) {
+		cfg.WorkspaceID = workspaceID
+	}
+}
+
+// HandlerWithProject sets the default langsmith project, see Config.SessionName.
+func HandlerWithProject(name string) HandlerOption {
+	return func(cfg *Config) {
+		cfg.SessionName = name
+	}
+}
+
+// HandlerWithSampler sets the sampler deciding which traces are exported, see Config.Sampler.
+func HandlerWithSampler(sampler Sampler) HandlerOption {
+	return func(cfg *Config) {
+		cfg.Sampler = sampler
+	}
+}
+
+// HandlerWithQueueSize sets the capacity of the async export queue, see Config.QueueSize.
+func HandlerWithQueueSize(size int) HandlerOption {
+	return func(cfg *Config) {
+		cfg.QueueSize = size
+	}
+}
+
+// HandlerWithLogger sets the logger of the handler and its client, see Config.Logger.
+func HandlerWithLogger(logger Logger) HandlerOption {
+	return func(cfg *Config) {
+		cfg.Logger = logger
+	}
+}
+
+// HandlerWithSerializer sets the serializer of run inputs and outputs, see Config.Serializer.
+func HandlerWithSerializer(serializer Serializer) HandlerOption {
+	return func(cfg *Config) {
+		cfg.Serializer = serializer
+	}
+}
+
+// HandlerWithConfig applies fn to the Config, for the fields without a dedicated option.
+func HandlerWithConfig(fn func(cfg *Config)) HandlerOption {
+	return HandlerOption(fn)
+}
+
+// Sampler decides whether the trace started by the root run of info is exported. A sampled out trace creates no run
+// at all, its nested components included.
+type Sampler func(ctx context.Context, info *callbacks.RunInfo) bool
+
+// NewRatioSampler returns a Sampler exporting about ratio of the traces, 0 drops every trace and 1 keeps them all.
+func NewRatioSampler(ratio float64) Sampler {
+	var mu sync.Mutex
+	rnd := rand.New(rand.NewSource(rand.Int63()))
+	return func(ctx context.Context, info *callbacks.RunInfo) bool {
+		if ratio >= 1 {
+			return true
+		}
+		if ratio <= 0 {
+			return false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return rnd.Float64() < ratio
+	}
+}
+
+// sampled reports whether the trace starting with the run of info is exported. Runs continuing a trace, locally or
+// from a remote parent, follow the decision taken at its root.
+func (c *Config) sampled(ctx context.Context, info *callbacks.RunInfo, state *LangsmithState) bool {
+	return c.Sampler == nil || state.ParentRunID != "" || c.Sampler(ctx, info)
+}
+
+// sampleOut returns a context dropping the trace: the root run and all its descendants are skipped.
+func sampleOut(ctx context.Context, state *LangsmithState) context.Context {
+	sampledOut := *state
+	sampledOut.skipped = true
+	sampledOut.sampledOut = true
+	sampledOut.loop = nil
+	sampledOut.agents = nil
+	return context.WithValue(ctx, langsmithStateKey{}, &sampledOut)
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/compose"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHandlerOptions(t *testing.T) {
+	logger := &recordLogger{}
+	serializer := sonicSerializer{}
+	h, err := NewHandler("key",
+		HandlerWithEndpoint("http://localhost:1984"),
+		HandlerWithProject("my-app"),
+		HandlerWithQueueSize(16),
+		HandlerWithLogger(logger),
+		HandlerWithSerializer(serializer),
+		HandlerWithSampler(NewRatioSampler(1)),
+		HandlerWithConfig(func(cfg *Config) { cfg.Blocking = true }),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "key", h.cfg.APIKey)
+	assert.Equal(t, "http://localhost:1984", h.cfg.APIURL)
+	assert.Equal(t, "my-app", h.cfg.SessionName)
+	assert.Equal(t, 16, h.cfg.QueueSize)
+	assert.Same(t, logger, h.cfg.Logger)
+	assert.Equal(t, serializer, h.cfg.Serializer)
+	assert.NotNil(t, h.cfg.Sampler)
+	assert.True(t, h.cfg.Blocking)
+	assert.Nil(t, h.async)
+	assert.NotNil(t, h.cfg.RunIDGen)
+}
+
+func TestRatioSampler(t *testing.T) {
+	info := &callbacks.RunInfo{Name: "graph"}
+	ctx := context.Background()
+	assert.True(t, NewRatioSampler(1)(ctx, info))
+	assert.False(t, NewRatioSampler(0)(ctx, info))
+
+	sampler := NewRatioSampler(0.5)
+	kept := 0
+	for i := 0; i < 1000; i++ {
+		if sampler(ctx, info) {
+			kept++
+		}
+	}
+	assert.InDelta(t, 500, kept, 100)
+}
+
+// TestSampledOutTrace 测试被采样丢弃的 trace 不创建任何 run，且只在根节点采样一次
+func TestSampledOutTrace(t *testing.T) {
+	mCli := &mockLangsmith{}
+	calls := 0
+	h := &CallbackHandler{cli: mCli, cfg: &Config{
+		RunIDGen: func(ctx context.Context) string { return uuid.NewString() },
+		Sampler: func(ctx context.Context, info *callbacks.RunInfo) bool {
+			calls++
+			return info.Name == "kept"
+		},
+	}}
+	var runs []*Run
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		runs = append(runs, args.Get(1).(*Run))
+	}).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	graph := &callbacks.RunInfo{Name: "dropped", Component: compose.ComponentOfGraph}
+	tool := &callbacks.RunInfo{Name: "kept", Component: components.ComponentOfTool}
+	graphCtx := h.OnStart(context.Background(), graph, "in")
+	toolCtx := h.OnStart(graphCtx, tool, "in")
+	h.OnEnd(toolCtx, tool, "out")
+	h.OnEnd(graphCtx, graph, "out")
+	assert.Empty(t, runs)
+	assert.Equal(t, 1, calls)
+	mCli.AssertNotCalled(t, "UpdateRun", mock.Anything, mock.Anything, mock.Anything)
+
+	kept := &callbacks.RunInfo{Name: "kept", Component: compose.ComponentOfGraph}
+	keptCtx := h.OnStart(context.Background(), kept, "in")
+	toolCtx = h.OnStart(keptCtx, &callbacks.RunInfo{Name: "dropped", Component: components.ComponentOfTool}, "in")
+	h.OnEnd(toolCtx, &callbacks.RunInfo{Name: "dropped", Component: components.ComponentOfTool}, "out")
+	h.OnEnd(keptCtx, kept, "out")
+	require.Len(t, runs, 2)
+	assert.Equal(t, 2, calls)
+}
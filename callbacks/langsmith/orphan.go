@@ -0,0 +1,137 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"io"
+	"runtime/debug"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/schema"
+)
+
+// TagRecoveredOrphan tags the runs created for components that ended without a langsmith state in their context, e.g.
+// when a custom component didn't pass on the context of its start callback. They hold the output or error only.
+const TagRecoveredOrphan = "recovered_orphan"
+
+// orphanRun returns a standalone run for a component that ended without state: its start is unknown, so it starts
+// and ends now. Like a root run, it joins the trace set by WithTraceID if any. nil means the component is filtered out.
+func (c *CallbackHandler) orphanRun(ctx context.Context, info *callbacks.RunInfo) *Run {
+	if !c.cfg.Filter.traced(info) {
+		return nil
+	}
+	opts, _ := ctx.Value(langsmithTraceOptionKey{}).(*traceOptions)
+	if opts == nil {
+		opts = &traceOptions{}
+	}
+	_, state := GetOrInitState(ctx)
+	runID := c.cfg.RunIDGen(ctx)
+	path := nodePath(state, info)
+	startTime, _ := runStartTime(state.ParentDottedOrder)
+	run := &Run{
+		ID:          runID,
+		TraceID:     state.TraceID,
+		Name:        c.cfg.runName(ctx, info, path),
+		RunType:     runInfoToRunType(info),
+		StartTime:   startTime,
+		EndTime:     &startTime,
+		Inputs:      map[string]interface{}{},
+		SessionName: c.cfg.sessionName(opts),
+		Extra:       SafeDeepCopySyncMapMetadata(opts.Metadata),
+		Tags:        append(append([]string(nil), opts.Tags...), TagRecoveredOrphan),
+		ParentRunID: state.parentRunID(),
+	}
+	if run.TraceID == "" {
+		run.TraceID = runID
+	}
+	run.ReferenceExampleID = c.cfg.referenceExampleID(opts, state)
+	run.DottedOrder = dottedOrder(state.ParentDottedOrder, run.StartTime, runID)
+	setRunMetadata(run.Extra, MetadataNodePath, path)
+	return run
+}
+
+// recoverOrphanEnd creates a run holding the output of a component that ended without state.
+func (c *CallbackHandler) recoverOrphanEnd(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) {
+	run := c.orphanRun(ctx, info)
+	if run == nil {
+		return
+	}
+	outputs, err := c.runOutputs(info, output)
+	if err != nil {
+		c.cfg.logger().Error(ctx, "marshal output error", "err", err, "run_info", info)
+		return
+	}
+	run.Outputs = outputs
+	if usage := modelUsage(info, output); usage != nil {
+		c.cfg.reportUsage(run.Extra, usage)
+	}
+	c.createRun(ctx, run)
+}
+
+// recoverOrphanError creates a run holding the error of a component that failed without state.
+func (c *CallbackHandler) recoverOrphanError(ctx context.Context, info *callbacks.RunInfo, err error) {
+	run := c.orphanRun(ctx, info)
+	if run == nil {
+		return
+	}
+	details, errStr := c.cfg.errorDetails(err)
+	run.Error = &errStr
+	run.Extra[ExtraErrorDetails] = details
+	c.createRun(ctx, run)
+}
+
+// recoverOrphanStream creates a run holding the output chunks of a component that streamed its output without state.
+func (c *CallbackHandler) recoverOrphanStream(ctx context.Context, info *callbacks.RunInfo, output *schema.StreamReader[callbacks.CallbackOutput]) {
+	run := c.orphanRun(ctx, info)
+	if run == nil {
+		output.Close()
+		return
+	}
+	state := &LangsmithState{startTime: run.StartTime, started: time.Now()}
+	c.consumeStream(ctx, info, func(dropped bool) {
+		defer func() {
+			if r := recover(); r != nil {
+				c.cfg.logger().Error(ctx, "recovered in OnEndWithStreamOutput", "panic", r, "stack", string(debug.Stack()))
+			}
+			output.Close()
+		}()
+		var chunks []callbacks.CallbackOutput
+		for !dropped {
+			chunk, err := output.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				c.cfg.logger().Error(ctx, "error receiving stream output", "err", err)
+				break
+			}
+			chunks = append(chunks, chunk)
+		}
+		if dropped {
+			run.Outputs = map[string]interface{}{"stream_outputs": StreamDroppedPlaceholder}
+		} else if c.cfg.HideOutputs {
+			run.Outputs = map[string]interface{}{"stream_outputs": HiddenPlaceholder}
+		} else {
+			run.Outputs = map[string]interface{}{"stream_outputs": limitPayload(chunks, c.cfg.MaxOutputBytes)}
+		}
+		endTime := state.now()
+		run.EndTime = &endTime
+		c.createRun(ctx, run)
+	})
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOrphanRuns 测试丢失 state 时结束回调仍创建带 recovered_orphan 标签的独立 run
+func TestOrphanRuns(t *testing.T) {
+	mCli := &mockLangsmith{}
+	h := &CallbackHandler{cli: mCli, cfg: &Config{
+		RunIDGen: func(ctx context.Context) string { return uuid.NewString() },
+		Filter:   &RunFilter{DenyComponents: []components.Component{compose.ComponentOfLambda}},
+	}}
+	runs := make(chan *Run, 4)
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		runs <- args.Get(1).(*Run)
+	}).Return(nil)
+
+	traceID := uuid.NewString()
+	ctx := SetTrace(context.Background(), WithTraceID(traceID), AddTag("prod"), WithSessionName("orphans"))
+	h.OnEnd(ctx, &callbacks.RunInfo{Name: "custom", Component: components.ComponentOfTool}, "out")
+	run := <-runs
+	assert.Equal(t, "custom", run.Name)
+	assert.Equal(t, traceID, run.TraceID)
+	assert.Nil(t, run.ParentRunID)
+	assert.Equal(t, []string{"prod", TagRecoveredOrphan}, run.Tags)
+	assert.Equal(t, "orphans", run.SessionName)
+	assert.Equal(t, "out", run.Outputs["output"])
+	require.NotNil(t, run.EndTime)
+	assert.Equal(t, run.StartTime, *run.EndTime)
+
+	h.OnError(context.Background(), &callbacks.RunInfo{Name: "failed", Component: components.ComponentOfTool}, errors.New("boom"))
+	run = <-runs
+	assert.Equal(t, run.ID, run.TraceID)
+	require.NotNil(t, run.Error)
+	assert.Equal(t, "boom", *run.Error)
+	assert.Equal(t, []string{TagRecoveredOrphan}, run.Tags)
+
+	sr, sw := schema.Pipe[callbacks.CallbackOutput](2)
+	sw.Send("a", nil)
+	sw.Send("b", nil)
+	sw.Close()
+	h.OnEndWithStreamOutput(context.Background(), &callbacks.RunInfo{Name: "streamed", Component: components.ComponentOfTool}, sr)
+	select {
+	case run = <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("stream orphan run not created")
+	}
+	assert.Equal(t, "streamed", run.Name)
+	assert.Equal(t, []callbacks.CallbackOutput{"a", "b"}, run.Outputs["stream_outputs"])
+	assert.False(t, run.EndTime.Before(run.StartTime))
+
+	// filtered out components stay untraced
+	h.OnEnd(context.Background(), &callbacks.RunInfo{Name: "lambda", Component: compose.ComponentOfLambda}, "out")
+	assert.Empty(t, runs)
+}
@@ -0,0 +1,249 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/compose"
+)
+
+// TagPanic tags the runs the handler terminated because their component never reported its end: eino recovers the
+// panic of a node and fails the enclosing graph with it, but doesn't call the end callbacks of the node.
+const TagPanic = "panic"
+
+// panicErrorMarker starts the message of the errors eino converts recovered panics into, see the Error method of the
+// panicErr of github.com/cloudwego/eino/internal/safe, which is internal so the error can only be matched by message.
+const panicErrorMarker = "panic error: "
+
+// TagAbandoned tags the runs the handler terminated because they didn't end within Config.PendingRunTTL or before
+// Shutdown, e.g. when the request crashed or a panic was recovered by the application instead of eino.
+const TagAbandoned = "abandoned"
+
+var (
+	errAbandonedAtShutdown = errors.New("abandoned: run not ended before shutdown")
+	errAbandonedAfterTTL   = errors.New("abandoned: run not ended within the pending run ttl")
+)
+
+// pendingRun is a run created by the handler whose end isn't reported yet.
+type pendingRun struct {
+	id       string
+	parentID string
+	rootID   string          // outermost pending run the run is nested in, the run itself if none
+	state    *LangsmithState // state of the run, for its clock, events and metadata
+	added    time.Time
+}
+
+// pendingRuns tracks the runs created by the handlers of a Client until they end, so that the runs of panicked
+// components or crashed requests can be terminated instead of staying pending in langsmith forever. Without a ttl,
+// only the runs of graphs and their nested runs are tracked, for the panics eino recovers in graphs, and the runs
+// nested in a graph are released when it ends. Its methods are safe to call on nil.
+type pendingRuns struct {
+	mu   sync.Mutex
+	runs map[string]*pendingRun
+	ttl  time.Duration
+
+	stop     chan struct{} // stops the reaper
+	stopOnce sync.Once
+}
+
+func newPendingRuns(ttl time.Duration) *pendingRuns {
+	return &pendingRuns{runs: map[string]*pendingRun{}, ttl: ttl, stop: make(chan struct{})}
+}
+
+// add tracks the run of state, graph tells whether it's the run of a graph.
+func (p *pendingRuns) add(state *LangsmithState, parentID string, graph bool) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	parent := p.runs[parentID]
+	if p.ttl <= 0 && !graph && parent == nil {
+		return
+	}
+	run := &pendingRun{id: state.ParentRunID, parentID: parentID, rootID: state.ParentRunID, state: state, added: time.Now()}
+	if parent != nil {
+		run.rootID = parent.rootID
+	}
+	p.runs[run.id] = run
+}
+
+func (p *pendingRuns) done(runID string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	run := p.runs[runID]
+	delete(p.runs, runID)
+	if p.ttl <= 0 && run != nil && run.rootID == runID {
+		// nothing terminates the runs left in an ended graph without a ttl but Shutdown, don't hold them until then
+		for id, nested := range p.runs {
+			if nested.rootID == runID {
+				delete(p.runs, id)
+			}
+		}
+	}
+}
+
+// removeDescendants removes and returns the pending runs nested in runID.
+func (p *pendingRuns) removeDescendants(runID string) []*pendingRun {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	nested := func(run *pendingRun) bool {
+		for depth := 0; run != nil && depth <= len(p.runs); depth++ {
+			if run.parentID == runID {
+				return true
+			}
+			run = p.runs[run.parentID]
+		}
+		return false
+	}
+	var descendants []*pendingRun
+	for _, run := range p.runs {
+		if nested(run) {
+			descendants = append(descendants, run)
+		}
+	}
+	for _, run := range descendants {
+		delete(p.runs, run.id)
+	}
+	return descendants
+}
+
+// removeAll removes and returns all the pending runs.
+func (p *pendingRuns) removeAll() []*pendingRun {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	all := make([]*pendingRun, 0, len(p.runs))
+	for _, run := range p.runs {
+		all = append(all, run)
+	}
+	p.runs = map[string]*pendingRun{}
+	return all
+}
+
+// removeExpired removes and returns the runs pending for longer than ttl.
+func (p *pendingRuns) removeExpired(ttl time.Duration) []*pendingRun {
+	if p == nil || ttl <= 0 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var expired []*pendingRun
+	for id, run := range p.runs {
+		if time.Since(run.added) > ttl {
+			expired = append(expired, run)
+			delete(p.runs, id)
+		}
+	}
+	return expired
+}
+
+// reap terminates the runs pending for longer than ttl every half ttl, until stopReaper is called.
+func (p *pendingRuns) reap(ttl time.Duration, terminate func(runs []*pendingRun)) {
+	ticker := time.NewTicker(ttl / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if runs := p.removeExpired(ttl); len(runs) > 0 {
+				terminate(runs)
+			}
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *pendingRuns) stopReaper() {
+	if p == nil {
+		return
+	}
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+// isGraphComponent reports whether component is the one of a graph, chain or workflow.
+func isGraphComponent(component components.Component) bool {
+	switch component {
+	case compose.ComponentOfGraph, compose.ComponentOfChain, compose.ComponentOfWorkflow:
+		return true
+	}
+	return false
+}
+
+// isPanicError reports whether err is, or wraps, a panic recovered by eino, TestPanicErrorMarker pins the marker
+// against the error of the eino version in go.mod.
+func isPanicError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), panicErrorMarker)
+}
+
+// terminateRuns ends runs with err, tagged with tag.
+func (c *CallbackHandler) terminateRuns(ctx context.Context, runs []*pendingRun, err error, tag string) {
+	for _, run := range runs {
+		state := run.state
+		endTime := state.now()
+		details, errStr := c.cfg.errorDetails(err)
+		patch := &RunPatch{
+			EndTime: &endTime,
+			Error:   &errStr,
+			Events:  state.events.drain(),
+			Extra:   SafeDeepCopySyncMapMetadata(state.Metadata),
+		}
+		patch.Extra[ExtraErrorDetails] = details
+		state.annotations.apply(patch)
+		if patch.Tags == nil {
+			patch.Tags = append([]string(nil), state.Tags...)
+		}
+		patch.Tags = append(patch.Tags, tag)
+		c.updateRun(ctx, run.id, patch)
+	}
+}
+
+// startReaper terminates the runs pending for longer than Config.PendingRunTTL in the background.
+func (c *CallbackHandler) startReaper() {
+	ttl := c.cfg.PendingRunTTL
+	if ttl <= 0 || c.pending == nil {
+		return
+	}
+	go c.pending.reap(ttl, func(runs []*pendingRun) {
+		c.terminateRuns(context.Background(), runs, errAbandonedAfterTTL, TagAbandoned)
+	})
+}
+
+// reapExpired terminates the runs pending for longer than Config.PendingRunTTL, see Flush.
+func (c *CallbackHandler) reapExpired(ctx context.Context) {
+	c.terminateRuns(ctx, c.pending.removeExpired(c.cfg.PendingRunTTL), errAbandonedAfterTTL, TagAbandoned)
+}
+
+// reapAll stops the reaper and terminates all the pending runs, see Shutdown.
+func (c *CallbackHandler) reapAll(ctx context.Context) {
+	c.pending.stopReaper()
+	c.terminateRuns(ctx, c.pending.removeAll(), errAbandonedAtShutdown, TagAbandoned)
+}
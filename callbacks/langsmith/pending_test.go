@@ -0,0 +1,210 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingRunsDescendants(t *testing.T) {
+	p := newPendingRuns(time.Minute)
+	for _, r := range [][2]string{{"graph", ""}, {"node", "graph"}, {"tool", "node"}, {"other", ""}} {
+		p.add(&LangsmithState{ParentRunID: r[0]}, r[1], r[0] == "graph")
+	}
+	ids := func(runs []*pendingRun) []string {
+		var ids []string
+		for _, r := range runs {
+			ids = append(ids, r.id)
+		}
+		return ids
+	}
+	p.done("graph")
+	assert.ElementsMatch(t, []string{"node", "tool"}, ids(p.removeDescendants("graph")))
+	assert.Empty(t, p.removeDescendants("graph"))
+	assert.Equal(t, []string{"other"}, ids(p.removeAll()))
+
+	var nilRuns *pendingRuns
+	nilRuns.add(&LangsmithState{ParentRunID: "a"}, "", false)
+	nilRuns.done("a")
+	assert.Nil(t, nilRuns.removeAll())
+}
+
+// TestPendingRunsWithoutTTL 测试未设置 PendingRunTTL 时只跟踪图内的 run，且图结束时释放其未结束的子 run
+func TestPendingRunsWithoutTTL(t *testing.T) {
+	p := newPendingRuns(0)
+	p.add(&LangsmithState{ParentRunID: "tool"}, "", false)
+	p.add(&LangsmithState{ParentRunID: "graph"}, "", true)
+	p.add(&LangsmithState{ParentRunID: "node"}, "graph", false)
+	p.add(&LangsmithState{ParentRunID: "lost"}, "node", false)
+	p.done("node")
+	assert.Len(t, p.runs, 2, "runs outside graphs aren't tracked")
+	p.done("graph")
+	assert.Empty(t, p.runs, "the runs left in an ended graph are released")
+}
+
+// TestPanicErrorMarker 测试 panicErrorMarker 与 eino 恢复 panic 后返回的错误一致
+func TestPanicErrorMarker(t *testing.T) {
+	r, err := compose.NewChain[string, string]().
+		AppendLambda(compose.InvokableLambda(func(ctx context.Context, in string) (string, error) {
+			panic("boom")
+		})).
+		Compile(context.Background())
+	require.NoError(t, err)
+	_, err = r.Invoke(context.Background(), "in")
+	require.Error(t, err)
+	assert.True(t, isPanicError(err), "eino's recovered panic error no longer matches panicErrorMarker: %v", err)
+	assert.False(t, isPanicError(errors.New("boom")))
+	assert.False(t, isPanicError(nil))
+}
+
+// TestPanicTerminatesRuns 测试节点 panic 后其 run 被以错误结束，并带有 panic 标签
+func TestPanicTerminatesRuns(t *testing.T) {
+	mCli := &mockLangsmith{}
+	h := &CallbackHandler{cli: mCli, cfg: &Config{RunIDGen: DefaultRunIDGen}, pending: newPendingRuns(0)}
+	var mu sync.Mutex
+	runs := map[string]*Run{}
+	patches := map[string]*RunPatch{}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		run := args.Get(1).(*Run)
+		runs[run.Name] = run
+	}).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		patches[args.String(1)] = args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	g := compose.NewGraph[string, string]()
+	require.NoError(t, g.AddLambdaNode("explode", compose.InvokableLambda(func(ctx context.Context, in string) (string, error) {
+		panic("boom")
+	}), compose.WithNodeName("explode")))
+	require.NoError(t, g.AddEdge(compose.START, "explode"))
+	require.NoError(t, g.AddEdge("explode", compose.END))
+	r, err := g.Compile(context.Background(), compose.WithGraphName("graph"))
+	require.NoError(t, err)
+	_, err = r.Invoke(context.Background(), "in", compose.WithCallbacks(h))
+	require.Error(t, err)
+
+	node := runs["explode"]
+	require.NotNil(t, node)
+	patch := patches[node.ID]
+	require.NotNil(t, patch, "the run of the panicked node is terminated")
+	require.NotNil(t, patch.Error)
+	assert.Contains(t, *patch.Error, "panic error: boom")
+	assert.Contains(t, patch.Tags, TagPanic)
+	assert.NotNil(t, patch.EndTime)
+	assert.Contains(t, *patches[runs["graph"].ID].Error, "panic error: boom")
+	assert.Empty(t, h.pending.removeAll())
+}
+
+// TestShutdownTerminatesPending 测试 Shutdown 时未结束的 run 以 abandoned 错误结束
+func TestShutdownTerminatesPending(t *testing.T) {
+	mCli := &mockLangsmith{}
+	cfg := &Config{RunIDGen: DefaultRunIDGen, PendingRunTTL: time.Hour}
+	h := &CallbackHandler{cli: mCli, cfg: cfg, pending: newPendingRuns(cfg.PendingRunTTL)}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	var patch *RunPatch
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		patch = args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	ctx := h.OnStart(SetTrace(context.Background(), AddTag("prod")), &callbacks.RunInfo{Name: "tool", Component: components.ComponentOfTool}, "in")
+	_, state := GetState(ctx)
+	require.NoError(t, h.Shutdown(context.Background()))
+	mCli.AssertCalled(t, "UpdateRun", mock.Anything, state.ParentRunID, mock.Anything)
+	assert.Equal(t, errAbandonedAtShutdown.Error(), *patch.Error)
+	assert.Equal(t, []string{"prod", TagAbandoned}, patch.Tags)
+
+	// ended runs are not terminated again
+	patch = nil
+	info := &callbacks.RunInfo{Name: "tool", Component: components.ComponentOfTool}
+	h.OnEnd(h.OnStart(context.Background(), info, "in"), info, "out")
+	patch = nil
+	require.NoError(t, h.Shutdown(context.Background()))
+	assert.Nil(t, patch)
+}
+
+// TestPendingRunTTL 测试超过 PendingRunTTL 未结束的 run 被后台和 Flush 以 abandoned 错误结束
+func TestPendingRunTTL(t *testing.T) {
+	mCli := &mockLangsmith{}
+	cfg := &Config{RunIDGen: DefaultRunIDGen, PendingRunTTL: 20 * time.Millisecond}
+	h := &CallbackHandler{cli: mCli, cfg: cfg, pending: newPendingRuns(cfg.PendingRunTTL)}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	ended := make(chan *RunPatch, 2)
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		ended <- args.Get(2).(*RunPatch)
+	}).Return(nil)
+	info := &callbacks.RunInfo{Name: "tool", Component: components.ComponentOfTool}
+
+	h.OnStart(context.Background(), info, "in")
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, h.Flush(context.Background()))
+	patch := <-ended
+	assert.Equal(t, errAbandonedAfterTTL.Error(), *patch.Error)
+	assert.Equal(t, []string{TagAbandoned}, patch.Tags)
+
+	// a fresh run isn't reaped by Flush
+	h.OnStart(context.Background(), info, "in")
+	require.NoError(t, h.Flush(context.Background()))
+	assert.Empty(t, ended)
+
+	h.startReaper()
+	defer h.pending.stopReaper()
+	select {
+	case patch = <-ended:
+		assert.Equal(t, errAbandonedAfterTTL.Error(), *patch.Error)
+	case <-time.After(time.Second):
+		t.Fatal("expired run not reaped")
+	}
+}
+
+// TestEndsRunOnMarshalError 测试输出序列化失败时 run 仍以占位输出结束，且不再被跟踪
+func TestEndsRunOnMarshalError(t *testing.T) {
+	mCli := &mockLangsmith{}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	cfg := &Config{RunIDGen: DefaultRunIDGen, PendingRunTTL: time.Hour}
+	cfg.Serializer = SerializerFunc(func(info *callbacks.RunInfo, v interface{}) (string, error) {
+		if v == "out" {
+			return "", errors.New("unserializable")
+		}
+		return "", nil
+	})
+	h := &CallbackHandler{cli: mCli, cfg: cfg, pending: newPendingRuns(cfg.PendingRunTTL)}
+	var patch *RunPatch
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		patch = args.Get(2).(*RunPatch)
+	}).Return(nil)
+	info := &callbacks.RunInfo{Name: "lambda", Component: compose.ComponentOfLambda}
+	h.OnEnd(h.OnStart(context.Background(), info, "in"), info, "out")
+	assert.Empty(t, h.pending.removeAll())
+	require.NotNil(t, patch, "the run is ended despite the error")
+	assert.NotNil(t, patch.EndTime)
+	assert.Equal(t, map[string]interface{}{"output": UnserializablePlaceholder, "serialize_error": "unserializable"}, patch.Outputs)
+}
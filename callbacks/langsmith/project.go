@@ -0,0 +1,188 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const opProject APIOp = "project"
+
+// Project is a langsmith project, called tracer session in the API. Runs are grouped by the project named in
+// their session_name.
+type Project struct {
+	ID                 string                 `json:"id,omitempty"`
+	Name               string                 `json:"name"`
+	Description        string                 `json:"description,omitempty"`
+	Extra              map[string]interface{} `json:"extra,omitempty"`                // project metadata, e.g. {"metadata": {...}}
+	ReferenceDatasetID string                 `json:"reference_dataset_id,omitempty"` // set for experiments over a dataset
+	StartTime          *time.Time             `json:"start_time,omitempty"`
+	TenantID           string                 `json:"tenant_id,omitempty"`
+}
+
+// ProjectDefaults are the description and metadata of the projects created by Config.AutoCreateProject.
+type ProjectDefaults struct {
+	Description string
+	Metadata    map[string]interface{}
+}
+
+// ListProjectsOptions filters and paginates ListProjects.
+type ListProjectsOptions struct {
+	NameContains       string
+	ReferenceDatasetID string // only experiments of this dataset
+	Offset             int
+	Limit              int // default: server side default (100)
+}
+
+// projectKey identifies a project in the caches: tenants using WithAPIKey or WithWorkspaceID may own projects of the
+// same name.
+type projectKey struct {
+	apiKey      string
+	workspaceID string
+	name        string
+}
+
+// newProjectKey returns the key of the project name as seen by the requests made with ctx.
+func newProjectKey(ctx context.Context, name string) projectKey {
+	apiKey, workspaceID := requestCredentials(ctx)
+	return projectKey{apiKey: apiKey, workspaceID: workspaceID, name: name}
+}
+
+// ProjectClient manages projects, it's implemented by the client returned by NewLangsmith.
+type ProjectClient interface {
+	CreateProject(ctx context.Context, project *Project) (*Project, error)
+	ReadProject(ctx context.Context, name string) (*Project, error)
+	ListProjects(ctx context.Context, opts *ListProjectsOptions) ([]*Project, error)
+}
+
+// CreateProject creates a project
+func (c *langsmithClient) CreateProject(ctx context.Context, project *Project) (*Project, error) {
+	if project == nil || project.Name == "" {
+		return nil, fmt.Errorf("project name is required")
+	}
+	created := &Project{}
+	if err := c.doJSON(ctx, opProject, "POST", "/sessions", project, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// ReadProject reads a project by name, ErrNotFound is returned if it doesn't exist
+func (c *langsmithClient) ReadProject(ctx context.Context, name string) (*Project, error) {
+	var projects []*Project
+	if err := c.doJSON(ctx, opProject, "GET", "/sessions?"+url.Values{"name": {name}}.Encode(), nil, &projects); err != nil {
+		return nil, err
+	}
+	for _, p := range projects {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("project %q: %w", name, ErrNotFound)
+}
+
+// ListProjects lists projects of the workspace
+func (c *langsmithClient) ListProjects(ctx context.Context, opts *ListProjectsOptions) ([]*Project, error) {
+	if opts == nil {
+		opts = &ListProjectsOptions{}
+	}
+	query := url.Values{}
+	if opts.NameContains != "" {
+		query.Set("name_contains", opts.NameContains)
+	}
+	if opts.ReferenceDatasetID != "" {
+		query.Set("reference_dataset", opts.ReferenceDatasetID)
+	}
+	if opts.Offset > 0 {
+		query.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	path := "/sessions"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var projects []*Project
+	if err := c.doJSON(ctx, opProject, "GET", path, nil, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// projectCreatingLangsmith creates the project of a run before the run, if it doesn't exist yet.
+type projectCreatingLangsmith struct {
+	Langsmith
+	api      ProjectClient
+	defaults *ProjectDefaults
+	logger   Logger
+
+	projects sync.Map // projectKey -> *sync.Mutex, held while the project is checked
+	ready    sync.Map // projectKey -> struct{}, projects known to exist
+}
+
+func (p *projectCreatingLangsmith) unwrap() Langsmith {
+	return p.Langsmith
+}
+
+func (p *projectCreatingLangsmith) CreateRun(ctx context.Context, run *Run) error {
+	p.ensureProject(ctx, run.SessionName)
+	return p.Langsmith.CreateRun(ctx, run)
+}
+
+// ensureProject creates the project name unless it exists, a failure is logged and checked again by the next run.
+// runs without a project go to the default project, which always exists.
+func (p *projectCreatingLangsmith) ensureProject(ctx context.Context, name string) {
+	if name == "" {
+		return
+	}
+	key := newProjectKey(ctx, name)
+	if _, ok := p.ready.Load(key); ok {
+		return
+	}
+	mu, _ := p.projects.LoadOrStore(key, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+	if _, ok := p.ready.Load(key); ok {
+		return
+	}
+
+	_, err := p.api.ReadProject(ctx, name)
+	if errors.Is(err, ErrNotFound) {
+		project := &Project{Name: name, Description: p.defaults.Description}
+		if len(p.defaults.Metadata) > 0 {
+			project.Extra = map[string]interface{}{"metadata": p.defaults.Metadata}
+		}
+		if _, err = p.api.CreateProject(ctx, project); errors.Is(err, ErrConflict) {
+			err = nil // created concurrently, e.g. by another process
+		}
+		if err == nil {
+			p.logger.Debug(ctx, "created langsmith project", "project", name)
+		}
+	}
+	if err != nil {
+		p.logger.Warn(ctx, "failed to create langsmith project, runs may land in the default project", "project", name, "err", err)
+		return
+	}
+	p.ready.Store(key, struct{}{})
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/sessions":
+			var p map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+			assert.Equal(t, "prod", p["name"])
+			assert.Equal(t, map[string]interface{}{"metadata": map[string]interface{}{"env": "prod"}}, p["extra"])
+			_, _ = w.Write([]byte(`{"id":"p-1","name":"prod"}`))
+		case r.Method == "GET" && r.URL.Path == "/sessions":
+			q := r.URL.Query()
+			switch {
+			case q.Get("name") == "prod":
+				_, _ = w.Write([]byte(`[{"id":"p-1","name":"prod"}]`))
+			case q.Get("name_contains") == "pr":
+				assert.Equal(t, "20", q.Get("limit"))
+				_, _ = w.Write([]byte(`[{"id":"p-1","name":"prod"},{"id":"p-2","name":"preprod"}]`))
+			default:
+				_, _ = w.Write([]byte(`[]`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	cli := NewLangsmith("test-key", srv.URL).(*langsmithClient)
+	ctx := context.Background()
+
+	p, err := cli.CreateProject(ctx, &Project{
+		Name:  "prod",
+		Extra: map[string]interface{}{"metadata": map[string]interface{}{"env": "prod"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "p-1", p.ID)
+	_, err = cli.CreateProject(ctx, &Project{})
+	assert.Error(t, err)
+
+	p, err = cli.ReadProject(ctx, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "p-1", p.ID)
+	_, err = cli.ReadProject(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	ps, err := cli.ListProjects(ctx, &ListProjectsOptions{NameContains: "pr", Limit: 20})
+	require.NoError(t, err)
+	assert.Len(t, ps, 2)
+}
+
+// TestAutoCreateProject 测试首次使用时自动创建不存在的项目
+func TestAutoCreateProject(t *testing.T) {
+	ctx := context.Background()
+	mCli := &mockLangsmith{}
+	log := &recordLogger{}
+	cli := &projectCreatingLangsmith{Langsmith: mCli, api: mCli, logger: log, defaults: &ProjectDefaults{
+		Description: "created by the app", Metadata: map[string]interface{}{"team": "search"}}}
+
+	mCli.On("CreateRun", ctx, mock.Anything).Return(nil)
+	mCli.On("ReadProject", ctx, "existing").Return(&Project{ID: "p-1", Name: "existing"}, nil).Once()
+	mCli.On("ReadProject", ctx, "new").Return(nil, ErrNotFound).Once()
+	mCli.On("CreateProject", ctx, &Project{Name: "new", Description: "created by the app",
+		Extra: map[string]interface{}{"metadata": map[string]interface{}{"team": "search"}}}).
+		Return(&Project{ID: "p-2", Name: "new"}, nil).Once()
+	mCli.On("ReadProject", ctx, "flaky").Return(nil, errors.New("unavailable")).Once()
+	mCli.On("ReadProject", ctx, "flaky").Return(nil, ErrNotFound).Once()
+	mCli.On("CreateProject", ctx, mock.MatchedBy(func(p *Project) bool { return p.Name == "flaky" })).
+		Return(nil, ErrConflict).Once()
+
+	for _, name := range []string{"", "existing", "existing", "new", "new", "flaky", "flaky", "flaky"} {
+		assert.NoError(t, cli.CreateRun(ctx, &Run{ID: "run", SessionName: name}))
+	}
+	mCli.AssertExpectations(t)
+	mCli.AssertNumberOfCalls(t, "CreateRun", 8)
+	warns := 0
+	for _, e := range log.entries {
+		if strings.HasPrefix(e, "WARN failed to create langsmith project") {
+			warns++
+		}
+	}
+	assert.Equal(t, 1, warns)
+}
+
+// TestAutoCreateProjectPerTenant 测试不同租户的同名项目分别创建
+func TestAutoCreateProjectPerTenant(t *testing.T) {
+	mCli := &mockLangsmith{}
+	cli := &projectCreatingLangsmith{Langsmith: mCli, api: mCli, logger: &recordLogger{}, defaults: &ProjectDefaults{}}
+	tenantA := SetTrace(context.Background(), WithAPIKey("key-a"))
+	tenantB := SetTrace(context.Background(), WithAPIKey("key-b"), WithWorkspaceID("ws-b"))
+
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	for _, ctx := range []context.Context{tenantA, tenantB} {
+		mCli.On("ReadProject", ctx, "default-app").Return(nil, ErrNotFound).Once()
+		mCli.On("CreateProject", ctx, mock.Anything).Return(&Project{ID: "p", Name: "default-app"}, nil).Once()
+	}
+	for _, ctx := range []context.Context{tenantA, tenantB, tenantA, tenantB} {
+		assert.NoError(t, cli.CreateRun(ctx, &Run{ID: "run", SessionName: "default-app"}))
+	}
+	mCli.AssertExpectations(t)
+	mCli.AssertNumberOfCalls(t, "CreateProject", 2)
+}
@@ -0,0 +1,354 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+)
+
+const opPrompt APIOp = "prompt"
+
+// PromptCommit is a version of a prompt in the LangSmith Prompt Hub.
+type PromptCommit struct {
+	Owner      string                   `json:"owner"`
+	Repo       string                   `json:"repo"`
+	CommitHash string                   `json:"commit_hash"`
+	Manifest   map[string]interface{}   `json:"manifest"` // LangChain serialized prompt
+	Examples   []map[string]interface{} `json:"examples,omitempty"`
+}
+
+// PromptClient pulls and pushes prompts of the Prompt Hub, it's implemented by the client returned by NewLangsmith.
+type PromptClient interface {
+	PullPrompt(ctx context.Context, identifier string) (*PromptCommit, error)
+	PushPrompt(ctx context.Context, identifier string, manifest map[string]interface{}, opts *PushPromptOptions) (string, error)
+}
+
+// PullPrompt pulls a prompt commit from the Prompt Hub.
+// identifier is "[owner/]name[:commit_hash_or_tag]", the latest commit of your own workspace is used by default.
+func (c *langsmithClient) PullPrompt(ctx context.Context, identifier string) (*PromptCommit, error) {
+	owner, repo, ref, err := parsePromptIdentifier(identifier)
+	if err != nil {
+		return nil, err
+	}
+	path := fmt.Sprintf("/commits/%s/%s/%s", url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(ref))
+	commit := &PromptCommit{}
+	if err = c.doJSON(ctx, opPrompt, "GET", path, nil, commit); err != nil {
+		return nil, err
+	}
+	commit.Owner, commit.Repo = owner, repo
+	return commit, nil
+}
+
+// PushPromptOptions describes the prompt repo, they are only used when the repo is created.
+type PushPromptOptions struct {
+	Description string
+	Tags        []string
+	IsPublic    bool
+}
+
+// PushPrompt creates a new commit of manifest in the Prompt Hub, the prompt repo is created if it doesn't exist.
+// identifier is "[owner/]name", the commit hash is returned.
+func (c *langsmithClient) PushPrompt(ctx context.Context, identifier string, manifest map[string]interface{}, opts *PushPromptOptions) (string, error) {
+	owner, repo, _, err := parsePromptIdentifier(identifier)
+	if err != nil {
+		return "", err
+	}
+	if opts == nil {
+		opts = &PushPromptOptions{}
+	}
+	repoPath := url.PathEscape(owner) + "/" + url.PathEscape(repo)
+
+	err = c.doJSON(ctx, opPrompt, "GET", "/repos/"+repoPath, nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		err = c.doJSON(ctx, opPrompt, "POST", "/repos/", map[string]interface{}{
+			"repo_handle": repo,
+			"description": opts.Description,
+			"tags":        opts.Tags,
+			"is_public":   opts.IsPublic,
+		}, nil)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare prompt repo: %w", err)
+	}
+
+	var latest struct {
+		Commits []struct {
+			CommitHash string `json:"commit_hash"`
+		} `json:"commits"`
+	}
+	if err = c.doJSON(ctx, opPrompt, "GET", "/commits/"+repoPath+"/?limit=1&offset=0", nil, &latest); err != nil {
+		return "", fmt.Errorf("failed to get latest prompt commit: %w", err)
+	}
+	body := map[string]interface{}{"manifest": manifest}
+	if len(latest.Commits) > 0 {
+		body["parent_commit"] = latest.Commits[0].CommitHash
+	}
+
+	var created struct {
+		Commit struct {
+			CommitHash string `json:"commit_hash"`
+		} `json:"commit"`
+	}
+	if err = c.doJSON(ctx, opPrompt, "POST", "/commits/"+repoPath, body, &created); err != nil {
+		return "", fmt.Errorf("failed to create prompt commit: %w", err)
+	}
+	return created.Commit.CommitHash, nil
+}
+
+// PushChatTemplate pushes the templates of a chat template to the Prompt Hub, see PromptManifest.
+func PushChatTemplate(ctx context.Context, cli PromptClient, identifier string, opts *PushPromptOptions,
+	formatType schema.FormatType, templates ...schema.MessagesTemplate) (string, error) {
+	manifest, err := PromptManifest(formatType, templates...)
+	if err != nil {
+		return "", err
+	}
+	return cli.PushPrompt(ctx, identifier, manifest, opts)
+}
+
+// PullChatTemplate pulls a prompt from the Prompt Hub and converts it into an eino ChatTemplate.
+func PullChatTemplate(ctx context.Context, cli PromptClient, identifier string) (prompt.ChatTemplate, error) {
+	commit, err := cli.PullPrompt(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+	return commit.ChatTemplate()
+}
+
+// ChatTemplate converts the manifest of the commit into an eino ChatTemplate.
+func (p *PromptCommit) ChatTemplate() (prompt.ChatTemplate, error) {
+	return ManifestToChatTemplate(p.Manifest)
+}
+
+func parsePromptIdentifier(identifier string) (owner, repo, ref string, err error) {
+	owner, ref = "-", "latest"
+	repo = strings.TrimSpace(identifier)
+	if i := strings.LastIndex(repo, ":"); i >= 0 {
+		repo, ref = repo[:i], repo[i+1:]
+	}
+	if i := strings.Index(repo, "/"); i >= 0 {
+		owner, repo = repo[:i], repo[i+1:]
+	}
+	if owner == "" || repo == "" || ref == "" || strings.Contains(repo, "/") {
+		return "", "", "", fmt.Errorf("invalid prompt identifier: %q", identifier)
+	}
+	return owner, repo, ref, nil
+}
+
+// lcSerialized is a LangChain serialized object, as stored in prompt manifests.
+type lcSerialized struct {
+	ID     []string `json:"id"`
+	Kwargs lcKwargs `json:"kwargs"`
+}
+
+type lcKwargs struct {
+	Template       string          `json:"template,omitempty"`
+	TemplateFormat string          `json:"template_format,omitempty"`
+	InputVariables []string        `json:"input_variables,omitempty"`
+	Messages       []*lcSerialized `json:"messages,omitempty"`
+	Prompt         *lcSerialized   `json:"prompt,omitempty"`
+	VariableName   string          `json:"variable_name,omitempty"`
+	Optional       bool            `json:"optional,omitempty"`
+	Role           string          `json:"role,omitempty"`
+	Content        string          `json:"content,omitempty"`
+}
+
+func (o *lcSerialized) className() string {
+	if o == nil || len(o.ID) == 0 {
+		return ""
+	}
+	return o.ID[len(o.ID)-1]
+}
+
+// ManifestToChatTemplate converts a LangChain ChatPromptTemplate or PromptTemplate manifest into an eino ChatTemplate.
+// f-string and jinja2 templates are supported, all messages must share the same template format.
+func ManifestToChatTemplate(manifest map[string]interface{}) (prompt.ChatTemplate, error) {
+	data, err := sonic.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	obj := &lcSerialized{}
+	if err = sonic.Unmarshal(data, obj); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+
+	c := &templateConverter{}
+	var templates []schema.MessagesTemplate
+	switch obj.className() {
+	case "ChatPromptTemplate":
+		for _, m := range obj.Kwargs.Messages {
+			t, err := c.message(m)
+			if err != nil {
+				return nil, err
+			}
+			templates = append(templates, t)
+		}
+	case "PromptTemplate":
+		t, err := c.template(obj)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, schema.UserMessage(t))
+	default:
+		return nil, fmt.Errorf("unsupported prompt type: %s", obj.className())
+	}
+	return prompt.FromMessages(c.format, templates...), nil
+}
+
+type templateConverter struct {
+	format    schema.FormatType
+	formatSet bool
+}
+
+func (c *templateConverter) message(m *lcSerialized) (schema.MessagesTemplate, error) {
+	switch m.className() {
+	case "MessagesPlaceholder":
+		return schema.MessagesPlaceholder(m.Kwargs.VariableName, m.Kwargs.Optional), nil
+	case "SystemMessage":
+		return schema.SystemMessage(m.Kwargs.Content), nil
+	case "HumanMessage":
+		return schema.UserMessage(m.Kwargs.Content), nil
+	case "AIMessage":
+		return schema.AssistantMessage(m.Kwargs.Content, nil), nil
+	}
+
+	t, err := c.template(m.Kwargs.Prompt)
+	if err != nil {
+		return nil, err
+	}
+	switch m.className() {
+	case "SystemMessagePromptTemplate":
+		return schema.SystemMessage(t), nil
+	case "HumanMessagePromptTemplate":
+		return schema.UserMessage(t), nil
+	case "AIMessagePromptTemplate":
+		return schema.AssistantMessage(t, nil), nil
+	case "ChatMessagePromptTemplate":
+		return &schema.Message{Role: schema.RoleType(m.Kwargs.Role), Content: t}, nil
+	default:
+		return nil, fmt.Errorf("unsupported prompt message type: %s", m.className())
+	}
+}
+
+func (c *templateConverter) template(p *lcSerialized) (string, error) {
+	if p == nil || p.className() != "PromptTemplate" {
+		return "", fmt.Errorf("unsupported message prompt type: %s", p.className())
+	}
+	var format schema.FormatType
+	switch p.Kwargs.TemplateFormat {
+	case "", "f-string":
+		format = schema.FString
+	case "jinja2":
+		format = schema.Jinja2
+	default:
+		return "", fmt.Errorf("unsupported template format: %s", p.Kwargs.TemplateFormat)
+	}
+	if c.formatSet && c.format != format {
+		return "", fmt.Errorf("mixed template formats are not supported")
+	}
+	c.format, c.formatSet = format, true
+	return p.Kwargs.Template, nil
+}
+
+// PromptPlaceholder is a schema.MessagesPlaceholder which can be serialized by PromptManifest.
+type PromptPlaceholder struct {
+	Key      string
+	Optional bool
+}
+
+// Format implements schema.MessagesTemplate
+func (p *PromptPlaceholder) Format(ctx context.Context, vs map[string]any, formatType schema.FormatType) ([]*schema.Message, error) {
+	return schema.MessagesPlaceholder(p.Key, p.Optional).Format(ctx, vs, formatType)
+}
+
+var (
+	fStringVariable = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	jinja2Variable  = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// PromptManifest serializes templates, i.e. the arguments of prompt.FromMessages, into a LangChain ChatPromptTemplate
+// manifest. templates must be *schema.Message or *PromptPlaceholder, only f-string and jinja2 formats are supported.
+func PromptManifest(formatType schema.FormatType, templates ...schema.MessagesTemplate) (map[string]interface{}, error) {
+	var format string
+	var variable *regexp.Regexp
+	switch formatType {
+	case schema.FString:
+		format, variable = "f-string", fStringVariable
+	case schema.Jinja2:
+		format, variable = "jinja2", jinja2Variable
+	default:
+		return nil, fmt.Errorf("unsupported template format: %v", formatType)
+	}
+
+	var inputVariables []string
+	seen := map[string]bool{}
+	addVariable := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			inputVariables = append(inputVariables, name)
+		}
+	}
+
+	messages := make([]interface{}, 0, len(templates))
+	for _, t := range templates {
+		switch tpl := t.(type) {
+		case *PromptPlaceholder:
+			addVariable(tpl.Key)
+			messages = append(messages, lcConstructor([]string{"langchain", "prompts", "chat", "MessagesPlaceholder"},
+				map[string]interface{}{"variable_name": tpl.Key, "optional": tpl.Optional}))
+		case *schema.Message:
+			var vars []string
+			for _, m := range variable.FindAllStringSubmatch(tpl.Content, -1) {
+				addVariable(m[1])
+				vars = append(vars, m[1])
+			}
+			promptTemplate := lcConstructor([]string{"langchain", "prompts", "prompt", "PromptTemplate"},
+				map[string]interface{}{"input_variables": vars, "template": tpl.Content, "template_format": format})
+			kwargs := map[string]interface{}{"prompt": promptTemplate}
+			var class string
+			switch tpl.Role {
+			case schema.System:
+				class = "SystemMessagePromptTemplate"
+			case schema.User:
+				class = "HumanMessagePromptTemplate"
+			case schema.Assistant:
+				class = "AIMessagePromptTemplate"
+			default:
+				class = "ChatMessagePromptTemplate"
+				kwargs["role"] = string(tpl.Role)
+			}
+			messages = append(messages, lcConstructor([]string{"langchain", "prompts", "chat", class}, kwargs))
+		default:
+			return nil, fmt.Errorf("unsupported messages template: %T", t)
+		}
+	}
+
+	return lcConstructor([]string{"langchain", "prompts", "chat", "ChatPromptTemplate"},
+		map[string]interface{}{"input_variables": inputVariables, "messages": messages}), nil
+}
+
+func lcConstructor(id []string, kwargs map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"lc": 1, "type": "constructor", "id": id, "kwargs": kwargs}
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const chatPromptManifest = `{
+	"lc": 1, "type": "constructor", "id": ["langchain", "prompts", "chat", "ChatPromptTemplate"],
+	"kwargs": {
+		"input_variables": ["question", "history"],
+		"messages": [
+			{"lc": 1, "type": "constructor", "id": ["langchain", "prompts", "chat", "SystemMessagePromptTemplate"],
+			 "kwargs": {"prompt": {"lc": 1, "type": "constructor", "id": ["langchain", "prompts", "prompt", "PromptTemplate"],
+			   "kwargs": {"input_variables": [], "template": "You are a helpful assistant.", "template_format": "f-string"}}}},
+			{"lc": 1, "type": "constructor", "id": ["langchain", "prompts", "chat", "MessagesPlaceholder"],
+			 "kwargs": {"variable_name": "history", "optional": true}},
+			{"lc": 1, "type": "constructor", "id": ["langchain", "prompts", "chat", "HumanMessagePromptTemplate"],
+			 "kwargs": {"prompt": {"lc": 1, "type": "constructor", "id": ["langchain", "prompts", "prompt", "PromptTemplate"],
+			   "kwargs": {"input_variables": ["question"], "template": "Question: {question}", "template_format": "f-string"}}}}
+		]
+	}
+}`
+
+func TestParsePromptIdentifier(t *testing.T) {
+	tests := []struct {
+		identifier string
+		owner      string
+		repo       string
+		ref        string
+		wantErr    bool
+	}{
+		{identifier: "my-prompt", owner: "-", repo: "my-prompt", ref: "latest"},
+		{identifier: "my-prompt:abc123", owner: "-", repo: "my-prompt", ref: "abc123"},
+		{identifier: "team/my-prompt:prod", owner: "team", repo: "my-prompt", ref: "prod"},
+		{identifier: "", wantErr: true},
+		{identifier: "a/b/c", wantErr: true},
+	}
+	for _, tt := range tests {
+		owner, repo, ref, err := parsePromptIdentifier(tt.identifier)
+		if tt.wantErr {
+			assert.Error(t, err, tt.identifier)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, []string{tt.owner, tt.repo, tt.ref}, []string{owner, repo, ref})
+	}
+}
+
+func TestPullChatTemplate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/commits/team/qa-prompt/latest", r.URL.Path)
+		_, _ = w.Write([]byte(`{"commit_hash": "abc123", "manifest": ` + chatPromptManifest + `}`))
+	}))
+	defer srv.Close()
+
+	cli := NewLangsmith("test-key", srv.URL).(*langsmithClient)
+	tpl, err := PullChatTemplate(context.Background(), cli, "team/qa-prompt")
+	require.NoError(t, err)
+
+	msgs, err := tpl.Format(context.Background(), map[string]any{
+		"question": "what is eino?",
+		"history":  []*schema.Message{schema.AssistantMessage("hi", nil)},
+	})
+	require.NoError(t, err)
+	require.Len(t, msgs, 3)
+	assert.Equal(t, schema.System, msgs[0].Role)
+	assert.Equal(t, "hi", msgs[1].Content)
+	assert.Equal(t, schema.User, msgs[2].Role)
+	assert.Equal(t, "Question: what is eino?", msgs[2].Content)
+}
+
+func TestManifestToChatTemplate(t *testing.T) {
+	t.Run("prompt template", func(t *testing.T) {
+		tpl, err := ManifestToChatTemplate(map[string]interface{}{
+			"id": []interface{}{"langchain", "prompts", "prompt", "PromptTemplate"},
+			"kwargs": map[string]interface{}{
+				"template":        "Hello {{ name }}",
+				"template_format": "jinja2",
+			},
+		})
+		require.NoError(t, err)
+		msgs, err := tpl.Format(context.Background(), map[string]any{"name": "eino"})
+		require.NoError(t, err)
+		assert.Equal(t, "Hello eino", msgs[0].Content)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		var manifest map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(chatPromptManifest), &manifest))
+		msgs := manifest["kwargs"].(map[string]interface{})["messages"].([]interface{})
+		msgs[0].(map[string]interface{})["kwargs"].(map[string]interface{})["prompt"].(map[string]interface{})["kwargs"].(map[string]interface{})["template_format"] = "mustache"
+		_, err := ManifestToChatTemplate(manifest)
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		_, err := ManifestToChatTemplate(map[string]interface{}{"id": []interface{}{"langchain", "prompts", "FewShotPromptTemplate"}})
+		assert.Error(t, err)
+	})
+}
+
+func TestPromptManifestRoundTrip(t *testing.T) {
+	templates := []schema.MessagesTemplate{
+		schema.SystemMessage("You are {role}."),
+		&PromptPlaceholder{Key: "history", Optional: true},
+		schema.UserMessage("Question: {question}"),
+	}
+	manifest, err := PromptManifest(schema.FString, templates...)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"role", "history", "question"}, manifest["kwargs"].(map[string]interface{})["input_variables"])
+
+	tpl, err := ManifestToChatTemplate(manifest)
+	require.NoError(t, err)
+	msgs, err := tpl.Format(context.Background(), map[string]any{"role": "a pirate", "question": "where?"})
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, "You are a pirate.", msgs[0].Content)
+	assert.Equal(t, "Question: where?", msgs[1].Content)
+
+	_, err = PromptManifest(schema.GoTemplate, templates...)
+	assert.Error(t, err)
+}
+
+func TestPushChatTemplate(t *testing.T) {
+	var commitBody map[string]interface{}
+	var repoCreated bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repos/-/qa-prompt":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == "POST" && r.URL.Path == "/repos/":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "qa-prompt", body["repo_handle"])
+			assert.Equal(t, "qa", body["description"])
+			repoCreated = true
+		case r.Method == "GET" && r.URL.Path == "/commits/-/qa-prompt/":
+			_, _ = w.Write([]byte(`{"commits": [{"commit_hash": "parent"}]}`))
+		case r.Method == "POST" && r.URL.Path == "/commits/-/qa-prompt":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&commitBody))
+			_, _ = w.Write([]byte(`{"commit": {"commit_hash": "new"}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	cli := NewLangsmith("test-key", srv.URL).(*langsmithClient)
+	hash, err := PushChatTemplate(context.Background(), cli, "qa-prompt", &PushPromptOptions{Description: "qa"},
+		schema.FString, schema.UserMessage("{question}"))
+	require.NoError(t, err)
+	assert.Equal(t, "new", hash)
+	assert.True(t, repoCreated)
+	assert.Equal(t, "parent", commitBody["parent_commit"])
+	assert.NotNil(t, commitBody["manifest"])
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/sonic"
+)
+
+// Distributed tracing headers of the langsmith SDKs, a trace started by a Python or JS service can be continued
+// by a Go service and vice versa.
+const (
+	TraceHeader   = "langsmith-trace" // dotted order of the parent run
+	BaggageHeader = "baggage"         // W3C baggage carrying langsmith-metadata, langsmith-tags and langsmith-project
+
+	baggagePrefix   = "langsmith-"
+	baggageMetadata = baggagePrefix + "metadata"
+	baggageTags     = baggagePrefix + "tags"
+	baggageProject  = baggagePrefix + "project"
+)
+
+// InjectHeaders writes the run ctx is traced in into the langsmith-trace and baggage headers of an outgoing request.
+// baggage entries of other vendors are kept.
+func InjectHeaders(ctx context.Context, header http.Header) {
+	_, state := GetState(ctx)
+	if state == nil || state.ParentDottedOrder == "" {
+		return
+	}
+	header.Set(TraceHeader, state.ParentDottedOrder)
+
+	var items []string
+	for _, item := range strings.Split(header.Get(BaggageHeader), ",") {
+		if item = strings.TrimSpace(item); item != "" && !strings.HasPrefix(item, baggagePrefix) {
+			items = append(items, item)
+		}
+	}
+	if opts, _ := ctx.Value(langsmithTraceOptionKey{}).(*traceOptions); opts != nil {
+		if md, _ := SafeDeepCopySyncMapMetadata(opts.Metadata)["metadata"].(map[string]interface{}); len(md) > 0 {
+			if data, err := sonic.MarshalString(md); err == nil {
+				items = append(items, baggageMetadata+"="+baggageEscape(data))
+			}
+		}
+		if len(opts.Tags) > 0 {
+			items = append(items, baggageTags+"="+baggageEscape(strings.Join(opts.Tags, ",")))
+		}
+		if opts.SessionName != "" {
+			items = append(items, baggageProject+"="+baggageEscape(opts.SessionName))
+		}
+	}
+	if len(items) > 0 {
+		header.Set(BaggageHeader, strings.Join(items, ","))
+	}
+}
+
+// ExtractHeaders continues the trace of the langsmith-trace and baggage headers of an incoming request:
+// runs started from the returned context are children of the remote run, and inherit its metadata, tags and project
+// unless ctx already sets them. ctx is returned unchanged when the headers are missing or invalid.
+func ExtractHeaders(ctx context.Context, header http.Header) context.Context {
+	dottedOrder := header.Get(TraceHeader)
+	if dottedOrder == "" {
+		return ctx
+	}
+	traceID, parentID, err := parseDottedOrder(dottedOrder)
+	if err != nil {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, langsmithStateKey{}, &LangsmithState{
+		TraceID:           traceID,
+		ParentRunID:       parentID,
+		ParentDottedOrder: dottedOrder,
+	})
+
+	var (
+		remoteMetadata map[string]interface{}
+		remoteTags     []string
+		remoteProject  string
+	)
+	for _, item := range strings.Split(header.Get(BaggageHeader), ",") {
+		kv := strings.SplitN(strings.TrimSpace(item), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value, err := url.PathUnescape(kv[1])
+		if err != nil {
+			continue
+		}
+		switch kv[0] {
+		case baggageMetadata:
+			_ = sonic.UnmarshalString(value, &remoteMetadata)
+		case baggageTags:
+			remoteTags = strings.Split(value, ",")
+		case baggageProject:
+			remoteProject = value
+		}
+	}
+	if len(remoteMetadata) == 0 && len(remoteTags) == 0 && remoteProject == "" {
+		return ctx
+	}
+
+	opts := &traceOptions{}
+	if old, _ := ctx.Value(langsmithTraceOptionKey{}).(*traceOptions); old != nil {
+		*opts = *old
+	}
+	if opts.SessionName == "" {
+		opts.SessionName = remoteProject
+	}
+	opts.Tags = append([]string(nil), opts.Tags...)
+	for _, tag := range remoteTags {
+		AddTag(tag)(opts)
+	}
+	if len(remoteMetadata) > 0 {
+		extra := SafeDeepCopySyncMapMetadata(opts.Metadata)
+		md := make(map[string]interface{}, len(remoteMetadata))
+		for k, v := range remoteMetadata {
+			md[k] = v
+		}
+		for k, v := range extra["metadata"].(map[string]interface{}) {
+			md[k] = v
+		}
+		extra["metadata"] = md
+		opts.Metadata = &sync.Map{}
+		for k, v := range extra {
+			opts.Metadata.Store(k, v)
+		}
+	}
+	return context.WithValue(ctx, langsmithTraceOptionKey{}, opts)
+}
+
+// baggageEscape percent-encodes a baggage value like python's urllib.parse.quote.
+func baggageEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// parseDottedOrder returns the trace id and the id of the last run of a dotted order,
+// e.g. 20250102T030405000000Z<trace id>.20250102T030405000100Z<run id>
+func parseDottedOrder(dottedOrder string) (traceID, runID string, err error) {
+	for i, segment := range strings.Split(dottedOrder, ".") {
+		idx := strings.Index(segment, "Z")
+		if idx < 0 || idx == len(segment)-1 {
+			return "", "", fmt.Errorf("invalid dotted order %q", dottedOrder)
+		}
+		runID = segment[idx+1:]
+		if i == 0 {
+			traceID = runID
+		}
+	}
+	return traceID, runID, nil
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDottedOrder(t *testing.T) {
+	traceID, runID, err := parseDottedOrder("20250102T030405000000Zaaaa.20250102T030405000100Zbbbb")
+	require.NoError(t, err)
+	assert.Equal(t, "aaaa", traceID)
+	assert.Equal(t, "bbbb", runID)
+
+	_, _, err = parseDottedOrder("20250102T030405000000Zaaaa.garbage")
+	assert.Error(t, err)
+}
+
+func TestExtractHeaders(t *testing.T) {
+	// headers as produced by the python sdk
+	header := http.Header{}
+	header.Set(TraceHeader, "20250102T030405000000Ztrace-1.20250102T030405000100Zrun-2")
+	header.Set(BaggageHeader, "other=1,langsmith-metadata=%7B%22user%22%3A%20%22u%201%22%7D,langsmith-tags=py%2Cweb,langsmith-project=my%20project")
+
+	md := &sync.Map{}
+	md.Store("metadata", map[string]interface{}{"user": "local"})
+	ctx := SetTrace(context.Background(), AddTag("go"), SetMetadata(md))
+	ctx = ExtractHeaders(ctx, header)
+
+	_, state := GetState(ctx)
+	require.NotNil(t, state)
+	assert.Equal(t, "trace-1", state.TraceID)
+	assert.Equal(t, "run-2", state.ParentRunID)
+	assert.Equal(t, "20250102T030405000000Ztrace-1.20250102T030405000100Zrun-2", state.ParentDottedOrder)
+
+	opts := ctx.Value(langsmithTraceOptionKey{}).(*traceOptions)
+	assert.Equal(t, "my project", opts.SessionName)
+	assert.Equal(t, []string{"go", "py", "web"}, opts.Tags)
+	metadata := SafeDeepCopySyncMapMetadata(opts.Metadata)["metadata"].(map[string]interface{})
+	assert.Equal(t, "local", metadata["user"], "local metadata wins")
+
+	assert.Equal(t, context.Background(), ExtractHeaders(context.Background(), http.Header{}))
+}
+
+func TestInjectHeaders(t *testing.T) {
+	header := http.Header{}
+	InjectHeaders(context.Background(), header)
+	assert.Empty(t, header)
+
+	md := &sync.Map{}
+	md.Store("metadata", map[string]interface{}{"user": "u 1"})
+	ctx := SetTrace(context.Background(), AddTag("a"), AddTag("b"), SetMetadata(md), WithSessionName("proj/x"))
+	ctx = context.WithValue(ctx, langsmithStateKey{}, &LangsmithState{
+		TraceID:           "trace-1",
+		ParentRunID:       "run-2",
+		ParentDottedOrder: "20250102T030405000000Ztrace-1.20250102T030405000100Zrun-2",
+	})
+	header.Set(BaggageHeader, "other=1,langsmith-project=stale")
+	InjectHeaders(ctx, header)
+	assert.Equal(t, "20250102T030405000000Ztrace-1.20250102T030405000100Zrun-2", header.Get(TraceHeader))
+	assert.Equal(t, "other=1,langsmith-metadata=%7B%22user%22%3A%22u%201%22%7D,langsmith-tags=a%2Cb,langsmith-project=proj%2Fx", header.Get(BaggageHeader))
+
+	// round trip
+	got := ExtractHeaders(context.Background(), header)
+	opts := got.Value(langsmithTraceOptionKey{}).(*traceOptions)
+	assert.Equal(t, "proj/x", opts.SessionName)
+	assert.Equal(t, []string{"a", "b"}, opts.Tags)
+	assert.Equal(t, "u 1", SafeDeepCopySyncMapMetadata(opts.Metadata)["metadata"].(map[string]interface{})["user"])
+}
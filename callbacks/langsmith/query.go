@@ -0,0 +1,130 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const opQuery APIOp = "query"
+
+// ListRunsOptions filters and paginates ListRuns, all filters are combined with AND.
+type ListRunsOptions struct {
+	ProjectIDs  []string // runs of these projects, see ReadProject
+	RunType     RunType
+	TraceID     string // runs of a single trace
+	ParentRunID string
+	IsRoot      bool     // only root runs
+	Tags        []string // runs having all of these tags
+	StartAfter  *time.Time
+	StartBefore *time.Time
+	// Filter is an additional langsmith filter expression, e.g. `and(eq(status, "error"), gt(latency, 5))`
+	Filter string
+
+	Cursor string // NextCursor of the previous page
+	Limit  int    // default: server side default (100)
+}
+
+// RunsPage is a page of runs returned by ListRuns, NextCursor is empty on the last page.
+type RunsPage struct {
+	Runs       []*Run
+	NextCursor string
+}
+
+type runsQuery struct {
+	Session   []string `json:"session,omitempty"`
+	RunType   RunType  `json:"run_type,omitempty"`
+	Trace     string   `json:"trace,omitempty"`
+	ParentRun string   `json:"parent_run,omitempty"`
+	IsRoot    *bool    `json:"is_root,omitempty"`
+	Filter    string   `json:"filter,omitempty"`
+	Cursor    string   `json:"cursor,omitempty"`
+	Limit     int      `json:"limit,omitempty"`
+}
+
+type runsQueryResponse struct {
+	Runs    []*Run            `json:"runs"`
+	Cursors map[string]string `json:"cursors"`
+}
+
+// QueryClient reads runs back from langsmith, it's implemented by the client returned by NewLangsmith.
+type QueryClient interface {
+	ReadRun(ctx context.Context, runID string) (*Run, error)
+	ListRuns(ctx context.Context, opts *ListRunsOptions) (*RunsPage, error)
+}
+
+// ReadRun reads a run by id, ErrNotFound is returned if it doesn't exist
+func (c *langsmithClient) ReadRun(ctx context.Context, runID string) (*Run, error) {
+	run := &Run{}
+	if err := c.doJSON(ctx, opQuery, "GET", "/runs/"+url.PathEscape(runID), nil, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// ListRuns queries runs matching opts
+func (c *langsmithClient) ListRuns(ctx context.Context, opts *ListRunsOptions) (*RunsPage, error) {
+	if opts == nil {
+		opts = &ListRunsOptions{}
+	}
+	q := &runsQuery{
+		Session:   opts.ProjectIDs,
+		RunType:   opts.RunType,
+		Trace:     opts.TraceID,
+		ParentRun: opts.ParentRunID,
+		Filter:    buildRunsFilter(opts),
+		Cursor:    opts.Cursor,
+		Limit:     opts.Limit,
+	}
+	if opts.IsRoot {
+		q.IsRoot = &opts.IsRoot
+	}
+	resp := &runsQueryResponse{}
+	if err := c.doJSON(ctx, opQuery, "POST", "/runs/query", q, resp); err != nil {
+		return nil, err
+	}
+	return &RunsPage{Runs: resp.Runs, NextCursor: resp.Cursors["next"]}, nil
+}
+
+// buildRunsFilter translates tags and the time range into a filter expression and joins it with opts.Filter.
+func buildRunsFilter(opts *ListRunsOptions) string {
+	var conds []string
+	for _, tag := range opts.Tags {
+		conds = append(conds, fmt.Sprintf("has(tags, %q)", tag))
+	}
+	if opts.StartAfter != nil {
+		conds = append(conds, fmt.Sprintf("gte(start_time, %q)", opts.StartAfter.UTC().Format(time.RFC3339Nano)))
+	}
+	if opts.StartBefore != nil {
+		conds = append(conds, fmt.Sprintf("lt(start_time, %q)", opts.StartBefore.UTC().Format(time.RFC3339Nano)))
+	}
+	if opts.Filter != "" {
+		conds = append(conds, opts.Filter)
+	}
+	switch len(conds) {
+	case 0:
+		return ""
+	case 1:
+		return conds[0]
+	default:
+		return "and(" + strings.Join(conds, ", ") + ")"
+	}
+}
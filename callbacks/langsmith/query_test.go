@@ -0,0 +1,86 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRunsFilter(t *testing.T) {
+	assert.Equal(t, "", buildRunsFilter(&ListRunsOptions{}))
+	assert.Equal(t, `has(tags, "a")`, buildRunsFilter(&ListRunsOptions{Tags: []string{"a"}}))
+
+	after := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t,
+		`and(has(tags, "a"), has(tags, "b"), gte(start_time, "2025-01-02T03:04:05Z"), eq(status, "error"))`,
+		buildRunsFilter(&ListRunsOptions{Tags: []string{"a", "b"}, StartAfter: &after, Filter: `eq(status, "error")`}))
+}
+
+func TestQueryClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/runs/run-1":
+			_, _ = w.Write([]byte(`{"id":"run-1","name":"chain","run_type":"chain","start_time":"2025-01-02T03:04:05Z","inputs":{"a":1}}`))
+		case r.Method == "POST" && r.URL.Path == "/runs/query":
+			var q map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&q))
+			assert.Equal(t, []interface{}{"p-1"}, q["session"])
+			assert.Equal(t, "llm", q["run_type"])
+			assert.Equal(t, true, q["is_root"])
+			assert.Equal(t, `has(tags, "prod")`, q["filter"])
+			if q["cursor"] == nil {
+				_, _ = w.Write([]byte(`{"runs":[{"id":"run-1"},{"id":"run-2"}],"cursors":{"next":"c-2"}}`))
+				return
+			}
+			assert.Equal(t, "c-2", q["cursor"])
+			_, _ = w.Write([]byte(`{"runs":[{"id":"run-3"}],"cursors":{"next":null}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	cli := NewLangsmith("test-key", srv.URL).(*langsmithClient)
+	ctx := context.Background()
+
+	run, err := cli.ReadRun(ctx, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, RunTypeChain, run.RunType)
+	assert.Equal(t, float64(1), run.Inputs["a"])
+	_, err = cli.ReadRun(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	opts := &ListRunsOptions{ProjectIDs: []string{"p-1"}, RunType: RunTypeLLM, IsRoot: true, Tags: []string{"prod"}}
+	page, err := cli.ListRuns(ctx, opts)
+	require.NoError(t, err)
+	assert.Len(t, page.Runs, 2)
+	assert.Equal(t, "c-2", page.NextCursor)
+
+	opts.Cursor = page.NextCursor
+	page, err = cli.ListRuns(ctx, opts)
+	require.NoError(t, err)
+	assert.Len(t, page.Runs, 1)
+	assert.Equal(t, "", page.NextCursor)
+}
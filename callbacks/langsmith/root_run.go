@@ -0,0 +1,98 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"sync"
+)
+
+// rootRun holds the run created for the root component of a trace, shared by the states of all the runs nested in
+// it, to pass it to Config.Evaluators and AddRunToDataset when it ends.
+type rootRun struct {
+	mu       sync.Mutex
+	run      *Run
+	datasets []string // names of the datasets to add the run to, see AddRunToDataset
+}
+
+// newRootRun returns the holder of run if it's the root of a trace, or the root of the parent run otherwise.
+func newRootRun(state *LangsmithState, run *Run) *rootRun {
+	if state.ParentRunID != "" {
+		return state.root
+	}
+	return &rootRun{run: run}
+}
+
+// set records run once it's complete, for runs whose inputs are read from a stream.
+func (r *rootRun) set(run *Run) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.run = run
+}
+
+// addDataset records the name of a dataset to add the run to once it ended.
+func (r *rootRun) addDataset(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range r.datasets {
+		if v == name {
+			return
+		}
+	}
+	r.datasets = append(r.datasets, name)
+}
+
+// finish returns the root run ended with patch and the datasets to add it to, nil if runID isn't the root run or
+// the root run isn't complete yet.
+func (r *rootRun) finish(runID string, patch *RunPatch) (*Run, []string) {
+	if r == nil {
+		return nil, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.run == nil || r.run.ID != runID {
+		return nil, nil
+	}
+	run := *r.run
+	run.EndTime = patch.EndTime
+	run.Outputs = patch.Outputs
+	run.Error = patch.Error
+	run.Events = patch.Events
+	if patch.Extra != nil {
+		run.Extra = patch.Extra
+	}
+	if patch.Tags != nil {
+		run.Tags = patch.Tags
+	}
+	datasets := r.datasets
+	r.datasets = nil
+	return &run, datasets
+}
+
+// finishRoot passes the run ended with patch to Config.Evaluators and the datasets it was added to, if it's the
+// root run of the trace.
+func (c *CallbackHandler) finishRoot(ctx context.Context, state *LangsmithState, patch *RunPatch) {
+	run, datasets := state.root.finish(state.ParentRunID, patch)
+	if run == nil {
+		return
+	}
+	c.evaluate(ctx, run)
+	c.addToDatasets(ctx, run, datasets)
+}
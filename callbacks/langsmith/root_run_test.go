@@ -0,0 +1,52 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootRun(t *testing.T) {
+	run := &Run{ID: "run-1"}
+	root := newRootRun(&LangsmithState{}, nil)
+	require.NotNil(t, root)
+	assert.Same(t, root, newRootRun(&LangsmithState{ParentRunID: "run-1", root: root}, &Run{ID: "child"}))
+	assert.Nil(t, newRootRun(&LangsmithState{ParentRunID: "remote"}, &Run{ID: "child"}))
+
+	finished, _ := root.finish("run-1", &RunPatch{})
+	assert.Nil(t, finished, "inputs not read yet")
+	root.set(run)
+	root.addDataset("golden")
+	root.addDataset("golden")
+	finished, _ = root.finish("child", &RunPatch{})
+	assert.Nil(t, finished, "only the root run finishes the trace")
+
+	errStr := "boom"
+	finished, datasets := root.finish("run-1", &RunPatch{Error: &errStr, Tags: []string{"a"}})
+	assert.Equal(t, "run-1", finished.ID)
+	assert.Equal(t, &errStr, finished.Error)
+	assert.Equal(t, []string{"a"}, finished.Tags)
+	assert.Equal(t, []string{"golden"}, datasets)
+	assert.Nil(t, run.Error, "the created run isn't modified")
+
+	var nilRoot *rootRun
+	finished, _ = nilRoot.finish("run-1", &RunPatch{})
+	assert.Nil(t, finished)
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// DefaultRunIDGen is the default Config.RunIDGen, it generates time-ordered (version 7) uuids: runs and traces sort
+// by id in their start order, and ids generated close in time are close in the indexes of langsmith.
+func DefaultRunIDGen(ctx context.Context) string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.NewString()
+	}
+	return id.String()
+}
+
+// RandomRunIDGen generates random (version 4) uuids, set it as Config.RunIDGen to keep the ids of previous releases.
+func RandomRunIDGen(ctx context.Context) string {
+	return uuid.NewString()
+}
+
+// NewSequentialRunIDGen returns a Config.RunIDGen generating the same sequence of uuids for the same seed, e.g. for
+// golden tests of run trees or to replay a recorded trace with stable ids. The ids are name-based uuids of the seed
+// and a counter, concurrent runs get deterministic ids only if they start in a deterministic order.
+func NewSequentialRunIDGen(seed string) func(ctx context.Context) string {
+	namespace := uuid.NewSHA1(uuid.NameSpaceOID, []byte(seed))
+	var mu sync.Mutex
+	var n uint64
+	return func(ctx context.Context) string {
+		mu.Lock()
+		n++
+		i := n
+		mu.Unlock()
+		return uuid.NewSHA1(namespace, []byte(strconv.FormatUint(i, 10))).String()
+	}
+}
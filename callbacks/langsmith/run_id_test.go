@@ -0,0 +1,88 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSequentialRunIDGen 测试相同种子生成相同的 run id 序列
+func TestSequentialRunIDGen(t *testing.T) {
+	ctx := context.Background()
+	gen1, gen2, other := NewSequentialRunIDGen("seed"), NewSequentialRunIDGen("seed"), NewSequentialRunIDGen("other")
+	seen := map[string]bool{}
+	for i := 0; i < 10; i++ {
+		id := gen1(ctx)
+		_, err := uuid.Parse(id)
+		require.NoError(t, err)
+		assert.Equal(t, id, gen2(ctx))
+		assert.NotEqual(t, id, other(ctx))
+		assert.False(t, seen[id])
+		seen[id] = true
+	}
+}
+
+// TestHandlerRunIDGen 测试 handler 使用配置的 RunIDGen 生成 run 与 trace id
+func TestHandlerRunIDGen(t *testing.T) {
+	mCli := &mockLangsmith{}
+	var runs []*Run
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		runs = append(runs, args.Get(1).(*Run))
+	}).Return(nil)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{RunIDGen: NewSequentialRunIDGen("replay")}}
+
+	ctx := h.OnStart(context.Background(), &callbacks.RunInfo{Name: "graph"}, "in")
+	h.OnStart(ctx, &callbacks.RunInfo{Name: "node"}, "in")
+
+	expected := NewSequentialRunIDGen("replay")
+	first, second := expected(ctx), expected(ctx)
+	require.Len(t, runs, 2)
+	assert.Equal(t, first, runs[0].ID)
+	assert.Equal(t, first, runs[0].TraceID)
+	assert.Equal(t, second, runs[1].ID)
+	assert.Equal(t, first, runs[1].TraceID)
+
+	cfg := &Config{APIKey: "test-key"}
+	_, err := NewLangsmithHandler(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.RunIDGen)
+}
+
+// TestDefaultRunIDGen 测试默认生成按时间排序的 UUIDv7
+func TestDefaultRunIDGen(t *testing.T) {
+	ctx := context.Background()
+	prev := ""
+	for i := 0; i < 100; i++ {
+		id := DefaultRunIDGen(ctx)
+		parsed, err := uuid.Parse(id)
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Version(7), parsed.Version())
+		assert.Less(t, prev, id)
+		prev = id
+	}
+
+	parsed, err := uuid.Parse(RandomRunIDGen(ctx))
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(4), parsed.Version())
+}
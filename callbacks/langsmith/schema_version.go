@@ -0,0 +1,88 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+)
+
+// SchemaVersion selects the format of the runs sent to langsmith, to pin the behavior against older self-hosted
+// servers as the run format evolves.
+type SchemaVersion int
+
+const (
+	// SchemaVersionDefault is the latest version supported by the package, it's the default.
+	SchemaVersionDefault SchemaVersion = 0
+	// SchemaV1 is the format of servers predating run events and multipart ingestion: events are sent as the
+	// "events" key of the run extra instead of the events field, and media stays inline in the run inputs.
+	SchemaV1 SchemaVersion = 1
+	// SchemaV2 is the current format: run timeline events in the events field, and media uploaded as attachments.
+	SchemaV2 SchemaVersion = 2
+)
+
+// ExtraEvents is the extra key holding the run events with SchemaV1.
+const ExtraEvents = "events"
+
+// WithSchemaVersion sets the format of the runs sent by the client, see SchemaVersion. default: the latest one
+func WithSchemaVersion(v SchemaVersion) ClientOption {
+	return func(c *langsmithClient) {
+		c.schema = v
+	}
+}
+
+// inlineMedia reports whether media stays inline in the run inputs instead of being uploaded as attachments.
+func (c *Config) inlineMedia() bool {
+	return c.InlineMedia || c.SchemaVersion == SchemaV1
+}
+
+// encodeRun returns run in the format of schema, run is copied if it must be changed.
+func (c *langsmithClient) encodeRun(ctx context.Context, run *Run) *Run {
+	if c.schema != SchemaV1 || (len(run.Events) == 0 && len(run.Attachments) == 0) {
+		return run
+	}
+	encoded := *run
+	encoded.Extra = legacyEventsExtra(run.Extra, run.Events)
+	encoded.Events = nil
+	if len(run.Attachments) > 0 {
+		c.logger.Warn(ctx, "attachments aren't supported by the schema version, dropped", "run_id", run.ID, "count", len(run.Attachments))
+		encoded.Attachments = nil
+	}
+	return &encoded
+}
+
+// encodePatch returns patch in the format of schema, patch is copied if it must be changed.
+func (c *langsmithClient) encodePatch(patch *RunPatch) *RunPatch {
+	if c.schema != SchemaV1 || patch == nil || len(patch.Events) == 0 {
+		return patch
+	}
+	encoded := *patch
+	encoded.Extra = legacyEventsExtra(patch.Extra, patch.Events)
+	encoded.Events = nil
+	return &encoded
+}
+
+// legacyEventsExtra returns a copy of extra with events under ExtraEvents.
+func legacyEventsExtra(extra map[string]interface{}, events []RunEvent) map[string]interface{} {
+	copied := make(map[string]interface{}, len(extra)+1)
+	for k, v := range extra {
+		copied[k] = v
+	}
+	if len(events) > 0 {
+		copied[ExtraEvents] = events
+	}
+	return copied
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSchemaVersion 测试旧版本格式将事件写入 extra 并丢弃附件
+func TestSchemaVersion(t *testing.T) {
+	var bodies []map[string]interface{}
+	var contentTypes []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body := map[string]interface{}{}
+		_ = sonic.Unmarshal(data, &body)
+		bodies = append(bodies, body)
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	events := []RunEvent{{Name: RunEventNewToken, Time: time.Now()}}
+
+	cli := NewLangsmith("test-key", srv.URL, WithSchemaVersion(SchemaV1))
+	run := &Run{ID: "run-1", Extra: map[string]interface{}{"k": "v"}, Events: events,
+		Attachments: map[string]*Attachment{"image": {ContentType: "image/png", Data: []byte("png")}}}
+	assert.NoError(t, cli.CreateRun(ctx, run))
+	assert.NoError(t, cli.UpdateRun(ctx, "run-1", &RunPatch{Events: events}))
+	assert.Len(t, bodies, 2)
+	assert.Equal(t, "application/json", contentTypes[0])
+	assert.Nil(t, bodies[0]["events"])
+	assert.Equal(t, "v", bodies[0]["extra"].(map[string]interface{})["k"])
+	assert.Len(t, bodies[0]["extra"].(map[string]interface{})[ExtraEvents], 1)
+	assert.Nil(t, bodies[1]["events"])
+	assert.Len(t, bodies[1]["extra"].(map[string]interface{})[ExtraEvents], 1)
+	// the caller's run is left untouched
+	assert.Len(t, run.Events, 1)
+	assert.Len(t, run.Attachments, 1)
+	assert.NotContains(t, run.Extra, ExtraEvents)
+
+	// the latest version sends the events field
+	bodies = nil
+	cli = NewLangsmith("test-key", srv.URL)
+	assert.NoError(t, cli.UpdateRun(ctx, "run-1", &RunPatch{Events: events}))
+	assert.Len(t, bodies[0]["events"], 1)
+
+	assert.True(t, (&Config{SchemaVersion: SchemaV1}).inlineMedia())
+	assert.False(t, (&Config{}).inlineMedia())
+}
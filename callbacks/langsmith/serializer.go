@@ -0,0 +1,52 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"github.com/bytedance/sonic"
+	"github.com/cloudwego/eino/callbacks"
+)
+
+// Serializer converts the callbacks.CallbackInput or callbacks.CallbackOutput of a component into the string reported
+// as the "input" or "output" of its run, e.g. to serialize protobuf messages with protojson or to drop noisy fields.
+// Inputs and outputs reported structurally, such as prompts and retrieved documents, don't go through it.
+// It's only called for runs that are sampled, traced by Config.Filter and admitted by the export queue.
+type Serializer interface {
+	Serialize(info *callbacks.RunInfo, v interface{}) (string, error)
+}
+
+// SerializerFunc adapts a function to Serializer
+type SerializerFunc func(info *callbacks.RunInfo, v interface{}) (string, error)
+
+// Serialize implements Serializer
+func (f SerializerFunc) Serialize(info *callbacks.RunInfo, v interface{}) (string, error) {
+	return f(info, v)
+}
+
+type sonicSerializer struct{}
+
+func (sonicSerializer) Serialize(_ *callbacks.RunInfo, v interface{}) (string, error) {
+	return sonic.MarshalString(v)
+}
+
+// serializer returns the configured Serializer, falling back to sonic.
+func (c *Config) serializer() Serializer {
+	if c == nil || c.Serializer == nil {
+		return sonicSerializer{}
+	}
+	return c.Serializer
+}
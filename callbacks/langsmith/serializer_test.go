@@ -0,0 +1,65 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializer(t *testing.T) {
+	type payload struct {
+		Name   string
+		Secret string
+	}
+	info := &callbacks.RunInfo{Name: "lambda", Component: components.Component("Lambda")}
+
+	// default: sonic
+	h := &CallbackHandler{cfg: &Config{}}
+	inputs, err := h.runInputs(info, &payload{Name: "a", Secret: "s"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"Name":"a","Secret":"s"}`, inputs["input"])
+
+	var seen *callbacks.RunInfo
+	h = &CallbackHandler{cfg: &Config{
+		MaxOutputBytes: 40,
+		Serializer: SerializerFunc(func(info *callbacks.RunInfo, v interface{}) (string, error) {
+			seen = info
+			if p, ok := v.(*payload); ok {
+				return "name=" + p.Name, nil
+			}
+			return "", errors.New("unsupported")
+		}),
+	}}
+	inputs, err = h.runInputs(info, &payload{Name: "a", Secret: "s"})
+	require.NoError(t, err)
+	assert.Equal(t, "name=a", inputs["input"])
+	assert.Equal(t, info, seen)
+
+	// limits still apply to serialized payloads
+	outputs, err := h.runOutputs(info, &payload{Name: "0123456789012345678901234567890123456789"})
+	require.NoError(t, err)
+	assert.Contains(t, outputs["output"], "...truncated")
+
+	_, err = h.runOutputs(info, 1)
+	assert.Error(t, err)
+}
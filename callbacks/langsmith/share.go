@@ -0,0 +1,86 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+const opShare APIOp = "share"
+
+type shareResponse struct {
+	ShareToken string `json:"share_token"`
+}
+
+// ShareClient shares traces publicly, it's implemented by the client returned by NewLangsmith.
+type ShareClient interface {
+	ShareRun(ctx context.Context, runID string) (string, error)
+	UnshareRun(ctx context.Context, runID string) error
+}
+
+// ShareRun makes the trace of a run public and returns its share url
+func (c *langsmithClient) ShareRun(ctx context.Context, runID string) (string, error) {
+	resp := &shareResponse{}
+	body := map[string]string{"run_id": runID}
+	if err := c.doJSON(ctx, opShare, "PUT", "/runs/"+url.PathEscape(runID)+"/share", body, resp); err != nil {
+		return "", err
+	}
+	return c.hostURL() + "/public/" + resp.ShareToken + "/r", nil
+}
+
+// UnshareRun revokes the share link of a run
+func (c *langsmithClient) UnshareRun(ctx context.Context, runID string) error {
+	return c.doJSON(ctx, opShare, "DELETE", "/runs/"+url.PathEscape(runID)+"/share", nil, nil)
+}
+
+// hostURL returns the url of the langsmith web app the api url belongs to,
+// e.g. https://eu.api.smith.langchain.com -> https://eu.smith.langchain.com, https://langsmith.example.com/api -> https://langsmith.example.com
+func (c *langsmithClient) hostURL() string {
+	u, err := url.Parse(strings.TrimSuffix(c.baseURL, "/"))
+	if err != nil {
+		return c.baseURL
+	}
+	labels := strings.Split(u.Host, ".")
+	for i, label := range labels {
+		if label == "api" && i < len(labels)-1 {
+			u.Host = strings.Join(append(labels[:i:i], labels[i+1:]...), ".")
+			break
+		}
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/api")
+	return u.String()
+}
+
+// GetShareURLForContext shares the run ctx is traced in and returns its share url, e.g. to put it into an error report.
+func GetShareURLForContext(ctx context.Context, cli ShareClient) (string, error) {
+	runID := CurrentRunID(ctx)
+	if runID == "" {
+		return "", ErrNoRunInContext
+	}
+	return cli.ShareRun(ctx, runID)
+}
+
+// GetShareURL shares the run ctx is traced in, see GetShareURLForContext.
+func (c *CallbackHandler) GetShareURL(ctx context.Context) (string, error) {
+	cli, err := apiClient[ShareClient](c.cli, "share")
+	if err != nil {
+		return "", err
+	}
+	return GetShareURLForContext(ctx, cli)
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/api/runs/run-1/share":
+			_, _ = w.Write([]byte(`{"run_id":"run-1","share_token":"tok-1"}`))
+		case r.Method == "DELETE" && r.URL.Path == "/api/runs/run-1/share":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	cli := NewLangsmith("test-key", srv.URL+"/api").(*langsmithClient)
+	ctx := context.Background()
+
+	link, err := cli.ShareRun(ctx, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL+"/public/tok-1/r", link)
+	assert.NoError(t, cli.UnshareRun(ctx, "run-1"))
+	_, err = cli.ShareRun(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestHostURL(t *testing.T) {
+	assert.Equal(t, "https://smith.langchain.com", (&langsmithClient{baseURL: DefaultLangsmithAPIURL}).hostURL())
+	assert.Equal(t, "https://eu.smith.langchain.com", (&langsmithClient{baseURL: "https://eu.api.smith.langchain.com"}).hostURL())
+	assert.Equal(t, "https://ls.example.com", (&langsmithClient{baseURL: "https://ls.example.com/api/"}).hostURL())
+}
+
+func TestGetShareURL(t *testing.T) {
+	mCli := new(mockLangsmith)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{}}
+
+	_, err := h.GetShareURL(context.Background())
+	assert.ErrorIs(t, err, ErrNoRunInContext)
+
+	ctx := context.WithValue(context.Background(), langsmithStateKey{}, &LangsmithState{TraceID: "trace-1", ParentRunID: "run-1"})
+	mCli.On("ShareRun", mock.Anything, "run-1").Return("https://smith.langchain.com/public/tok-1/r", nil)
+	link, err := h.GetShareURL(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "https://smith.langchain.com/public/tok-1/r", link)
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+)
+
+// Client is the connection to langsmith shared by the CallbackHandler and FlowTrace of an application: the API client
+// with its rate limiter and circuit breaker, the spool and the async export queue. Runs traced by eino callbacks and
+// by manual spans then share the limits and the queue, and are flushed together, e.g.
+//
+//	client, err := langsmith.NewClient(cfg)
+//	callbacks.AppendGlobalHandlers(client.Handler())
+//	ctx, spanID, err := client.FlowTrace().StartSpan(ctx, "request", nil)
+//	defer client.Shutdown(context.Background())
+type Client struct {
+	cfg   *Config
+	cli   Langsmith
+	spool *spooledLangsmith
+	async *asyncExporter // nil in blocking mode
+	info  *ServerInfo    // set with Config.VerifyConnection
+
+	pending    *pendingRuns
+	background *backgroundTasks
+	streams    semaphore
+}
+
+// NewClient validates cfg, see Config.Validate, and connects to langsmith, cfg must not be modified afterwards.
+func NewClient(cfg *Config) (*Client, error) {
+	if cfg.RunIDGen == nil {
+		cfg.RunIDGen = DefaultRunIDGen
+	}
+	warnings, err := cfg.validate()
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range warnings {
+		cfg.logger().Warn(context.Background(), "questionable langsmith config", "problem", warning)
+	}
+	cli := cfg.newClient()
+	c := &Client{
+		cfg:        cfg,
+		cli:        cli,
+		pending:    newPendingRuns(cfg.PendingRunTTL),
+		background: &backgroundTasks{},
+		streams:    newSemaphore(cfg.MaxConcurrentStreams),
+	}
+	if cfg.VerifyConnection && !cfg.DryRun {
+		info, err := cfg.verify(cli)
+		if err != nil {
+			return nil, err
+		}
+		c.info = info
+	}
+	if cfg.SpoolDir != "" && !cfg.DryRun {
+		spool, err := newDiskSpool(cfg.SpoolDir, cfg.logger())
+		if err != nil {
+			return nil, err
+		}
+		c.spool = newSpooledLangsmith(cli, spool, cfg.SpoolReplayInterval, cfg.logger())
+		c.cli = c.spool
+	}
+	if !cfg.Blocking {
+		c.async = newAsyncExporter(c.cli, cfg, cfg.QueueSize, defaultExportWorkers)
+	}
+	c.Handler().startReaper()
+	return c, nil
+}
+
+// Handler returns a CallbackHandler exporting runs through c.
+func (c *Client) Handler() *CallbackHandler {
+	return &CallbackHandler{
+		cli:   c.cli,
+		cfg:   c.cfg,
+		spool: c.spool,
+		async: c.async,
+		info:  c.info,
+
+		pending:    c.pending,
+		background: c.background,
+		streams:    c.streams,
+	}
+}
+
+// FlowTrace returns a FlowTrace exporting spans through c. Spans are created synchronously, as StartSpan reports
+// the error of the creation, but share the limits and the spool of c.
+func (c *Client) FlowTrace() *FlowTrace {
+	return &FlowTrace{cli: c.cli, cfg: c.cfg}
+}
+
+// API returns the langsmith API client of c, e.g. to post feedback or manage datasets, see Langsmith for the
+// interfaces it implements.
+func (c *Client) API() Langsmith {
+	return rootClient(c.cli)
+}
+
+// ServerInfo returns the info of the langsmith deployment fetched with Config.VerifyConnection, nil if not set.
+func (c *Client) ServerInfo() *ServerInfo {
+	return c.info
+}
+
+// Flush terminates the runs pending for longer than Config.PendingRunTTL, then blocks until the evaluators and
+// AddRunToDataset of the ended root runs are done and all runs queued so far are exported, or ctx is done.
+func (c *Client) Flush(ctx context.Context) error {
+	return c.Handler().Flush(ctx)
+}
+
+// Shutdown terminates the runs of its handlers still pending with an error tagged TagAbandoned, waits for the
+// evaluators and AddRunToDataset of the ended root runs, exports queued runs and stops the background workers of c, runs reported
+// afterwards are dropped. Spooled requests are kept on disk.
+func (c *Client) Shutdown(ctx context.Context) error {
+	return c.Handler().Shutdown(ctx)
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClient 测试 handler 与 FlowTrace 共享同一个 Client
+func TestClient(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(&Config{APIKey: "test-key", APIURL: srv.URL})
+	require.NoError(t, err)
+	h, ft := client.Handler(), client.FlowTrace()
+	assert.Same(t, client.API(), h.cli)
+	assert.Same(t, client.API(), ft.cli)
+	assert.Same(t, client.async, h.async)
+	assert.Same(t, client.API(), h.FlowTrace().cli)
+
+	ctx, spanID, err := ft.StartSpan(context.Background(), "request", nil)
+	require.NoError(t, err)
+	info := &callbacks.RunInfo{Name: "node"}
+	h.OnEnd(h.OnStart(ctx, info, "in"), info, "out")
+	ft.FinishSpan(ctx, spanID)
+	require.NoError(t, client.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, requests, 4)
+	assert.Contains(t, requests, "PATCH /runs/"+spanID)
+	assert.Equal(t, 2, countString(requests, "POST /runs"))
+
+	_, err = NewClient(&Config{APIKey: "test-key", Langfuse: &LangfuseConfig{}})
+	assert.Error(t, err)
+}
+
+func countString(list []string, s string) int {
+	n := 0
+	for _, v := range list {
+		if v == s {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,338 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+const (
+	// DefaultSpoolReplayInterval is how often spooled requests are re-submitted by default.
+	DefaultSpoolReplayInterval = 30 * time.Second
+
+	spoolActiveFile  = "active.jsonl"
+	spoolMaxAttempts = 10
+)
+
+// spoolRecord is one failed request persisted in the spool.
+type spoolRecord struct {
+	Op       ExportOp  `json:"op"`
+	Run      *Run      `json:"run,omitempty"`
+	RunID    string    `json:"run_id,omitempty"`
+	Patch    *RunPatch `json:"patch,omitempty"`
+	Attempts int       `json:"attempts"`
+
+	// attachments of the created run, not part of its JSON
+	Attachments map[string]*Attachment `json:"attachments,omitempty"`
+
+	// credentials of the trace set by WithAPIKey and WithWorkspaceID, the request is replayed with them
+	APIKey      string `json:"api_key,omitempty"`
+	WorkspaceID string `json:"workspace_id,omitempty"`
+}
+
+// newCreateRecord records the creation of run with its attachments.
+func newCreateRecord(run *Run) *spoolRecord {
+	return &spoolRecord{Op: ExportOpCreate, Run: run, Attachments: run.Attachments}
+}
+
+// withCredentials records the credentials of the trace ctx is traced in.
+func (r *spoolRecord) withCredentials(ctx context.Context) *spoolRecord {
+	r.APIKey, r.WorkspaceID = requestCredentials(ctx)
+	return r
+}
+
+func (r *spoolRecord) send(ctx context.Context, cli RunExporter) error {
+	if r.APIKey != "" || r.WorkspaceID != "" {
+		ctx = context.WithValue(ctx, langsmithTraceOptionKey{}, &traceOptions{APIKey: r.APIKey, WorkspaceID: r.WorkspaceID})
+	}
+	switch r.Op {
+	case ExportOpCreate:
+		if r.Run != nil && len(r.Attachments) > 0 {
+			r.Run.Attachments = r.Attachments
+		}
+		return cli.CreateRun(ctx, r.Run)
+	case ExportOpUpdate:
+		return cli.UpdateRun(ctx, r.RunID, r.Patch)
+	default:
+		return fmt.Errorf("unknown spool op: %s", r.Op)
+	}
+}
+
+// diskSpool is an append-only JSON Lines spool in a directory.
+// new records go to the active file, which is sealed into a timestamped segment before every replay.
+type diskSpool struct {
+	dir    string
+	logger Logger
+	mu     sync.Mutex
+}
+
+func newDiskSpool(dir string, logger Logger) (*diskSpool, error) {
+	// records may hold the API keys set with WithAPIKey
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create spool dir: %w", err)
+	}
+	return &diskSpool{dir: dir, logger: logger}, nil
+}
+
+func (s *diskSpool) append(rec *spoolRecord) error {
+	line, err := sonic.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal spool record: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(filepath.Join(s.dir, spoolActiveFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open spool file: %w", err)
+	}
+	if _, err = f.Write(line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write spool file: %w", err)
+	}
+	return f.Close()
+}
+
+// seal renames the active file into a segment so that replay never races with append.
+func (s *diskSpool) seal() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	active := filepath.Join(s.dir, spoolActiveFile)
+	if _, err := os.Stat(active); os.IsNotExist(err) {
+		return nil
+	}
+	segment := filepath.Join(s.dir, fmt.Sprintf("%020d.jsonl", time.Now().UnixNano()))
+	return os.Rename(active, segment)
+}
+
+// segments returns sealed segments, oldest first.
+func (s *diskSpool) segments() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == spoolActiveFile || !strings.HasSuffix(e.Name(), ".jsonl") {
+			continue
+		}
+		files = append(files, filepath.Join(s.dir, e.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// replay re-submits spooled records in order. it stops at the first failure, as the endpoint is most
+// likely still unhealthy, and keeps the remaining records for the next round. Records rejected by langsmith are
+// dropped, sending them again can't succeed.
+func (s *diskSpool) replay(ctx context.Context, cli Langsmith) (int, error) {
+	if err := s.seal(); err != nil {
+		return 0, fmt.Errorf("failed to seal spool file: %w", err)
+	}
+	files, err := s.segments()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list spool files: %w", err)
+	}
+	replayed := 0
+	for _, file := range files {
+		records, err := readSpoolFile(file)
+		if err != nil {
+			s.logger.Error(ctx, "dropping unreadable spool file", "file", file, "err", err)
+			_ = os.Remove(file)
+			continue
+		}
+		for i, rec := range records {
+			if err = rec.send(ctx, cli); err == nil {
+				replayed++
+				continue
+			}
+			if isRejected(err) {
+				s.logger.Warn(ctx, "dropping spooled request rejected by langsmith", "op", rec.Op, "run_id", rec.runID(), "err", err)
+				continue
+			}
+			rec.Attempts++
+			if rec.Attempts >= spoolMaxAttempts {
+				s.logger.Warn(ctx, "dropping spooled request after too many attempts", "op", rec.Op, "run_id", rec.runID(), "err", err)
+				i++
+			}
+			if werr := writeSpoolFile(file, records[i:]); werr != nil {
+				return replayed, fmt.Errorf("failed to rewrite spool file: %w", werr)
+			}
+			return replayed, err
+		}
+		if err = os.Remove(file); err != nil {
+			return replayed, fmt.Errorf("failed to remove spool file: %w", err)
+		}
+	}
+	return replayed, nil
+}
+
+func (r *spoolRecord) runID() string {
+	if r.Run != nil {
+		return r.Run.ID
+	}
+	return r.RunID
+}
+
+func readSpoolFile(file string) ([]*spoolRecord, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []*spoolRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		rec := &spoolRecord{}
+		if err = sonic.Unmarshal(scanner.Bytes(), rec); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+func writeSpoolFile(file string, records []*spoolRecord) error {
+	if len(records) == 0 {
+		return os.Remove(file)
+	}
+	tmp := file + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, rec := range records {
+		line, err := sonic.Marshal(rec)
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
+		_, _ = w.Write(line)
+		_ = w.WriteByte('\n')
+	}
+	if err = w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// spooledLangsmith writes failed requests into a diskSpool and periodically replays them.
+type spooledLangsmith struct {
+	Langsmith
+	spool  *diskSpool
+	logger Logger
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newSpooledLangsmith(cli Langsmith, spool *diskSpool, interval time.Duration, logger Logger) *spooledLangsmith {
+	if interval <= 0 {
+		interval = DefaultSpoolReplayInterval
+	}
+	s := &spooledLangsmith{
+		Langsmith: cli,
+		spool:     spool,
+		logger:    logger,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go s.replayLoop(interval)
+	return s
+}
+
+func (s *spooledLangsmith) unwrap() Langsmith {
+	return s.Langsmith
+}
+
+func (s *spooledLangsmith) CreateRun(ctx context.Context, run *Run) error {
+	err := s.Langsmith.CreateRun(ctx, run)
+	if err != nil && !isRejected(err) {
+		s.save(ctx, newCreateRecord(run).withCredentials(ctx))
+	}
+	return err
+}
+
+// UpdateRun spools the updates of unknown runs too, their create may be spooled, and is replayed before them.
+func (s *spooledLangsmith) UpdateRun(ctx context.Context, runID string, patch *RunPatch) error {
+	err := s.Langsmith.UpdateRun(ctx, runID, patch)
+	if err != nil && (!isRejected(err) || errors.Is(err, ErrNotFound)) {
+		s.save(ctx, (&spoolRecord{Op: ExportOpUpdate, RunID: runID, Patch: patch}).withCredentials(ctx))
+	}
+	return err
+}
+
+func (s *spooledLangsmith) save(ctx context.Context, rec *spoolRecord) {
+	if err := s.spool.append(rec); err != nil {
+		s.logger.Error(ctx, "failed to spool request, run dropped", "op", rec.Op, "run_id", rec.runID(), "err", err)
+		return
+	}
+	s.logger.Warn(ctx, "request failed, spooled for replay", "op", rec.Op, "run_id", rec.runID())
+}
+
+func (s *spooledLangsmith) replayLoop(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			ctx := context.Background()
+			n, err := s.spool.replay(ctx, s.Langsmith)
+			if n > 0 {
+				s.logger.Debug(ctx, "replayed spooled requests", "count", n)
+			}
+			if err != nil {
+				s.logger.Debug(ctx, "spool replay stopped", "err", err)
+			}
+		}
+	}
+}
+
+// close stops the replay loop, spooled records stay on disk for the next process.
+func (s *spooledLangsmith) close() {
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	<-s.done
+}
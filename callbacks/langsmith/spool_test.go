@@ -0,0 +1,224 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskSpoolReplay(t *testing.T) {
+	spool, err := newDiskSpool(t.TempDir(), &recordLogger{})
+	require.NoError(t, err)
+
+	require.NoError(t, spool.append(&spoolRecord{Op: ExportOpCreate, Run: &Run{ID: "run-1"}}))
+	require.NoError(t, spool.append(&spoolRecord{Op: ExportOpUpdate, RunID: "run-1", Patch: &RunPatch{}}))
+
+	t.Run("endpoint still down", func(t *testing.T) {
+		mCli := new(mockLangsmith)
+		mCli.On("CreateRun", mock.Anything, mock.Anything).Return(errors.New("down"))
+
+		n, err := spool.replay(context.Background(), mCli)
+		assert.Error(t, err)
+		assert.Equal(t, 0, n)
+		mCli.AssertNotCalled(t, "UpdateRun", mock.Anything, mock.Anything, mock.Anything)
+
+		files, _ := spool.segments()
+		require.Len(t, files, 1)
+		records, _ := readSpoolFile(files[0])
+		require.Len(t, records, 2)
+		assert.Equal(t, 1, records[0].Attempts)
+	})
+
+	t.Run("endpoint recovered", func(t *testing.T) {
+		mCli := new(mockLangsmith)
+		mCli.On("CreateRun", mock.Anything, mock.MatchedBy(func(r *Run) bool { return r.ID == "run-1" })).Return(nil)
+		mCli.On("UpdateRun", mock.Anything, "run-1", mock.Anything).Return(nil)
+
+		n, err := spool.replay(context.Background(), mCli)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		files, _ := spool.segments()
+		assert.Empty(t, files)
+	})
+}
+
+func TestDiskSpoolDropsAfterMaxAttempts(t *testing.T) {
+	spool, err := newDiskSpool(t.TempDir(), &recordLogger{})
+	require.NoError(t, err)
+	require.NoError(t, spool.append(&spoolRecord{Op: ExportOpCreate, Run: &Run{ID: "run-1"}, Attempts: spoolMaxAttempts - 1}))
+
+	mCli := new(mockLangsmith)
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(errors.New("bad request"))
+	_, err = spool.replay(context.Background(), mCli)
+	assert.Error(t, err)
+
+	files, _ := spool.segments()
+	assert.Empty(t, files)
+}
+
+func TestSpooledLangsmith(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := newDiskSpool(dir, &recordLogger{})
+	require.NoError(t, err)
+
+	down := new(mockLangsmith)
+	down.On("CreateRun", mock.Anything, mock.Anything).Return(errors.New("down"))
+	cli := newSpooledLangsmith(down, spool, time.Hour, &recordLogger{})
+	defer cli.close()
+
+	assert.Error(t, cli.CreateRun(context.Background(), &Run{ID: "run-1"}))
+
+	require.NoError(t, spool.seal())
+	files, _ := spool.segments()
+	require.Len(t, files, 1)
+	records, _ := readSpoolFile(files[0])
+	require.Len(t, records, 1)
+	assert.Equal(t, "run-1", records[0].Run.ID)
+}
+
+func TestSpooledLangsmithSkipsRejected(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := newDiskSpool(dir, &recordLogger{})
+	require.NoError(t, err)
+
+	rejecting := new(mockLangsmith)
+	rejecting.On("UpdateRun", mock.Anything, "run-1", mock.Anything).Return(&APIError{StatusCode: 413})
+	cli := newSpooledLangsmith(rejecting, spool, time.Hour, &recordLogger{})
+	defer cli.close()
+
+	assert.ErrorIs(t, cli.UpdateRun(context.Background(), "run-1", &RunPatch{}), ErrPayloadTooLarge)
+	require.NoError(t, spool.seal())
+	files, _ := spool.segments()
+	assert.Empty(t, files)
+}
+
+// TestSpooledLangsmithUpdateOfSpooledCreate 测试 create 被 spool 后，update 返回 404 也会被 spool，并在 create 之后重放
+func TestSpooledLangsmithUpdateOfSpooledCreate(t *testing.T) {
+	spool, err := newDiskSpool(t.TempDir(), &recordLogger{})
+	require.NoError(t, err)
+
+	down := new(mockLangsmith)
+	down.On("CreateRun", mock.Anything, mock.Anything).Return(errors.New("down"))
+	down.On("UpdateRun", mock.Anything, "run-1", mock.Anything).Return(&APIError{StatusCode: 404})
+	cli := newSpooledLangsmith(down, spool, time.Hour, &recordLogger{})
+	defer cli.close()
+
+	assert.Error(t, cli.CreateRun(context.Background(), &Run{ID: "run-1"}))
+	assert.ErrorIs(t, cli.UpdateRun(context.Background(), "run-1", &RunPatch{}), ErrNotFound)
+
+	var order []string
+	up := new(mockLangsmith)
+	up.On("CreateRun", mock.Anything, mock.Anything).Return(nil).Run(func(mock.Arguments) { order = append(order, "create") })
+	up.On("UpdateRun", mock.Anything, "run-1", mock.Anything).Return(nil).Run(func(mock.Arguments) { order = append(order, "update") })
+	replayed, err := spool.replay(context.Background(), up)
+	require.NoError(t, err)
+	assert.Equal(t, 2, replayed)
+	assert.Equal(t, []string{"create", "update"}, order)
+	files, _ := spool.segments()
+	assert.Empty(t, files)
+}
+
+// TestDiskSpoolDropsRejected 测试重放时被拒绝的请求直接丢弃，不阻塞后续请求
+func TestDiskSpoolDropsRejected(t *testing.T) {
+	spool, err := newDiskSpool(t.TempDir(), &recordLogger{})
+	require.NoError(t, err)
+	require.NoError(t, spool.append(&spoolRecord{Op: ExportOpUpdate, RunID: "run-1", Patch: &RunPatch{}}))
+	require.NoError(t, spool.append(&spoolRecord{Op: ExportOpCreate, Run: &Run{ID: "run-2"}}))
+
+	mCli := new(mockLangsmith)
+	mCli.On("UpdateRun", mock.Anything, "run-1", mock.Anything).Return(&APIError{StatusCode: 404})
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	replayed, err := spool.replay(context.Background(), mCli)
+	require.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+	files, _ := spool.segments()
+	assert.Empty(t, files)
+}
+
+func TestHandlerWithSpool(t *testing.T) {
+	h, err := NewLangsmithHandler(&Config{APIKey: "test-key", SpoolDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.NotNil(t, h.spool)
+	assert.NoError(t, h.Shutdown(context.Background()))
+}
+
+// TestSpoolKeepsTraceCredentials 测试重放时使用 trace 自己的 API key 和 workspace
+func TestSpoolKeepsTraceCredentials(t *testing.T) {
+	var keys, workspaces []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("x-api-key"))
+		workspaces = append(workspaces, r.Header.Get(WorkspaceIDHeader))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	spool, err := newDiskSpool(dir, &recordLogger{})
+	require.NoError(t, err)
+	down := new(mockLangsmith)
+	down.On("CreateRun", mock.Anything, mock.Anything).Return(errors.New("down"))
+	cli := newSpooledLangsmith(down, spool, time.Hour, &recordLogger{})
+	defer cli.close()
+
+	tenant := SetTrace(context.Background(), WithAPIKey("tenant-key"), WithWorkspaceID("ws-tenant"))
+	assert.Error(t, cli.CreateRun(tenant, &Run{ID: "run-1"}))
+	assert.Error(t, cli.CreateRun(context.Background(), &Run{ID: "run-2"}))
+	stat, err := os.Stat(filepath.Join(dir, spoolActiveFile))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), stat.Mode().Perm())
+
+	n, err := spool.replay(context.Background(), NewLangsmith("operator-key", srv.URL))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"tenant-key", "operator-key"}, keys)
+	assert.Equal(t, []string{"ws-tenant", ""}, workspaces)
+}
+
+// TestSpoolKeepsAttachments 测试重放时上传 run 的附件，输入中的 attachment:// 引用不会失效
+func TestSpoolKeepsAttachments(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := newDiskSpool(dir, &recordLogger{})
+	require.NoError(t, err)
+	down := new(mockLangsmith)
+	down.On("CreateRun", mock.Anything, mock.Anything).Return(errors.New("down"))
+	cli := newSpooledLangsmith(down, spool, time.Hour, &recordLogger{})
+	defer cli.close()
+
+	image := &Attachment{ContentType: "image/png", Data: []byte{0x89, 'P', 'N', 'G'}}
+	assert.Error(t, cli.CreateRun(context.Background(), &Run{ID: "run-1", Attachments: map[string]*Attachment{"image_0": image}}))
+
+	var replayed *Run
+	up := new(mockLangsmith)
+	up.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		replayed = args.Get(1).(*Run)
+	}).Return(nil)
+	n, err := spool.replay(context.Background(), up)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.NotNil(t, replayed)
+	assert.Equal(t, map[string]*Attachment{"image_0": image}, replayed.Attachments)
+}
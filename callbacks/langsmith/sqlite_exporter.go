@@ -0,0 +1,167 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// DefaultSQLiteTable is the table SQLiteExporter stores runs in by default.
+const DefaultSQLiteTable = "langsmith_runs"
+
+const sqliteUploadBatch = 100
+
+var sqliteTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLiteExporterConfig configures NewSQLiteExporter
+type SQLiteExporterConfig struct {
+	// DB is an opened SQLite database, the driver is registered by the application, e.g. by importing
+	// modernc.org/sqlite or github.com/mattn/go-sqlite3. SQLite allows a single writer, limiting the pool with
+	// DB.SetMaxOpenConns(1) avoids "database is locked" errors. required
+	DB *sql.DB
+	// Table the runs are stored in, it's created if it doesn't exist. default: DefaultSQLiteTable
+	Table string
+}
+
+// SQLiteExporter is a RunExporter storing runs in a local SQLite database, e.g. for CLI tools and batch jobs without
+// network access, and uploading them to langsmith later with Upload. The runs keep their ids, trace ids and dotted
+// orders, attachments, and the credentials set with WithAPIKey and WithWorkspaceID. Set it as Config.Exporter.
+type SQLiteExporter struct {
+	db    *sql.DB
+	table string
+}
+
+// NewSQLiteExporter creates the table of the exporter if it doesn't exist.
+func NewSQLiteExporter(ctx context.Context, cfg *SQLiteExporterConfig) (*SQLiteExporter, error) {
+	if cfg == nil || cfg.DB == nil {
+		return nil, fmt.Errorf("sqlite exporter db is required")
+	}
+	table := cfg.Table
+	if table == "" {
+		table = DefaultSQLiteTable
+	}
+	if !sqliteTableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid sqlite exporter table name: %q", table)
+	}
+	e := &SQLiteExporter{db: cfg.DB, table: table}
+	_, err := e.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+table+
+		" (seq INTEGER PRIMARY KEY AUTOINCREMENT, op TEXT NOT NULL, run_id TEXT NOT NULL, record BLOB NOT NULL, created_at INTEGER NOT NULL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sqlite exporter table: %w", err)
+	}
+	return e, nil
+}
+
+// CreateRun implements RunExporter
+func (e *SQLiteExporter) CreateRun(ctx context.Context, run *Run) error {
+	return e.insert(ctx, newCreateRecord(run).withCredentials(ctx))
+}
+
+// UpdateRun implements RunExporter
+func (e *SQLiteExporter) UpdateRun(ctx context.Context, runID string, patch *RunPatch) error {
+	return e.insert(ctx, (&spoolRecord{Op: ExportOpUpdate, RunID: runID, Patch: patch}).withCredentials(ctx))
+}
+
+func (e *SQLiteExporter) insert(ctx context.Context, rec *spoolRecord) error {
+	data, err := sonic.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sqlite record: %w", err)
+	}
+	_, err = e.db.ExecContext(ctx, "INSERT INTO "+e.table+" (op, run_id, record, created_at) VALUES (?, ?, ?, ?)",
+		string(rec.Op), rec.runID(), data, time.Now().UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to insert sqlite record: %w", err)
+	}
+	return nil
+}
+
+// Upload sends the stored runs to cli in the order they were recorded and deletes them once sent, requests rejected
+// by langsmith and unreadable records are dropped too. It stops at the first other failure, the remaining runs are
+// uploaded by the next call. The number of uploaded requests is returned.
+func (e *SQLiteExporter) Upload(ctx context.Context, cli RunExporter) (int, error) {
+	uploaded := 0
+	for {
+		seqs, records, err := e.next(ctx)
+		if err != nil {
+			return uploaded, err
+		}
+		if len(records) == 0 {
+			return uploaded, nil
+		}
+		for i, rec := range records {
+			if rec == nil {
+				// unreadable records would block the upload forever
+				err = nil
+			} else if err = rec.send(ctx, cli); err != nil && !isRejected(err) {
+				return uploaded, fmt.Errorf("failed to upload run %s: %w", rec.runID(), err)
+			}
+			if rec != nil && err == nil {
+				uploaded++
+			}
+			if _, err = e.db.ExecContext(ctx, "DELETE FROM "+e.table+" WHERE seq = ?", seqs[i]); err != nil {
+				return uploaded, fmt.Errorf("failed to delete uploaded sqlite record: %w", err)
+			}
+		}
+	}
+}
+
+// Pending returns the number of stored requests not uploaded yet.
+func (e *SQLiteExporter) Pending(ctx context.Context) (int, error) {
+	var n int
+	if err := e.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+e.table).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count sqlite records: %w", err)
+	}
+	return n, nil
+}
+
+// next reads the oldest batch of stored records, unreadable records are returned as nil.
+func (e *SQLiteExporter) next(ctx context.Context) ([]int64, []*spoolRecord, error) {
+	rows, err := e.db.QueryContext(ctx, "SELECT seq, record FROM "+e.table+" ORDER BY seq LIMIT ?", sqliteUploadBatch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read sqlite records: %w", err)
+	}
+	defer rows.Close()
+	var (
+		seqs    []int64
+		records []*spoolRecord
+	)
+	for rows.Next() {
+		var (
+			seq  int64
+			data []byte
+		)
+		if err = rows.Scan(&seq, &data); err != nil {
+			return nil, nil, fmt.Errorf("failed to read sqlite record: %w", err)
+		}
+		rec := &spoolRecord{}
+		if sonic.Unmarshal(data, rec) != nil {
+			rec = nil
+		}
+		seqs = append(seqs, seq)
+		records = append(records, rec)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read sqlite records: %w", err)
+	}
+	return seqs, records, nil
+}
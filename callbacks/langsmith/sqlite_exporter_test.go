@@ -0,0 +1,164 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSQLite 是一个仅支持 SQLiteExporter 所用语句的内存 database/sql 驱动
+type fakeSQLite struct {
+	mu      sync.Mutex
+	created bool
+	seq     int64
+	rows    []fakeSQLiteRow
+}
+
+type fakeSQLiteRow struct {
+	seq    int64
+	record []byte
+}
+
+func (f *fakeSQLite) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeSQLiteConn{f}, nil
+}
+func (f *fakeSQLite) Driver() driver.Driver { return nil }
+
+type fakeSQLiteConn struct{ db *fakeSQLite }
+
+func (c *fakeSQLiteConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (c *fakeSQLiteConn) Close() error              { return nil }
+func (c *fakeSQLiteConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (c *fakeSQLiteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	f := c.db
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS langsmith_runs "):
+		f.created = true
+	case strings.HasPrefix(query, "INSERT INTO langsmith_runs "):
+		f.seq++
+		f.rows = append(f.rows, fakeSQLiteRow{seq: f.seq, record: args[2].Value.([]byte)})
+	case strings.HasPrefix(query, "DELETE FROM langsmith_runs WHERE seq = ?"):
+		for i, row := range f.rows {
+			if row.seq == args[0].Value.(int64) {
+				f.rows = append(f.rows[:i], f.rows[i+1:]...)
+				break
+			}
+		}
+	default:
+		return nil, errors.New("unexpected query: " + query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeSQLiteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	f := c.db
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, "SELECT COUNT(*) FROM langsmith_runs"):
+		return &fakeSQLiteRows{cols: []string{"count"}, values: [][]driver.Value{{int64(len(f.rows))}}}, nil
+	case strings.HasPrefix(query, "SELECT seq, record FROM langsmith_runs ORDER BY seq LIMIT ?"):
+		rows := &fakeSQLiteRows{cols: []string{"seq", "record"}}
+		for i, row := range f.rows {
+			if int64(i) >= args[0].Value.(int64) {
+				break
+			}
+			rows.values = append(rows.values, []driver.Value{row.seq, row.record})
+		}
+		return rows, nil
+	}
+	return nil, errors.New("unexpected query: " + query)
+}
+
+type fakeSQLiteRows struct {
+	cols   []string
+	values [][]driver.Value
+}
+
+func (r *fakeSQLiteRows) Columns() []string { return r.cols }
+func (r *fakeSQLiteRows) Close() error      { return nil }
+func (r *fakeSQLiteRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// TestSQLiteExporter 测试本地存储的 run 在稍后上传时保留 trace id 与 dotted order
+func TestSQLiteExporter(t *testing.T) {
+	ctx := context.Background()
+	_, err := NewSQLiteExporter(ctx, nil)
+	assert.Error(t, err)
+	store := &fakeSQLite{}
+	db := sql.OpenDB(store)
+	defer db.Close()
+	_, err = NewSQLiteExporter(ctx, &SQLiteExporterConfig{DB: db, Table: "runs; DROP TABLE x"})
+	assert.ErrorContains(t, err, "invalid sqlite exporter table name")
+
+	exporter, err := NewSQLiteExporter(ctx, &SQLiteExporterConfig{DB: db})
+	require.NoError(t, err)
+	assert.True(t, store.created)
+
+	run := bundleTrace()[1]
+	require.NoError(t, exporter.CreateRun(ctx, run))
+	require.NoError(t, exporter.UpdateRun(ctx, run.ID, &RunPatch{Outputs: map[string]interface{}{"out": "ok"}}))
+	require.NoError(t, exporter.UpdateRun(ctx, "rejected", &RunPatch{}))
+	store.rows = append(store.rows, fakeSQLiteRow{seq: 100, record: []byte("corrupted")})
+	n, err := exporter.Pending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+
+	mCli := &mockLangsmith{}
+	mCli.On("CreateRun", ctx, mock.MatchedBy(func(r *Run) bool {
+		return r.ID == run.ID && r.TraceID == run.TraceID && r.DottedOrder == run.DottedOrder
+	})).Return(nil).Once()
+	mCli.On("UpdateRun", ctx, run.ID, mock.Anything).Return(errors.New("offline")).Once()
+
+	// the failed request is kept for the next upload
+	uploaded, err := exporter.Upload(ctx, mCli)
+	assert.ErrorContains(t, err, "offline")
+	assert.Equal(t, 1, uploaded)
+	n, _ = exporter.Pending(ctx)
+	assert.Equal(t, 3, n)
+
+	mCli.On("UpdateRun", ctx, run.ID, mock.MatchedBy(func(p *RunPatch) bool { return p.Outputs["out"] == "ok" })).Return(nil).Once()
+	mCli.On("UpdateRun", ctx, "rejected", mock.Anything).Return(&APIError{StatusCode: 422}).Once()
+	uploaded, err = exporter.Upload(ctx, mCli)
+	require.NoError(t, err)
+	assert.Equal(t, 1, uploaded)
+	n, _ = exporter.Pending(ctx)
+	assert.Equal(t, 0, n)
+	mCli.AssertExpectations(t)
+}
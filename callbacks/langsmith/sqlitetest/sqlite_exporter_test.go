@@ -0,0 +1,86 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sqlitetest
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/cloudwego/eino-ext/callbacks/langsmith"
+	"github.com/cloudwego/eino-ext/callbacks/langsmith/langsmithtest"
+)
+
+func openDB(t *testing.T, path string) *sql.DB {
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	return db
+}
+
+// TestSQLiteExporter 测试 run 写入真实的 SQLite 文件，重新打开后按顺序上传并删除
+func TestSQLiteExporter(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "runs.db")
+
+	db := openDB(t, path)
+	exporter, err := langsmith.NewSQLiteExporter(ctx, &langsmith.SQLiteExporterConfig{DB: db})
+	require.NoError(t, err)
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	run := &langsmith.Run{ID: "run-1", TraceID: "run-1", Name: "graph", RunType: langsmith.RunTypeChain,
+		StartTime: start, Inputs: map[string]interface{}{"q": "hi"}}
+	require.NoError(t, exporter.CreateRun(ctx, run))
+	end := start.Add(time.Second)
+	require.NoError(t, exporter.UpdateRun(ctx, "run-1", &langsmith.RunPatch{EndTime: &end,
+		Outputs: map[string]interface{}{"a": "hello"}}))
+	require.NoError(t, db.Close())
+
+	// the runs survive the process, the table already exists
+	db = openDB(t, path)
+	defer db.Close()
+	exporter, err = langsmith.NewSQLiteExporter(ctx, &langsmith.SQLiteExporterConfig{DB: db})
+	require.NoError(t, err)
+	n, err := exporter.Pending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	target := langsmithtest.NewExporter()
+	uploaded, err := exporter.Upload(ctx, target)
+	require.NoError(t, err)
+	assert.Equal(t, 2, uploaded)
+	runs := target.Runs()
+	require.Len(t, runs, 1)
+	assert.Equal(t, "run-1", runs[0].ID)
+	assert.True(t, start.Equal(runs[0].StartTime))
+	assert.Equal(t, map[string]interface{}{"q": "hi"}, runs[0].Inputs)
+	patches := target.Patches("run-1")
+	require.Len(t, patches, 1)
+	assert.Equal(t, map[string]interface{}{"a": "hello"}, patches[0].Outputs)
+
+	n, err = exporter.Pending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	uploaded, err = exporter.Upload(ctx, target)
+	require.NoError(t, err)
+	assert.Zero(t, uploaded)
+}
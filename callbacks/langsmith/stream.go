@@ -0,0 +1,176 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// streamChunksHint preallocates the chunks of a streamed output, model streams usually have dozens of them.
+const streamChunksHint = 32
+
+// partialUpdater decides when a long stream output is patched with its intermediate output.
+type partialUpdater struct {
+	everyChunks int
+	interval    time.Duration
+
+	chunks int
+	last   time.Time
+
+	message  *schema.Message // output concatenated by the last update
+	consumed int             // chunks concatenated in message
+}
+
+// newPartialUpdater returns nil if partial updates are disabled.
+func (c *CallbackHandler) newPartialUpdater() *partialUpdater {
+	if c.cfg.HideOutputs || (c.cfg.StreamUpdateChunks <= 0 && c.cfg.StreamUpdateInterval <= 0) {
+		return nil
+	}
+	return &partialUpdater{everyChunks: c.cfg.StreamUpdateChunks, interval: c.cfg.StreamUpdateInterval, last: time.Now()}
+}
+
+// due is called for every received chunk and reports whether a partial update should be sent.
+func (p *partialUpdater) due() bool {
+	if p == nil {
+		return false
+	}
+	p.chunks++
+	now := time.Now()
+	if (p.everyChunks > 0 && p.chunks >= p.everyChunks) || (p.interval > 0 && now.Sub(p.last) >= p.interval) {
+		p.chunks = 0
+		p.last = now
+		return true
+	}
+	return false
+}
+
+// concat appends the chunks received since the last update to the output concatenated so far, so that every chunk is
+// concatenated once however long the stream is.
+func (p *partialUpdater) concat(outputs []callbacks.CallbackOutput) (*schema.Message, error) {
+	messages := make([]*schema.Message, 0, len(outputs)-p.consumed+1)
+	if p.message != nil {
+		messages = append(messages, p.message)
+	}
+	for _, out := range convModelCallbackOutput(outputs[p.consumed:]) {
+		if out != nil && out.Message != nil {
+			messages = append(messages, out.Message)
+		}
+	}
+	p.consumed = len(outputs)
+	if len(messages) == 0 {
+		return &schema.Message{}, nil
+	}
+	message, err := schema.ConcatMessages(messages)
+	if err != nil {
+		return nil, fmt.Errorf("concat message failed: %v", err)
+	}
+	p.message = message
+	return message, nil
+}
+
+// updatePartialOutput patches a running stream with the output concatenated so far, the run isn't ended.
+func (c *CallbackHandler) updatePartialOutput(ctx context.Context, info *callbacks.RunInfo, runID string, partial *partialUpdater, outputs []callbacks.CallbackOutput) {
+	outMessage, err := partial.concat(outputs)
+	if err != nil {
+		c.cfg.logger().Debug(ctx, "extract partial stream output error", "err", err, "run_info", info)
+		return
+	}
+	c.updateRun(ctx, runID, &RunPatch{
+		Outputs: map[string]interface{}{"stream_outputs": limitPayload(outMessage, c.cfg.MaxOutputBytes)},
+	})
+}
+
+// chunkCapture keeps the first chunks of a streamed output as new_token events, see Config.CaptureStreamChunks.
+type chunkCapture struct {
+	cfg    *Config
+	info   *callbacks.RunInfo
+	events []RunEvent
+}
+
+// newChunkCapture returns nil if chunks aren't captured.
+func (c *CallbackHandler) newChunkCapture(info *callbacks.RunInfo) *chunkCapture {
+	if c.cfg.CaptureStreamChunks <= 0 {
+		return nil
+	}
+	return &chunkCapture{cfg: c.cfg, info: info}
+}
+
+// add records chunk received at t, chunks beyond the limit are only counted by the caller.
+func (cc *chunkCapture) add(chunk callbacks.CallbackOutput, t time.Time) {
+	if cc == nil || len(cc.events) >= cc.cfg.CaptureStreamChunks {
+		return
+	}
+	kwargs := map[string]interface{}{"index": len(cc.events)}
+	if !cc.cfg.HideOutputs {
+		if out := model.ConvCallbackOutput(chunk); out != nil && out.Message != nil {
+			kwargs["token"] = out.Message.Content
+			if out.Message.ReasoningContent != "" {
+				kwargs["reasoning_content"] = out.Message.ReasoningContent
+			}
+			if len(out.Message.ToolCalls) > 0 {
+				kwargs["tool_calls"] = out.Message.ToolCalls
+			}
+		} else if s, err := cc.cfg.serializer().Serialize(cc.info, chunk); err == nil {
+			kwargs["chunk"] = truncatePayload(s, cc.cfg.MaxOutputBytes)
+		}
+	}
+	cc.events = append(cc.events, RunEvent{Name: RunEventNewToken, Time: t, Kwargs: kwargs})
+}
+
+// StreamOverflowPolicy decides what happens to a stream when Config.MaxConcurrentStreams streams are already consumed.
+type StreamOverflowPolicy string
+
+const (
+	// StreamOverflowBlock makes the callback wait for a free slot, slowing down the traced graph. It's the default.
+	StreamOverflowBlock StreamOverflowPolicy = "block"
+	// StreamOverflowDrop closes the stream unread, the run is still traced with StreamDroppedPlaceholder as its
+	// streamed inputs or outputs.
+	StreamOverflowDrop StreamOverflowPolicy = "drop"
+)
+
+// StreamDroppedPlaceholder is reported instead of the streamed inputs or outputs of runs whose stream was dropped,
+// see StreamOverflowDrop.
+const StreamDroppedPlaceholder = "[stream dropped]"
+
+// consumeStream calls consume in the background, within the limit of Config.MaxConcurrentStreams. When the stream
+// overflows the limit it's called synchronously with dropped set instead, consume must then close the stream unread.
+// Streams waiting for a slot are dropped too once ctx is done.
+func (c *CallbackHandler) consumeStream(ctx context.Context, info *callbacks.RunInfo, consume func(dropped bool)) {
+	if c.streams == nil {
+		go consume(false)
+		return
+	}
+	select {
+	case c.streams <- struct{}{}:
+	default:
+		if c.cfg.StreamOverflow == StreamOverflowDrop || c.streams.acquire(ctx) != nil {
+			c.cfg.logger().Warn(ctx, "too many concurrent streams, stream dropped", "limit", cap(c.streams), "run_info", info)
+			consume(true)
+			return
+		}
+	}
+	go func() {
+		defer c.streams.release()
+		consume(false)
+	}()
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartialUpdaterDue(t *testing.T) {
+	p := (&CallbackHandler{cfg: &Config{}}).newPartialUpdater()
+	assert.False(t, p.due())
+
+	p = (&CallbackHandler{cfg: &Config{StreamUpdateChunks: 2}}).newPartialUpdater()
+	assert.False(t, p.due())
+	assert.True(t, p.due())
+	assert.False(t, p.due())
+	assert.True(t, p.due())
+
+	p = (&CallbackHandler{cfg: &Config{StreamUpdateInterval: 10 * time.Millisecond}}).newPartialUpdater()
+	assert.False(t, p.due())
+	time.Sleep(15 * time.Millisecond)
+	assert.True(t, p.due())
+
+	p = (&CallbackHandler{cfg: &Config{StreamUpdateChunks: 1, HideOutputs: true}}).newPartialUpdater()
+	assert.False(t, p.due())
+}
+
+func TestPartialStreamUpdates(t *testing.T) {
+	mCli := new(mockLangsmith)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{StreamUpdateChunks: 2}}
+	var mu sync.Mutex
+	var patches []*RunPatch
+	done := make(chan struct{})
+	mCli.On("UpdateRun", mock.Anything, "run-1", mock.Anything).Run(func(args mock.Arguments) {
+		patch := args.Get(2).(*RunPatch)
+		mu.Lock()
+		patches = append(patches, patch)
+		mu.Unlock()
+		if patch.EndTime != nil {
+			close(done)
+		}
+	}).Return(nil)
+
+	ctx := context.WithValue(context.Background(), langsmithStateKey{}, &LangsmithState{ParentRunID: "run-1"})
+	sr, sw := schema.Pipe[callbacks.CallbackOutput](5)
+	for _, s := range []string{"a", "b", "c", "d", "e"} {
+		sw.Send(&model.CallbackOutput{Message: schema.AssistantMessage(s, nil)}, nil)
+	}
+	sw.Close()
+	h.OnEndWithStreamOutput(ctx, &callbacks.RunInfo{Component: components.ComponentOfChatModel}, sr)
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, patches, 3)
+	assert.Nil(t, patches[0].EndTime)
+	assert.Equal(t, "ab", patches[0].Outputs["stream_outputs"].(*schema.Message).Content)
+	assert.Equal(t, "abcd", patches[1].Outputs["stream_outputs"].(*schema.Message).Content)
+	assert.Equal(t, "abcde", patches[2].Outputs["stream_outputs"].(*schema.Message).Content)
+}
+
+// TestPartialUpdaterConcat 测试增量拼接的结果与一次性拼接全部 chunk 一致
+func TestPartialUpdaterConcat(t *testing.T) {
+	call := func(args string) []schema.ToolCall {
+		return []schema.ToolCall{{Index: new(int), ID: "call-1", Function: schema.FunctionCall{Name: "search", Arguments: args}}}
+	}
+	var outputs []callbacks.CallbackOutput
+	for _, msg := range []*schema.Message{
+		schema.AssistantMessage("he", nil),
+		schema.AssistantMessage("llo", call(`{"q"`)),
+		schema.AssistantMessage("", call(`:"go"}`)),
+		schema.AssistantMessage("!", nil),
+	} {
+		outputs = append(outputs, &model.CallbackOutput{Message: msg})
+	}
+	p := &partialUpdater{}
+	for i := 1; i <= len(outputs); i++ {
+		got, err := p.concat(outputs[:i])
+		require.NoError(t, err)
+		_, want, _, err := extractModelOutput(convModelCallbackOutput(outputs[:i]))
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "after %d chunks", i)
+	}
+	assert.Equal(t, `{"q":"go"}`, p.message.ToolCalls[0].Function.Arguments)
+}
+
+func TestCaptureStreamChunks(t *testing.T) {
+	mCli := new(mockLangsmith)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{CaptureStreamChunks: 3}}
+	patches := make(chan *RunPatch, 1)
+	mCli.On("UpdateRun", mock.Anything, "run-1", mock.Anything).Run(func(args mock.Arguments) {
+		patches <- args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	ctx := context.WithValue(context.Background(), langsmithStateKey{}, &LangsmithState{ParentRunID: "run-1"})
+	sr, sw := schema.Pipe[callbacks.CallbackOutput](5)
+	toolCall := schema.ToolCall{Index: new(int), ID: "call-1", Function: schema.FunctionCall{Name: "search", Arguments: `{"q"`}}
+	sw.Send(&model.CallbackOutput{Message: schema.AssistantMessage("he", nil)}, nil)
+	sw.Send(&model.CallbackOutput{Message: schema.AssistantMessage("llo", nil)}, nil)
+	sw.Send(&model.CallbackOutput{Message: schema.AssistantMessage("", []schema.ToolCall{toolCall})}, nil)
+	sw.Send(&model.CallbackOutput{Message: schema.AssistantMessage("!", nil)}, nil)
+	sw.Close()
+	h.OnEndWithStreamOutput(ctx, &callbacks.RunInfo{Component: components.ComponentOfChatModel}, sr)
+	patch := <-patches
+
+	require.Len(t, patch.Events, 3)
+	for i, e := range patch.Events {
+		assert.Equal(t, RunEventNewToken, e.Name)
+		assert.Equal(t, i, e.Kwargs["index"])
+		if i > 0 {
+			assert.False(t, e.Time.Before(patch.Events[i-1].Time))
+		}
+	}
+	assert.Equal(t, "he", patch.Events[0].Kwargs["token"])
+	assert.Equal(t, "llo", patch.Events[1].Kwargs["token"])
+	assert.Equal(t, []schema.ToolCall{toolCall}, patch.Events[2].Kwargs["tool_calls"])
+	assert.Equal(t, 4, patch.Extra["metadata"].(map[string]interface{})["stream_chunks"])
+
+	// chunk contents are hidden with the outputs
+	h.cfg.HideOutputs = true
+	sr, sw = schema.Pipe[callbacks.CallbackOutput](1)
+	sw.Send(&model.CallbackOutput{Message: schema.AssistantMessage("secret", nil)}, nil)
+	sw.Close()
+	h.OnEndWithStreamOutput(ctx, &callbacks.RunInfo{Component: components.ComponentOfChatModel}, sr)
+	patch = <-patches
+	require.Len(t, patch.Events, 1)
+	assert.Equal(t, map[string]interface{}{"index": 0}, patch.Events[0].Kwargs)
+}
+
+// TestMaxConcurrentStreams 测试并发流超出上限时按溢出策略处理
+func TestMaxConcurrentStreams(t *testing.T) {
+	mCli := new(mockLangsmith)
+	patches := make(chan *RunPatch, 2)
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		patches <- args.Get(2).(*RunPatch)
+	}).Return(nil)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{StreamOverflow: StreamOverflowDrop}, streams: newSemaphore(1)}
+	info := &callbacks.RunInfo{Component: components.ComponentOfChatModel}
+
+	// the first stream holds the only slot until it's closed
+	first, firstW := schema.Pipe[callbacks.CallbackOutput](1)
+	h.OnEndWithStreamOutput(context.WithValue(context.Background(), langsmithStateKey{}, &LangsmithState{ParentRunID: "run-1"}), info, first)
+
+	// the second one is dropped, its run still ends
+	second, secondW := schema.Pipe[callbacks.CallbackOutput](1)
+	secondW.Send(&model.CallbackOutput{Message: schema.AssistantMessage("unread", nil)}, nil)
+	h.OnEndWithStreamOutput(context.WithValue(context.Background(), langsmithStateKey{}, &LangsmithState{ParentRunID: "run-2"}), info, second)
+	patch := <-patches
+	assert.Equal(t, StreamDroppedPlaceholder, patch.Outputs["stream_outputs"])
+	assert.NotNil(t, patch.EndTime)
+	secondW.Close()
+
+	firstW.Send(&model.CallbackOutput{Message: schema.AssistantMessage("read", nil)}, nil)
+	firstW.Close()
+	patch = <-patches
+	assert.Equal(t, "read", patch.Outputs["stream_outputs"].(*schema.Message).Content)
+
+	// with the block policy the callback waits for the slot, until its context is done
+	h.cfg.StreamOverflow = StreamOverflowBlock
+	h.streams <- struct{}{}
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), langsmithStateKey{}, &LangsmithState{ParentRunID: "run-3"}), 20*time.Millisecond)
+	defer cancel()
+	blocked, blockedW := schema.Pipe[callbacks.CallbackOutput](1)
+	blockedW.Close()
+	start := time.Now()
+	h.OnEndWithStreamOutput(ctx, info, blocked)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, StreamDroppedPlaceholder, (<-patches).Outputs["stream_outputs"])
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"io"
+	"runtime/debug"
+	"sync"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// runSummary aggregates the components nested in a root run when Config.RootOnly is set.
+type runSummary struct {
+	mu      sync.Mutex
+	usage   model.TokenUsage
+	cost    float64
+	hasCost bool
+	runs    int
+	errors  int
+}
+
+// newRunSummary returns the summary of a new root run, nil unless Config.RootOnly is set.
+func (c *Config) newRunSummary() *runSummary {
+	if !c.RootOnly {
+		return nil
+	}
+	return &runSummary{}
+}
+
+func (s *runSummary) addRun() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.runs++
+	s.mu.Unlock()
+}
+
+func (s *runSummary) addError() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.errors++
+	s.mu.Unlock()
+}
+
+// addModelOutputs adds the token usage and cost of a model run from its (possibly streamed) outputs.
+func (s *runSummary) addModelOutputs(cfg *Config, info *callbacks.RunInfo, outputs []callbacks.CallbackOutput) {
+	if s == nil || info.Component != components.ComponentOfChatModel {
+		return
+	}
+	outs := convModelCallbackOutput(outputs)
+	usage, _, _, _ := extractModelOutput(outs)
+	if usage == nil {
+		return
+	}
+	var modelName string
+	for _, out := range outs {
+		if out != nil && out.Config != nil && out.Config.Model != "" {
+			modelName = out.Config.Model
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage.PromptTokens += usage.PromptTokens
+	s.usage.CompletionTokens += usage.CompletionTokens
+	s.usage.TotalTokens += usage.TotalTokens
+	if cost, ok := cfg.usageCost(modelName, usage); ok {
+		s.cost += cost
+		s.hasCost = true
+	}
+}
+
+// report adds the aggregated usage, cost and counts to the metadata of the root run, if it had nested components.
+func (s *runSummary) report(extra map[string]interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.runs == 0 {
+		return
+	}
+	md, _ := extra["metadata"].(map[string]interface{})
+	cp := make(map[string]interface{}, len(md)+4)
+	for k, v := range md {
+		cp[k] = v
+	}
+	cp["child_runs"] = s.runs
+	cp["child_errors"] = s.errors
+	if s.usage.TotalTokens > 0 {
+		cp["usage_metadata"] = map[string]int{
+			"input_tokens":  s.usage.PromptTokens,
+			"output_tokens": s.usage.CompletionTokens,
+			"total_tokens":  s.usage.TotalTokens,
+		}
+	}
+	if s.hasCost {
+		cp["total_cost"] = s.cost
+	}
+	extra["metadata"] = cp
+}
+
+// summarizeStream consumes the streamed output of a skipped model run, adding its usage to the root run summary.
+// a dropped stream is closed unread.
+func (c *CallbackHandler) summarizeStream(ctx context.Context, info *callbacks.RunInfo, s *runSummary,
+	output *schema.StreamReader[callbacks.CallbackOutput], dropped bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.cfg.logger().Error(ctx, "recovered in summarizeStream", "panic", r, "stack", string(debug.Stack()))
+		}
+		output.Close()
+	}()
+	var outputs []callbacks.CallbackOutput
+	for !dropped {
+		chunk, err := output.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.cfg.logger().Error(ctx, "error receiving stream output", "err", err)
+			return
+		}
+		outputs = append(outputs, chunk)
+	}
+	s.addModelOutputs(c.cfg, info, outputs)
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRootOnly 测试只上报根节点并汇总子节点的用量
+func TestRootOnly(t *testing.T) {
+	mCli := &mockLangsmith{}
+	h := &CallbackHandler{cli: mCli, cfg: &Config{
+		RunIDGen: func(ctx context.Context) string { return uuid.NewString() },
+		RootOnly: true,
+		Pricing:  map[string]ModelPrice{"my-model": {PromptPer1K: 1, CompletionPer1K: 2}},
+	}}
+	var runs []*Run
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		runs = append(runs, args.Get(1).(*Run))
+	}).Return(nil)
+	var patches []*RunPatch
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		patches = append(patches, args.Get(2).(*RunPatch))
+	}).Return(nil)
+
+	graph := &callbacks.RunInfo{Name: "graph", Component: compose.ComponentOfGraph}
+	chatModel := &callbacks.RunInfo{Name: "model", Component: components.ComponentOfChatModel}
+	tool := &callbacks.RunInfo{Name: "tool", Component: components.ComponentOfTool}
+
+	graphCtx := h.OnStart(context.Background(), graph, "in")
+	modelCtx := h.OnStart(graphCtx, chatModel, &model.CallbackInput{})
+	h.OnEnd(modelCtx, chatModel, &model.CallbackOutput{
+		Config:     &model.Config{Model: "my-model"},
+		TokenUsage: &model.TokenUsage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500},
+	})
+	toolCtx := h.OnStart(graphCtx, tool, "in")
+	h.OnError(toolCtx, tool, errors.New("boom"))
+
+	// streamed model usage
+	streamCtx := h.OnStart(graphCtx, chatModel, &model.CallbackInput{})
+	sr, sw := schema.Pipe[callbacks.CallbackOutput](2)
+	sw.Send(&model.CallbackOutput{TokenUsage: &model.TokenUsage{PromptTokens: 1, CompletionTokens: 2, TotalTokens: 3}}, nil)
+	sw.Close()
+	_, state := GetState(streamCtx)
+	require.True(t, state.skipped)
+	h.summarizeStream(streamCtx, chatModel, state.summary, sr, false)
+
+	h.OnEnd(graphCtx, graph, "out")
+
+	require.Len(t, runs, 1)
+	assert.Equal(t, "graph", runs[0].Name)
+	require.Len(t, patches, 1)
+	md := patches[0].Extra["metadata"].(map[string]interface{})
+	assert.Equal(t, 3, md["child_runs"])
+	assert.Equal(t, 1, md["child_errors"])
+	assert.Equal(t, map[string]int{"input_tokens": 1001, "output_tokens": 502, "total_tokens": 1503}, md["usage_metadata"])
+	assert.InDelta(t, 2.0, md["total_cost"], 1e-9)
+}
+
+func TestRunSummaryDisabled(t *testing.T) {
+	var s *runSummary
+	assert.Nil(t, (&Config{}).newRunSummary())
+	s.addRun()
+	s.addError()
+	s.addModelOutputs(&Config{}, &callbacks.RunInfo{Component: components.ComponentOfChatModel}, nil)
+	extra := map[string]interface{}{}
+	s.report(extra)
+	assert.Empty(t, extra)
+
+	// a root without nested components keeps its own metadata
+	s = (&Config{RootOnly: true}).newRunSummary()
+	s.report(extra)
+	assert.Empty(t, extra)
+}
@@ -33,6 +33,8 @@ type traceOptions struct {
 	ParentID           string
 	ParentDottedOrder  string
 	Tags               []string
+	APIKey             string
+	WorkspaceID        string
 }
 
 type TraceOption func(*traceOptions)
@@ -92,3 +94,117 @@ func SetMetadata(metadata *sync.Map) TraceOption {
 		}
 	}
 }
+
+// Metadata keys langsmith groups the runs of a conversation thread by.
+const (
+	MetadataThreadID       = "thread_id"
+	MetadataConversationID = "conversation_id"
+)
+
+// WithThreadID groups the trace into a conversation thread of langsmith, multi-turn chats are shown as one thread.
+func WithThreadID(threadID string) TraceOption {
+	return func(o *traceOptions) {
+		setTraceMetadata(o, MetadataThreadID, threadID)
+	}
+}
+
+// WithConversationID is WithThreadID under the conversation_id key.
+func WithConversationID(conversationID string) TraceOption {
+	return func(o *traceOptions) {
+		setTraceMetadata(o, MetadataConversationID, conversationID)
+	}
+}
+
+// Metadata keys of the identity of the caller, to filter the runs of a user or a request in langsmith.
+const (
+	MetadataUserID    = "user_id"
+	MetadataRequestID = "request_id"
+	MetadataDeviceID  = "device_id"
+)
+
+// WithUserID records the end user the trace runs for.
+func WithUserID(userID string) TraceOption {
+	return func(o *traceOptions) {
+		setTraceMetadata(o, MetadataUserID, userID)
+	}
+}
+
+// WithRequestID records the id of the request the trace serves, e.g. to join it with access logs.
+func WithRequestID(requestID string) TraceOption {
+	return func(o *traceOptions) {
+		setTraceMetadata(o, MetadataRequestID, requestID)
+	}
+}
+
+// WithDeviceID records the device of the end user.
+func WithDeviceID(deviceID string) TraceOption {
+	return func(o *traceOptions) {
+		setTraceMetadata(o, MetadataDeviceID, deviceID)
+	}
+}
+
+// setTraceMetadata sets key in the "metadata" map of the trace metadata, the map is copied since runs may hold it.
+func setTraceMetadata(o *traceOptions, key string, value interface{}) {
+	if o.Metadata == nil {
+		o.Metadata = &sync.Map{}
+	}
+	md, _ := o.Metadata.Load("metadata")
+	inner, _ := md.(map[string]interface{})
+	cp := make(map[string]interface{}, len(inner)+1)
+	for k, v := range inner {
+		cp[k] = v
+	}
+	cp[key] = value
+	o.Metadata.Store("metadata", cp)
+}
+
+// WithAPIKey exports the runs of the trace with another langsmith API key, e.g. the key of a tenant in a multi-tenant
+// service. The other API requests made with the traced context, such as feedback, use it as well.
+// Runs spooled in Config.SpoolDir or stored by SQLiteExporter keep the key and the workspace, they are replayed with
+// them.
+func WithAPIKey(apiKey string) TraceOption {
+	return func(o *traceOptions) {
+		o.APIKey = apiKey
+	}
+}
+
+// WithWorkspaceID exports the runs of the trace to a workspace, for API keys having access to several of them.
+// Combine it with WithSessionName to route the trace to a project of the workspace.
+func WithWorkspaceID(workspaceID string) TraceOption {
+	return func(o *traceOptions) {
+		o.WorkspaceID = workspaceID
+	}
+}
+
+// requestCredentials returns the API key and workspace overridden by the trace options of ctx.
+func requestCredentials(ctx context.Context) (apiKey, workspaceID string) {
+	opts, _ := ctx.Value(langsmithTraceOptionKey{}).(*traceOptions)
+	if opts == nil {
+		return "", ""
+	}
+	return opts.APIKey, opts.WorkspaceID
+}
+
+// referenceExampleID returns the example a new run under state is linked to: only root runs are linked, unless
+// Config.ReferenceExampleOnAllRuns is set.
+func (c *Config) referenceExampleID(opts *traceOptions, state *LangsmithState) *string {
+	if opts == nil || opts.ReferenceExampleID == "" {
+		return nil
+	}
+	if state != nil && state.ParentRunID != "" && (c == nil || !c.ReferenceExampleOnAllRuns) {
+		return nil
+	}
+	id := opts.ReferenceExampleID
+	return &id
+}
+
+// sessionName returns the session of the trace, falling back to Config.SessionName.
+func (c *Config) sessionName(opts *traceOptions) string {
+	if opts != nil && opts.SessionName != "" {
+		return opts.SessionName
+	}
+	if c == nil {
+		return ""
+	}
+	return c.SessionName
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+)
+
+// traceScope holds the trace options of a context resolved once, at the run they are first seen by: usually the root
+// of the trace. Child runs started under the same options inherit the scope through their LangsmithState instead of
+// copying the options again, options set by SetTrace deeper in the trace start a new scope.
+type traceScope struct {
+	opts     *traceOptions          // options the scope is resolved from
+	session  string                 // project of the runs
+	tags     []string               // tags of the runs
+	metadata map[string]interface{} // extra of the runs set by the options, must not be modified
+}
+
+// traceScope returns the scope of the trace options of ctx, inherited from state when the options didn't change.
+func (c *Config) traceScope(ctx context.Context, state *LangsmithState) *traceScope {
+	opts, _ := ctx.Value(langsmithTraceOptionKey{}).(*traceOptions)
+	if state != nil && state.trace != nil && state.trace.opts == opts {
+		return state.trace
+	}
+	if opts == nil {
+		opts = &traceOptions{}
+	}
+	return &traceScope{
+		opts:     opts,
+		session:  c.sessionName(opts),
+		tags:     opts.Tags,
+		metadata: SafeDeepCopySyncMapMetadata(opts.Metadata),
+	}
+}
+
+// runExtra returns the extra a new run under state starts with. The metadata of the scope is only repeated on child
+// runs inheriting the scope unless Config.TraceMetadataOnRoot is set, metadata maps of the extra are copied on write.
+func (c *Config) runExtra(scope *traceScope, state *LangsmithState) map[string]interface{} {
+	if c.TraceMetadataOnRoot && state != nil && state.trace == scope {
+		return map[string]interface{}{"metadata": map[string]interface{}{}}
+	}
+	extra := make(map[string]interface{}, len(scope.metadata)+2)
+	for k, v := range scope.metadata {
+		extra[k] = v
+	}
+	return extra
+}
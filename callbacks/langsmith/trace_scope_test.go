@@ -0,0 +1,68 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTraceScope 测试 trace 选项只在根节点解析一次并由子节点继承
+func TestTraceScope(t *testing.T) {
+	for _, onRoot := range []bool{false, true} {
+		mCli := new(mockLangsmith)
+		runs := map[string]*Run{}
+		mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			run := args.Get(1).(*Run)
+			runs[run.Name] = run
+		}).Return(nil)
+		h, err := NewLangsmithHandler(&Config{Exporter: mCli, Blocking: true, TraceMetadataOnRoot: onRoot})
+		require.NoError(t, err)
+
+		md := &sync.Map{}
+		md.Store("payload", "large")
+		ctx := SetTrace(context.Background(), SetMetadata(md), WithSessionName("project"), AddTag("tag"))
+		rootCtx := h.OnStart(ctx, &callbacks.RunInfo{Name: "root", Component: "Graph"}, "input")
+		childCtx := h.OnStart(rootCtx, &callbacks.RunInfo{Name: "child", Component: "Lambda"}, "input")
+		rootState := rootCtx.Value(langsmithStateKey{}).(*LangsmithState)
+		childState := childCtx.Value(langsmithStateKey{}).(*LangsmithState)
+		assert.Same(t, rootState.trace, childState.trace)
+
+		// options set deeper in the trace start a new scope
+		nested := SetTrace(childCtx, WithSessionName("nested"))
+		h.OnStart(nested, &callbacks.RunInfo{Name: "nested", Component: "Lambda"}, "input")
+
+		assert.Equal(t, "large", runs["root"].Extra["payload"])
+		assert.Equal(t, "project", runs["child"].SessionName)
+		assert.Equal(t, []string{"tag"}, runs["child"].Tags)
+		assert.Equal(t, "nested", runs["nested"].SessionName)
+		if onRoot {
+			assert.NotContains(t, runs["child"].Extra, "payload")
+		} else {
+			assert.Equal(t, "large", runs["child"].Extra["payload"])
+		}
+		// the scope is never modified by the runs
+		runs["root"].Extra["other"] = 1
+		assert.NotContains(t, rootState.trace.metadata, "other")
+	}
+}
@@ -18,6 +18,8 @@ package langsmith
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 
@@ -191,3 +193,66 @@ func TestTraceOptionChaining(t *testing.T) {
 	assert.NotEqual(t, "trace1", opts.TraceID)          // 这个应该被重置
 	assert.ElementsMatch(t, []string{"tag2"}, opts.Tags)
 }
+
+func TestPerRequestCredentials(t *testing.T) {
+	var keys, workspaces []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("x-api-key"))
+		workspaces = append(workspaces, r.Header.Get(WorkspaceIDHeader))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	cli := NewLangsmith("default-key", srv.URL).(*langsmithClient)
+
+	assert.NoError(t, cli.UpdateRun(context.Background(), "run-1", &RunPatch{}))
+	ctx := SetTrace(context.Background(), WithAPIKey("tenant-key"), WithWorkspaceID("ws-1"))
+	assert.NoError(t, cli.UpdateRun(ctx, "run-1", &RunPatch{}))
+	// the override survives detached export contexts
+	assert.NoError(t, cli.UpdateRun(detachContext(SetTrace(context.Background(), WithWorkspaceID("ws-2"))), "run-1", &RunPatch{}))
+
+	assert.Equal(t, []string{"default-key", "tenant-key", "default-key"}, keys)
+	assert.Equal(t, []string{"", "ws-1", "ws-2"}, workspaces)
+
+	// the workspace of the client is the default of all requests
+	workspaces = nil
+	cli = NewLangsmith("default-key", srv.URL, WithClientWorkspaceID("ws-default")).(*langsmithClient)
+	assert.NoError(t, cli.UpdateRun(context.Background(), "run-1", &RunPatch{}))
+	_, err := cli.ListRuns(context.Background(), &ListRunsOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, cli.UpdateRun(ctx, "run-1", &RunPatch{}))
+	assert.Equal(t, []string{"ws-default", "ws-default", "ws-1"}, workspaces)
+}
+
+func TestWithThreadID(t *testing.T) {
+	md := &sync.Map{}
+	md.Store("metadata", map[string]interface{}{"user": "u1"})
+	ctx := SetTrace(context.Background(), SetMetadata(md), WithThreadID("thread-1"), WithConversationID("conv-1"))
+	opts := ctx.Value(langsmithTraceOptionKey{}).(*traceOptions)
+	inner, _ := opts.Metadata.Load("metadata")
+	assert.Equal(t, map[string]interface{}{"user": "u1", "thread_id": "thread-1", "conversation_id": "conv-1"}, inner)
+
+	opts = SetTrace(context.Background(), WithThreadID("thread-2")).Value(langsmithTraceOptionKey{}).(*traceOptions)
+	inner, _ = opts.Metadata.Load("metadata")
+	assert.Equal(t, map[string]interface{}{"thread_id": "thread-2"}, inner)
+}
+
+func TestIdentityOptions(t *testing.T) {
+	ctx := SetTrace(context.Background(), WithUserID("u1"), WithRequestID("req-1"), WithDeviceID("dev-1"))
+	opts := ctx.Value(langsmithTraceOptionKey{}).(*traceOptions)
+	extra := SafeDeepCopySyncMapMetadata(opts.Metadata)
+	assert.Equal(t, map[string]interface{}{"user_id": "u1", "request_id": "req-1", "device_id": "dev-1"}, extra["metadata"])
+}
+
+func TestReferenceExampleOnlyAtRoot(t *testing.T) {
+	opts := &traceOptions{ReferenceExampleID: "example-1"}
+	root := &LangsmithState{}
+	child := &LangsmithState{ParentRunID: "run-1"}
+
+	cfg := &Config{}
+	assert.Equal(t, "example-1", *cfg.referenceExampleID(opts, root))
+	assert.Nil(t, cfg.referenceExampleID(opts, child))
+	assert.Nil(t, cfg.referenceExampleID(&traceOptions{}, root))
+
+	cfg.ReferenceExampleOnAllRuns = true
+	assert.Equal(t, "example-1", *cfg.referenceExampleID(opts, child))
+}
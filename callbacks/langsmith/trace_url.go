@@ -0,0 +1,69 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"net/url"
+)
+
+// DefaultProjectName is the langsmith project runs go to when no session name is configured.
+const DefaultProjectName = "default"
+
+// RunURLClient resolves the web urls of runs, it's implemented by the client returned by NewLangsmith.
+type RunURLClient interface {
+	GetRunURL(ctx context.Context, projectName, runID, traceID string) (string, error)
+}
+
+// GetRunURL returns the url of a run in the langsmith web app, the project is looked up once to resolve the
+// ids of the project and its workspace.
+func (c *langsmithClient) GetRunURL(ctx context.Context, projectName, runID, traceID string) (string, error) {
+	if projectName == "" {
+		projectName = DefaultProjectName
+	}
+	var project *Project
+	key := newProjectKey(ctx, projectName)
+	if cached, ok := c.projects.Load(key); ok {
+		project = cached.(*Project)
+	} else {
+		var err error
+		if project, err = c.ReadProject(ctx, projectName); err != nil {
+			return "", err
+		}
+		c.projects.Store(key, project)
+	}
+	u := c.hostURL() + "/o/" + url.PathEscape(project.TenantID) + "/projects/p/" + url.PathEscape(project.ID) +
+		"/r/" + url.PathEscape(runID)
+	if traceID != "" {
+		u += "?" + url.Values{"trace_id": {traceID}}.Encode()
+	}
+	return u, nil
+}
+
+// TraceURL returns the langsmith web url of the trace ctx is traced in, e.g. to attach a clickable link to logs and
+// error reports. It works inside runs created by CallbackHandler and FlowTrace, other contexts return ErrNoRunInContext.
+func TraceURL(ctx context.Context) (string, error) {
+	_, state := GetState(ctx)
+	if state == nil || state.TraceID == "" || state.cli == nil {
+		return "", ErrNoRunInContext
+	}
+	cli, err := apiClient[RunURLClient](state.cli, "run url")
+	if err != nil {
+		return "", err
+	}
+	return cli.GetRunURL(ctx, state.session, state.TraceID, state.TraceID)
+}
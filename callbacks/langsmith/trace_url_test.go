@@ -0,0 +1,81 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRunURL(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "/api/sessions", r.URL.Path)
+		assert.Equal(t, "default", r.URL.Query().Get("name"))
+		if r.Header.Get(WorkspaceIDHeader) == "ws-2" {
+			_, _ = w.Write([]byte(`[{"id":"p-2","name":"default","tenant_id":"t-2"}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id":"p-1","name":"default","tenant_id":"t-1"}]`))
+	}))
+	defer srv.Close()
+
+	cli := NewLangsmith("key", srv.URL+"/api").(*langsmithClient)
+	u, err := cli.GetRunURL(context.Background(), "", "run-1", "trace-1")
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL+"/o/t-1/projects/p/p-1/r/run-1?trace_id=trace-1", u)
+
+	u, err = cli.GetRunURL(context.Background(), "default", "run-2", "")
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL+"/o/t-1/projects/p/p-1/r/run-2", u)
+	assert.Equal(t, 1, calls)
+
+	// the project of the same name in another workspace isn't mixed up with the cached one
+	u, err = cli.GetRunURL(SetTrace(context.Background(), WithWorkspaceID("ws-2")), "default", "run-3", "")
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL+"/o/t-2/projects/p/p-2/r/run-3", u)
+	assert.Equal(t, 2, calls)
+}
+
+func TestTraceURL(t *testing.T) {
+	_, err := TraceURL(context.Background())
+	assert.ErrorIs(t, err, ErrNoRunInContext)
+
+	mCli := &mockLangsmith{}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{
+		SessionName: "my-project",
+		RunIDGen:    func(ctx context.Context) string { return uuid.NewString() },
+	}}
+	ctx := h.OnStart(context.Background(), &callbacks.RunInfo{Name: "graph"}, "in")
+	_, state := GetState(ctx)
+	mCli.On("GetRunURL", mock.Anything, "my-project", state.TraceID, state.TraceID).Return("https://smith/r", nil).Once()
+
+	u, err := TraceURL(ForkSpan(ctx))
+	require.NoError(t, err)
+	assert.Equal(t, "https://smith/r", u)
+	mCli.AssertExpectations(t)
+}
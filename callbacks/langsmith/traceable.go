@@ -0,0 +1,56 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"fmt"
+)
+
+// Traceable wraps fn so that every call is traced as a child run of the run of the call context, with the input and
+// output or error of the call, like the @traceable decorator of the python sdk. It traces business logic outside
+// of eino graphs into the same trace tree. By default the run is a RunTypeChain named name.
+func Traceable[I, O any](ft *FlowTrace, name string, fn func(context.Context, I) (O, error), opts ...SpanOption) func(context.Context, I) (O, error) {
+	return func(ctx context.Context, in I) (O, error) {
+		return TraceFunc(ctx, ft, name, fn, in, opts...)
+	}
+}
+
+// TraceFunc calls fn with in, traced as a child run of the run of ctx, see Traceable.
+// a failure of langsmith never fails the call, fn is called untraced instead.
+func TraceFunc[I, O any](ctx context.Context, ft *FlowTrace, name string, fn func(context.Context, I) (O, error), in I, opts ...SpanOption) (out O, err error) {
+	_, state := GetState(ctx)
+	spanOpts := append([]SpanOption{WithSpanInputs(map[string]interface{}{"input": in})}, opts...)
+	spanCtx, runID, spanErr := ft.StartSpan(ctx, name, state, spanOpts...)
+	if spanErr != nil {
+		return fn(ctx, in)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = ft.finishSpan(spanCtx, runID, nil, fmt.Errorf("panic: %v", r))
+			panic(r)
+		}
+	}()
+	out, err = fn(spanCtx, in)
+	if err != nil {
+		_ = ft.finishSpan(spanCtx, runID, nil, err)
+		return out, err
+	}
+	_ = ft.finishSpan(spanCtx, runID, map[string]interface{}{"output": out}, nil)
+	return out, nil
+}
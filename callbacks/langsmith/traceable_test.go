@@ -0,0 +1,79 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceable(t *testing.T) {
+	mCli := new(mockLangsmith)
+	n := 0
+	ft := &FlowTrace{cli: mCli, cfg: &Config{RunIDGen: func(ctx context.Context) string {
+		n++
+		return "run-" + strconv.Itoa(n)
+	}}}
+	created := map[string]*Run{}
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		run := args.Get(1).(*Run)
+		created[run.ID] = run
+	}).Return(nil)
+	patches := map[string]*RunPatch{}
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		patches[args.String(1)] = args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	double := Traceable(ft, "double", func(ctx context.Context, in int) (int, error) {
+		if in < 0 {
+			return 0, errors.New("negative")
+		}
+		return in * 2, nil
+	}, WithSpanRunType(RunTypeTool))
+
+	parentCtx, parentID, err := ft.StartSpan(context.Background(), "parent", nil)
+	require.NoError(t, err)
+
+	out, err := double(parentCtx, 21)
+	require.NoError(t, err)
+	assert.Equal(t, 42, out)
+	run := created["run-2"]
+	require.NotNil(t, run)
+	assert.Equal(t, "double", run.Name)
+	assert.Equal(t, RunTypeTool, run.RunType)
+	assert.Equal(t, parentID, *run.ParentRunID)
+	assert.Equal(t, map[string]interface{}{"input": 21}, run.Inputs)
+	assert.Equal(t, map[string]interface{}{"output": 42}, patches["run-2"].Outputs)
+	assert.NotNil(t, patches["run-2"].EndTime)
+
+	_, err = double(parentCtx, -1)
+	assert.EqualError(t, err, "negative")
+	assert.Equal(t, "negative", *patches["run-3"].Error)
+
+	assert.Panics(t, func() {
+		_, _ = TraceFunc(parentCtx, ft, "boom", func(ctx context.Context, in string) (string, error) {
+			panic("boom")
+		}, "x")
+	})
+	assert.Equal(t, "panic: boom", *patches["run-4"].Error)
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/model"
+)
+
+// Metadata keys of the token usage and cost of the model runs nested in a run, aggregated by the handler. They're
+// kept apart from usage_metadata and total_cost, which langsmith sums over the model runs of a trace itself.
+const (
+	MetadataRollupUsage = "rollup_usage_metadata"
+	MetadataRollupCost  = "rollup_total_cost"
+)
+
+// usageRollup aggregates the token usage and cost of the model runs nested in a run, each model run adds its usage
+// to the rollups of all its ancestors.
+type usageRollup struct {
+	parent *usageRollup
+	hidden bool // only aggregated for Config.TraceBudget, not reported
+
+	mu      sync.Mutex
+	usage   model.TokenUsage
+	cost    float64
+	hasCost bool
+	models  int
+
+	budget *traceBudgetState // set on the root rollup of the trace
+}
+
+// newUsageRollup returns the rollup of a new run nested in the run of parent. It's nil if Config.DisableUsageRollup is
+// set or Config.RootOnly aggregates the usage already, unless Config.TraceBudget needs the usage of the trace.
+func (c *Config) newUsageRollup(parent *usageRollup) *usageRollup {
+	hidden := c.DisableUsageRollup || c.RootOnly
+	if hidden && c.TraceBudget == nil {
+		return nil
+	}
+	return &usageRollup{parent: parent, hidden: hidden}
+}
+
+// add adds the usage of a model run nested in r to r and its ancestors, the budget of the trace is checked once the
+// root rollup is updated.
+func (r *usageRollup) add(ctx context.Context, cfg *Config, usage *model.TokenUsage, cost float64, priced bool) {
+	for ; r != nil; r = r.parent {
+		r.mu.Lock()
+		r.usage.PromptTokens += usage.PromptTokens
+		r.usage.CompletionTokens += usage.CompletionTokens
+		r.usage.TotalTokens += usage.TotalTokens
+		if priced {
+			r.cost += cost
+			r.hasCost = true
+		}
+		r.models++
+		var exceeded *BudgetExceeded
+		if r.parent == nil {
+			exceeded = r.budget.check(cfg.TraceBudget, r.usage.TotalTokens, r.cost)
+		}
+		r.mu.Unlock()
+		if exceeded != nil {
+			r.budget.exceed(ctx, cfg.TraceBudget, exceeded)
+		}
+	}
+}
+
+// addModel adds the usage of the model run owning r to its ancestors.
+func (r *usageRollup) addModel(ctx context.Context, cfg *Config, usage *model.TokenUsage, cost float64, priced bool) {
+	if r == nil {
+		return
+	}
+	r.parent.add(ctx, cfg, usage, cost, priced)
+}
+
+// addSkippedModel adds the usage of a model run filtered out of the trace, r is the rollup of its traced parent.
+func (r *usageRollup) addSkippedModel(ctx context.Context, cfg *Config, info *callbacks.RunInfo, output callbacks.CallbackOutput) {
+	if r == nil {
+		return
+	}
+	usage := modelUsage(info, output)
+	if usage == nil {
+		return
+	}
+	var modelName string
+	if out := model.ConvCallbackOutput(output); out.Config != nil {
+		modelName = out.Config.Model
+	}
+	cost, priced := cfg.usageCost(modelName, usage)
+	r.add(ctx, cfg, usage, cost, priced)
+}
+
+// nested reports whether model runs nested in the run added their usage to r.
+func (r *usageRollup) nested() bool {
+	if r == nil || r.hidden {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.models > 0
+}
+
+// report sets the aggregated usage and cost into extra.metadata, if model runs are nested in the run.
+func (r *usageRollup) report(extra map[string]interface{}) {
+	if r == nil || r.hidden {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.models == 0 {
+		return
+	}
+	md, _ := extra["metadata"].(map[string]interface{})
+	cp := make(map[string]interface{}, len(md)+2)
+	for k, v := range md {
+		cp[k] = v
+	}
+	cp[MetadataRollupUsage] = map[string]int{
+		"input_tokens":  r.usage.PromptTokens,
+		"output_tokens": r.usage.CompletionTokens,
+		"total_tokens":  r.usage.TotalTokens,
+	}
+	if r.hasCost {
+		cp[MetadataRollupCost] = r.cost
+	}
+	extra["metadata"] = cp
+}
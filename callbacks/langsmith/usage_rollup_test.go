@@ -0,0 +1,72 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUsageRollup 测试模型调用的 token 用量与费用汇总到祖先运行
+func TestUsageRollup(t *testing.T) {
+	mCli := new(mockLangsmith)
+	h, err := NewLangsmithHandler(&Config{Exporter: mCli, Blocking: true, Filter: &RunFilter{DenyNames: []string{"hidden"}}})
+	require.NoError(t, err)
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Return(nil)
+	patches := map[string]*RunPatch{}
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		patches[args.String(1)] = args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	modelInfo := &callbacks.RunInfo{Name: "model", Component: components.ComponentOfChatModel}
+	callModel := func(ctx context.Context, info *callbacks.RunInfo, tokens int) {
+		ctx = h.OnStart(ctx, info, &model.CallbackInput{Config: &model.Config{Model: "gpt-4o"}})
+		h.OnEnd(ctx, info, &model.CallbackOutput{Config: &model.Config{Model: "gpt-4o"},
+			TokenUsage: &model.TokenUsage{PromptTokens: tokens, CompletionTokens: tokens, TotalTokens: 2 * tokens}})
+	}
+	rootCtx := h.OnStart(context.Background(), &callbacks.RunInfo{Name: "root", Component: "Graph"}, "input")
+	subCtx := h.OnStart(rootCtx, &callbacks.RunInfo{Name: "sub", Component: "Chain"}, "input")
+	callModel(subCtx, modelInfo, 1000)
+	// filtered out model runs count too
+	callModel(subCtx, &callbacks.RunInfo{Name: "hidden", Component: components.ComponentOfChatModel}, 1000)
+	h.OnEnd(subCtx, &callbacks.RunInfo{Name: "sub", Component: "Chain"}, "output")
+	callModel(rootCtx, modelInfo, 500)
+	h.OnEnd(rootCtx, &callbacks.RunInfo{Name: "root", Component: "Graph"}, "output")
+
+	rollup := func(ctx context.Context) map[string]interface{} {
+		patch := patches[ctx.Value(langsmithStateKey{}).(*LangsmithState).ParentRunID]
+		require.NotNil(t, patch)
+		return patch.Extra["metadata"].(map[string]interface{})
+	}
+	sub := rollup(subCtx)
+	assert.Equal(t, map[string]int{"input_tokens": 2000, "output_tokens": 2000, "total_tokens": 4000}, sub[MetadataRollupUsage])
+	assert.InDelta(t, 0.025, sub[MetadataRollupCost], 1e-9)
+	root := rollup(rootCtx)
+	assert.Equal(t, map[string]int{"input_tokens": 2500, "output_tokens": 2500, "total_tokens": 5000}, root[MetadataRollupUsage])
+	assert.InDelta(t, 0.03125, root[MetadataRollupCost], 1e-9)
+	assert.NotContains(t, root, "usage_metadata")
+
+	// the rollup can be disabled
+	assert.Nil(t, (&Config{DisableUsageRollup: true}).newUsageRollup(nil))
+}
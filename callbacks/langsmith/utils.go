@@ -20,11 +20,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
+	"unicode/utf8"
+
+	"github.com/bytedance/sonic"
 
 	"github.com/cloudwego/eino/callbacks"
 	"github.com/cloudwego/eino/components"
 	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 )
 
@@ -35,12 +43,29 @@ func runInfoToName(info *callbacks.RunInfo) string {
 	return info.Type + string(info.Component)
 }
 
+// runName returns the name of the run of info, see Config.RunNameFunc.
+func (c *Config) runName(ctx context.Context, info *callbacks.RunInfo, path string) string {
+	if c.RunNameFunc != nil {
+		if name := c.RunNameFunc(ctx, info); name != "" {
+			return name
+		}
+	}
+	if c.NodePathRunNames && path != "" {
+		return path
+	}
+	return runInfoToName(info)
+}
+
 func runInfoToRunType(info *callbacks.RunInfo) RunType {
 	switch info.Component {
 	case components.ComponentOfChatModel:
 		return RunTypeLLM
 	case components.ComponentOfTool:
 		return RunTypeTool
+	case components.ComponentOfRetriever:
+		return RunTypeRetriever
+	case components.ComponentOfPrompt:
+		return RunTypePrompt
 	default:
 		return RunTypeChain
 	}
@@ -55,7 +80,7 @@ func convModelCallbackInput(in []callbacks.CallbackInput) []*model.CallbackInput
 }
 
 func extractModelInput(ins []*model.CallbackInput) (config *model.Config, messages []*schema.Message, extra map[string]interface{}, err error) {
-	var mas [][]*schema.Message
+	mas := make([][]*schema.Message, 0, len(ins))
 	for _, in := range ins {
 		if in == nil {
 			continue
@@ -89,7 +114,7 @@ func convModelCallbackOutput(out []callbacks.CallbackOutput) []*model.CallbackOu
 }
 
 func extractModelOutput(outs []*model.CallbackOutput) (usage *model.TokenUsage, message *schema.Message, extra map[string]interface{}, err error) {
-	var mas []*schema.Message
+	mas := make([]*schema.Message, 0, len(outs))
 	for _, out := range outs {
 		if out == nil {
 			continue
@@ -214,6 +239,8 @@ func SafeDeepCopyMetadata(original map[string]interface{}) map[string]interface{
 	return copyData
 }
 
+// SafeDeepCopySyncMapMetadata copies the run extra stored in original, nested maps such as "metadata" included, so the
+// copy can be modified while sibling runs started concurrently from the same context copy it too.
 func SafeDeepCopySyncMapMetadata(original *sync.Map) map[string]interface{} {
 	if original == nil {
 		return map[string]interface{}{"metadata": map[string]interface{}{}}
@@ -221,7 +248,7 @@ func SafeDeepCopySyncMapMetadata(original *sync.Map) map[string]interface{} {
 
 	copyData := make(map[string]interface{})
 	original.Range(func(k, v interface{}) bool {
-		copyData[k.(string)] = v
+		copyData[k.(string)] = copyMetadataValue(v)
 		return true
 	})
 
@@ -235,3 +262,285 @@ func SafeDeepCopySyncMapMetadata(original *sync.Map) map[string]interface{} {
 
 	return copyData
 }
+
+func copyMetadataValue(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	cp := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		cp[k] = copyMetadataValue(v)
+	}
+	return cp
+}
+
+// truncatePayload keeps the head and tail of s so that the result, marker included, fits in limit bytes,
+// the dropped middle part is replaced by a marker telling how many bytes were truncated.
+// Limits too small for the marker keep the head of s only.
+func truncatePayload(s string, limit int) string {
+	if limit <= 0 || len(s) <= limit {
+		return s
+	}
+	// no more than len(s) bytes are dropped, the marker is at most as long as with len(s)
+	budget := limit - len(truncationMarker(len(s)))
+	if budget <= 0 {
+		head := limit
+		for head > 0 && !utf8.RuneStart(s[head]) {
+			head--
+		}
+		return s[:head]
+	}
+	head := budget / 2
+	for head > 0 && !utf8.RuneStart(s[head]) {
+		head--
+	}
+	tail := len(s) - (budget - head)
+	for tail < len(s) && !utf8.RuneStart(s[tail]) {
+		tail++
+	}
+	return s[:head] + truncationMarker(tail-head) + s[tail:]
+}
+
+func truncationMarker(truncated int) string {
+	return fmt.Sprintf("...truncated %d bytes...", truncated)
+}
+
+// limitPayload returns v untouched if its serialized form fits in limit bytes,
+// otherwise the truncated serialized string is returned. The string is sent escaped within the run, its escaped form,
+// quotes aside, fits in limit bytes too.
+func limitPayload(v interface{}, limit int) interface{} {
+	if limit <= 0 {
+		return v
+	}
+	s, ok := v.(string)
+	if !ok {
+		data, err := sonic.MarshalString(v)
+		if err != nil || len(data) <= limit {
+			return v
+		}
+		s = data
+	}
+	return truncateEscaped(s, limit)
+}
+
+// truncateEscaped truncates s so that its JSON string encoding, quotes aside, fits in limit bytes. The budget of
+// truncatePayload shrinks by the escaping overflow until it does.
+func truncateEscaped(s string, limit int) string {
+	budget := limit
+	for {
+		truncated := truncatePayload(s, budget)
+		over := escapedLen(truncated) - limit
+		if over <= 0 {
+			return truncated
+		}
+		if budget -= over; budget <= 0 {
+			return ""
+		}
+	}
+}
+
+// escapedLen returns the length of s encoded as a JSON string, quotes aside.
+func escapedLen(s string) int {
+	data, err := sonic.MarshalString(s)
+	if err != nil {
+		return len(s)
+	}
+	return len(data) - 2
+}
+
+// limitRunPayload hides or limits run inputs or outputs, an oversized map is replaced by its truncated serialization
+// under key.
+func limitRunPayload(m map[string]interface{}, key string, hide bool, limit int) map[string]interface{} {
+	if hide {
+		return map[string]interface{}{key: HiddenPlaceholder}
+	}
+	if limited, ok := limitPayload(m, limit).(string); ok {
+		return map[string]interface{}{key: limited}
+	}
+	return m
+}
+
+// setRunMetadata sets key in the "metadata" map of a run's extra, the map is copied since it's shared with the parent run.
+func setRunMetadata(extra map[string]interface{}, key string, value interface{}) {
+	md, _ := extra["metadata"].(map[string]interface{})
+	cp := make(map[string]interface{}, len(md)+1)
+	for k, v := range md {
+		cp[k] = v
+	}
+	cp[key] = value
+	extra["metadata"] = cp
+}
+
+// setToolCallID records the id of the tool call a tool run executes, so it can be linked to the tool_calls of the model run.
+func setToolCallID(ctx context.Context, info *callbacks.RunInfo, extra map[string]interface{}) {
+	if info.Component != components.ComponentOfTool {
+		return
+	}
+	if id := compose.GetToolCallID(ctx); id != "" {
+		setRunMetadata(extra, "tool_call_id", id)
+	}
+}
+
+// modelToolCalls returns the tool calls of a chat model output, nil for other components.
+func modelToolCalls(info *callbacks.RunInfo, output callbacks.CallbackOutput) []schema.ToolCall {
+	if info.Component != components.ComponentOfChatModel {
+		return nil
+	}
+	out := model.ConvCallbackOutput(output)
+	if out == nil || out.Message == nil {
+		return nil
+	}
+	return out.Message.ToolCalls
+}
+
+// lsDocument is a document in the format of langsmith retriever runs.
+type lsDocument struct {
+	PageContent string                 `json:"page_content"`
+	Type        string                 `json:"type"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// retrieverDocuments converts the documents returned by a retriever, nil for other components.
+func retrieverDocuments(info *callbacks.RunInfo, output callbacks.CallbackOutput) []*lsDocument {
+	if info.Component != components.ComponentOfRetriever {
+		return nil
+	}
+	out := retriever.ConvCallbackOutput(output)
+	if out == nil {
+		return nil
+	}
+	return lsDocuments(out.Docs)
+}
+
+// lsDocuments converts documents into the langsmith format, nil documents are skipped.
+func lsDocuments(documents []*schema.Document) []*lsDocument {
+	docs := make([]*lsDocument, 0, len(documents))
+	for _, doc := range documents {
+		if doc == nil {
+			continue
+		}
+		metadata := make(map[string]interface{}, len(doc.MetaData)+2)
+		for k, v := range doc.MetaData {
+			metadata[k] = v
+		}
+		if doc.ID != "" {
+			metadata["id"] = doc.ID
+		}
+		if _, ok := doc.MetaData["_score"]; ok {
+			metadata["score"] = doc.Score()
+		}
+		docs = append(docs, &lsDocument{PageContent: doc.Content, Type: "Document", Metadata: metadata})
+	}
+	return docs
+}
+
+// promptInput returns the variables and templates of a chat template run, nil for other components.
+func promptInput(info *callbacks.RunInfo, input callbacks.CallbackInput) *prompt.CallbackInput {
+	if info.Component != components.ComponentOfPrompt {
+		return nil
+	}
+	return prompt.ConvCallbackInput(input)
+}
+
+// toolInputs returns the run inputs of a tool call: the tool name and its arguments, decoded into an object when
+// they're valid JSON, like the tool runs of LangChain. nil for other components.
+func toolInputs(info *callbacks.RunInfo, input callbacks.CallbackInput, limit int) map[string]interface{} {
+	if info.Component != components.ComponentOfTool {
+		return nil
+	}
+	in := tool.ConvCallbackInput(input)
+	if in == nil {
+		return nil
+	}
+	inputs := map[string]interface{}{"name": info.Name}
+	var args interface{}
+	if err := sonic.UnmarshalString(in.ArgumentsInJSON, &args); err == nil && args != nil {
+		inputs["args"] = limitPayload(args, limit)
+	} else {
+		inputs["input"] = truncatePayload(in.ArgumentsInJSON, limit)
+	}
+	return inputs
+}
+
+// toolOutputs returns the run outputs of a tool call: the result returned to the model. nil for other components.
+func toolOutputs(info *callbacks.RunInfo, output callbacks.CallbackOutput, limit int) map[string]interface{} {
+	if info.Component != components.ComponentOfTool {
+		return nil
+	}
+	out := tool.ConvCallbackOutput(output)
+	if out == nil {
+		return nil
+	}
+	return map[string]interface{}{"output": truncatePayload(out.Response, limit)}
+}
+
+// promptOutput returns the rendered messages of a chat template run, nil for other components.
+func promptOutput(info *callbacks.RunInfo, output callbacks.CallbackOutput) *prompt.CallbackOutput {
+	if info.Component != components.ComponentOfPrompt {
+		return nil
+	}
+	return prompt.ConvCallbackOutput(output)
+}
+
+// promptTemplates makes templates serializable, placeholders have no exported fields and are reported by their string form.
+func promptTemplates(templates []schema.MessagesTemplate) []interface{} {
+	ret := make([]interface{}, 0, len(templates))
+	for _, tpl := range templates {
+		if msg, ok := tpl.(*schema.Message); ok {
+			ret = append(ret, msg)
+			continue
+		}
+		ret = append(ret, fmt.Sprintf("%+v", tpl))
+	}
+	return ret
+}
+
+// setModelMetadata reports the model config in extra.invocation_params and the ls_* metadata conventions,
+// which langsmith uses to recognize the model, e.g. for cost calculation.
+func setModelMetadata(extra map[string]interface{}, info *callbacks.RunInfo, conf *model.Config) {
+	provider := strings.ToLower(info.Type)
+	params := map[string]interface{}{
+		"model":       conf.Model,
+		"max_tokens":  conf.MaxTokens,
+		"temperature": conf.Temperature,
+		"top_p":       conf.TopP,
+	}
+	if len(conf.Stop) > 0 {
+		params["stop"] = conf.Stop
+	}
+	if provider != "" {
+		params["_type"] = provider
+	}
+	extra["invocation_params"] = params
+
+	md, _ := extra["metadata"].(map[string]interface{})
+	cp := make(map[string]interface{}, len(md)+7)
+	for k, v := range md {
+		cp[k] = v
+	}
+	cp["ls_model_type"] = "chat"
+	cp["ls_model_name"] = conf.Model
+	cp["ls_max_tokens"] = conf.MaxTokens
+	cp["ls_temperature"] = conf.Temperature
+	if len(conf.Stop) > 0 {
+		cp["ls_stop"] = conf.Stop
+	}
+	if provider != "" {
+		cp["ls_provider"] = provider
+	}
+	cp["model_conf"] = conf
+	extra["metadata"] = cp
+}
+
+// modelUsage returns the token usage of a chat model output, nil for other components.
+func modelUsage(info *callbacks.RunInfo, output callbacks.CallbackOutput) *model.TokenUsage {
+	if info.Component != components.ComponentOfChatModel {
+		return nil
+	}
+	out := model.ConvCallbackOutput(output)
+	if out == nil {
+		return nil
+	}
+	return out.TokenUsage
+}
@@ -18,14 +18,23 @@ package langsmith
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"testing"
+	"unicode/utf8"
 
+	"github.com/bytedance/sonic"
 	"github.com/cloudwego/eino/callbacks"
 	"github.com/cloudwego/eino/components"
 	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRunInfoToName(t *testing.T) {
@@ -60,6 +69,22 @@ func TestRunInfoToName(t *testing.T) {
 	}
 }
 
+func TestRunNameFunc(t *testing.T) {
+	cfg := &Config{}
+	info := &callbacks.RunInfo{Name: "node_1", Type: "OpenAI", Component: components.ComponentOfChatModel}
+	assert.Equal(t, "node_1", cfg.runName(context.Background(), info, ""))
+
+	cfg.RunNameFunc = func(ctx context.Context, info *callbacks.RunInfo) string {
+		if info.Component == components.ComponentOfChatModel {
+			return "agent/" + info.Type
+		}
+		return ""
+	}
+	assert.Equal(t, "agent/OpenAI", cfg.runName(context.Background(), info, ""))
+	// empty names fall back to the default
+	assert.Equal(t, "tool", cfg.runName(context.Background(), &callbacks.RunInfo{Name: "tool", Component: components.ComponentOfTool}, ""))
+}
+
 func TestRunInfoToRunType(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -80,6 +105,13 @@ func TestRunInfoToRunType(t *testing.T) {
 			},
 			expected: RunTypeTool,
 		},
+		{
+			name: "retriever",
+			info: &callbacks.RunInfo{
+				Component: components.ComponentOfRetriever,
+			},
+			expected: RunTypeRetriever,
+		},
 		{
 			name: "chain",
 			info: &callbacks.RunInfo{
@@ -485,3 +517,234 @@ func TestSafeDeepCopySyncMapMetadata(t *testing.T) {
 		assert.Contains(t, result, "metadata")
 	})
 }
+
+func TestTruncatePayload(t *testing.T) {
+	assert.Equal(t, "hello", truncatePayload("hello", 0))
+	assert.Equal(t, "hello", truncatePayload("hello", 5))
+	assert.Equal(t, "ab...truncated 36 bytes...ij", truncatePayload(strings.Repeat("abcdefghij", 4), 28))
+	assert.Equal(t, "abcd", truncatePayload("abcdefghij", 4), "too small for the marker")
+
+	// never split multi-byte runes
+	got := truncatePayload(strings.Repeat("你好世界", 4), 31)
+	assert.Equal(t, "你...truncated 42 bytes...界", got)
+	assert.Equal(t, "你", truncatePayload("你好世界", 5))
+
+	s := strings.Repeat("数据 payload ", 500)
+	for limit := 1; limit < len(s); limit += 7 {
+		got := truncatePayload(s, limit)
+		assert.LessOrEqual(t, len(got), limit, "limit %d", limit)
+		assert.True(t, utf8.ValidString(got), "limit %d", limit)
+	}
+}
+
+func TestLimitPayload(t *testing.T) {
+	msgs := []*schema.Message{schema.UserMessage("hello")}
+	assert.Equal(t, msgs, limitPayload(msgs, 0))
+	assert.Equal(t, msgs, limitPayload(msgs, 1024))
+
+	limited, ok := limitPayload(msgs, 30).(string)
+	assert.True(t, ok)
+	assert.Contains(t, limited, "...truncated")
+}
+
+// TestLimitPayloadEscaped 测试截断后的序列化字符串再次转义后仍不超过限制
+func TestLimitPayloadEscaped(t *testing.T) {
+	msgs := []*schema.Message{schema.UserMessage(strings.Repeat(`say "hi" \ `, 200))}
+	overhead := len(`{"input":""}`)
+	for _, limit := range []int{64, 100, 257, 1000} {
+		inputs := limitRunPayload(map[string]interface{}{"messages": msgs}, "input", false, limit)
+		data, err := sonic.Marshal(inputs)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(data), limit+overhead, "limit %d", limit)
+		assert.Contains(t, inputs["input"], "...truncated", "limit %d", limit)
+	}
+}
+
+type echoTool struct{}
+
+func (echoTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: "echo", Desc: "echo the arguments"}, nil
+}
+
+func (echoTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	return argumentsInJSON, nil
+}
+
+// TestToolCallLinking 测试模型的 tool_calls 与工具 run 的 tool_call_id 关联
+func TestToolCallLinking(t *testing.T) {
+	mCli := new(mockLangsmith)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{RunIDGen: func(ctx context.Context) string { return uuid.NewString() }}}
+	var mu sync.Mutex
+	var toolRun *Run
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if run := args.Get(1).(*Run); run.RunType == RunTypeTool {
+			mu.Lock()
+			toolRun = run
+			mu.Unlock()
+		}
+	}).Return(nil)
+	var modelPatch *RunPatch
+
+	toolCall := schema.ToolCall{ID: "call-1", Function: schema.FunctionCall{Name: "echo", Arguments: `{"a":1}`}}
+	msg := schema.AssistantMessage("", []schema.ToolCall{toolCall})
+
+	// model run: tool calls are reported structurally
+	mCli.On("UpdateRun", mock.Anything, "model-run", mock.Anything).Run(func(args mock.Arguments) {
+		modelPatch = args.Get(2).(*RunPatch)
+	}).Return(nil)
+	mCli.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	modelInfo := &callbacks.RunInfo{Component: components.ComponentOfChatModel}
+	ctx := context.WithValue(context.Background(), langsmithStateKey{}, &LangsmithState{ParentRunID: "model-run"})
+	h.OnEnd(ctx, modelInfo, &model.CallbackOutput{Message: msg})
+	require.NotNil(t, modelPatch)
+	assert.Equal(t, []schema.ToolCall{toolCall}, modelPatch.Outputs["tool_calls"])
+
+	// tool run: the tool call id is attached as metadata
+	toolsNode, err := compose.NewToolNode(context.Background(), &compose.ToolsNodeConfig{Tools: []tool.BaseTool{echoTool{}}})
+	require.NoError(t, err)
+	r, err := compose.NewChain[*schema.Message, []*schema.Message]().AppendToolsNode(toolsNode).Compile(context.Background())
+	require.NoError(t, err)
+	_, err = r.Invoke(context.Background(), msg, compose.WithCallbacks(h))
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotNil(t, toolRun)
+	assert.Equal(t, "call-1", toolRun.Extra["metadata"].(map[string]interface{})["tool_call_id"])
+}
+
+// TestRetrieverDocuments 测试检索器输出转换为 langsmith documents
+func TestRetrieverDocuments(t *testing.T) {
+	h := &CallbackHandler{cfg: &Config{}}
+	info := &callbacks.RunInfo{Component: components.ComponentOfRetriever}
+	doc := (&schema.Document{ID: "doc-1", Content: "eino is a go framework", MetaData: map[string]any{"source": "wiki"}}).WithScore(0.9)
+
+	outputs, err := h.runOutputs(info, []*schema.Document{doc, nil})
+	assert.NoError(t, err)
+	docs := outputs["documents"].([]*lsDocument)
+	assert.Len(t, docs, 1)
+	assert.Equal(t, "eino is a go framework", docs[0].PageContent)
+	assert.Equal(t, "Document", docs[0].Type)
+	assert.Equal(t, "wiki", docs[0].Metadata["source"])
+	assert.Equal(t, "doc-1", docs[0].Metadata["id"])
+	assert.Equal(t, 0.9, docs[0].Metadata["score"])
+
+	outputs, err = h.runOutputs(&callbacks.RunInfo{Component: components.ComponentOfChatModel}, []*schema.Document{doc})
+	assert.NoError(t, err)
+	assert.Contains(t, outputs, "output")
+}
+
+// TestToolRunFormatting 测试工具运行记录工具名、参数对象与结果
+func TestToolRunFormatting(t *testing.T) {
+	h := &CallbackHandler{cfg: &Config{}}
+	info := &callbacks.RunInfo{Name: "get_weather", Component: components.ComponentOfTool}
+
+	inputs, err := h.runInputs(info, &tool.CallbackInput{ArgumentsInJSON: `{"city":"Paris","days":3}`})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name": "get_weather",
+		"args": map[string]interface{}{"city": "Paris", "days": float64(3)},
+	}, inputs)
+
+	// arguments that aren't JSON are kept raw
+	inputs, err = h.runInputs(info, "Paris")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "get_weather", "input": "Paris"}, inputs)
+
+	outputs, err := h.runOutputs(info, &tool.CallbackOutput{Response: "sunny"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"output": "sunny"}, outputs)
+
+	// other components are serialized
+	inputs, err = h.runInputs(&callbacks.RunInfo{Component: compose.ComponentOfLambda}, `{"city":"Paris"}`)
+	assert.NoError(t, err)
+	assert.Contains(t, inputs, "input")
+	assert.NotContains(t, inputs, "args")
+}
+
+// TestPromptRunCapture 测试 ChatTemplate 的变量、模板与渲染结果采集
+func TestPromptRunCapture(t *testing.T) {
+	mCli := new(mockLangsmith)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{RunIDGen: func(ctx context.Context) string { return "prompt-run" }}}
+	var created *Run
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*Run)
+	}).Return(nil)
+	var patched *RunPatch
+	mCli.On("UpdateRun", mock.Anything, "prompt-run", mock.Anything).Run(func(args mock.Arguments) {
+		patched = args.Get(2).(*RunPatch)
+	}).Return(nil)
+
+	tpl := prompt.FromMessages(schema.FString, schema.SystemMessage("you are {role}"), schema.MessagesPlaceholder("history", true))
+	info := &callbacks.RunInfo{Component: components.ComponentOfPrompt}
+	ctx := callbacks.InitCallbacks(context.Background(), info, h)
+	_, err := tpl.Format(ctx, map[string]any{"role": "a poet"})
+	require.NoError(t, err)
+
+	require.NotNil(t, created)
+	assert.Equal(t, RunTypePrompt, created.RunType)
+	assert.Equal(t, map[string]any{"role": "a poet"}, created.Inputs["variables"])
+	templates := created.Inputs["templates"].([]interface{})
+	require.Len(t, templates, 2)
+	assert.Equal(t, "you are {role}", templates[0].(*schema.Message).Content)
+	assert.Contains(t, templates[1], "history")
+
+	require.NotNil(t, patched)
+	messages := patched.Outputs["messages"].([]*schema.Message)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "you are a poet", messages[0].Content)
+}
+
+// TestModelInvocationParams 测试模型调用参数与 ls_* 元数据
+func TestModelInvocationParams(t *testing.T) {
+	mCli := new(mockLangsmith)
+	h := &CallbackHandler{cli: mCli, cfg: &Config{RunIDGen: func(ctx context.Context) string { return "model-run" }}}
+	created := make(chan *Run, 2)
+	mCli.On("CreateRun", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created <- args.Get(1).(*Run)
+	}).Return(nil)
+
+	conf := &model.Config{Model: "gpt-4o", MaxTokens: 256, Temperature: 0.5, Stop: []string{"END"}}
+	input := &model.CallbackInput{Messages: []*schema.Message{schema.UserMessage("hi")}, Config: conf}
+	info := &callbacks.RunInfo{Type: "OpenAI", Component: components.ComponentOfChatModel}
+
+	check := func(run *Run) {
+		params := run.Extra["invocation_params"].(map[string]interface{})
+		assert.Equal(t, "gpt-4o", params["model"])
+		assert.Equal(t, "openai", params["_type"])
+		assert.Equal(t, []string{"END"}, params["stop"])
+		metadata := run.Extra["metadata"].(map[string]interface{})
+		assert.Equal(t, "gpt-4o", metadata["ls_model_name"])
+		assert.Equal(t, "openai", metadata["ls_provider"])
+		assert.Equal(t, float32(0.5), metadata["ls_temperature"])
+		assert.Equal(t, 256, metadata["ls_max_tokens"])
+	}
+
+	h.OnStart(context.Background(), info, input)
+	check(<-created)
+
+	sr, sw := schema.Pipe[callbacks.CallbackInput](1)
+	sw.Send(input, nil)
+	sw.Close()
+	ctx := h.OnStartWithStreamInput(context.Background(), info, sr)
+	check(<-created)
+	_, state := GetState(ctx)
+	params, _ := state.Metadata.Load("invocation_params")
+	assert.Equal(t, "gpt-4o", params.(map[string]interface{})["model"])
+}
+
+// TestSafeDeepCopySyncMapMetadataNested 测试拷贝后修改嵌套的 metadata 不影响原 map
+func TestSafeDeepCopySyncMapMetadataNested(t *testing.T) {
+	original := &sync.Map{}
+	original.Store("metadata", map[string]interface{}{"ls_provider": "openai", "nested": map[string]interface{}{"a": 1}})
+	original.Store("tool_call_id", "call_1")
+
+	cp := SafeDeepCopySyncMapMetadata(original)
+	cp["metadata"].(map[string]interface{})["ls_provider"] = "ark"
+	cp["metadata"].(map[string]interface{})["nested"].(map[string]interface{})["a"] = 2
+
+	v, _ := original.Load("metadata")
+	assert.Equal(t, "openai", v.(map[string]interface{})["ls_provider"])
+	assert.Equal(t, 1, v.(map[string]interface{})["nested"].(map[string]interface{})["a"])
+	assert.Equal(t, "call_1", cp["tool_call_id"])
+}
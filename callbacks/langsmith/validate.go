@@ -0,0 +1,187 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidConfig matches the errors returned by Config.Validate with errors.Is.
+var ErrInvalidConfig = errors.New("invalid langsmith config")
+
+// ConfigError lists all the problems found by Config.Validate.
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrInvalidConfig, strings.Join(e.Problems, "; "))
+}
+
+// Is matches ErrInvalidConfig.
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+// Validate checks the config without sending any request: the URLs, the numeric limits and the enumerations. All the
+// invalid values are returned at once as a *ConfigError, nil if there's none. NewClient and NewLangsmithHandler call
+// it, a disabled config is always valid.
+//
+// Questionable settings that don't prevent tracing, e.g. an API key not formatted as langsmith keys or options
+// having no effect together, aren't errors: NewClient logs them as warnings.
+func (c *Config) Validate() error {
+	_, err := c.validate()
+	return err
+}
+
+// validate returns the warnings and the error of Validate.
+func (c *Config) validate() ([]string, error) {
+	if c.Disabled {
+		return nil, nil
+	}
+	v := &configValidator{}
+	c.validateCredentials(v)
+	v.url("APIURL", c.APIURL)
+	v.url("IngestURL", c.IngestURL)
+
+	v.nonNegative("MaxInputBytes", c.MaxInputBytes)
+	v.nonNegative("MaxOutputBytes", c.MaxOutputBytes)
+	v.nonNegative("RateBurst", c.RateBurst)
+	v.nonNegative("MaxInFlightRequests", c.MaxInFlightRequests)
+	v.nonNegative("CircuitBreakerThreshold", c.CircuitBreakerThreshold)
+	v.nonNegative("MaxRetries", c.MaxRetries)
+	v.nonNegative("QueueSize", c.QueueSize)
+	v.nonNegative("StreamUpdateChunks", c.StreamUpdateChunks)
+	v.nonNegative("MaxConcurrentStreams", c.MaxConcurrentStreams)
+	v.nonNegative("CaptureStreamChunks", c.CaptureStreamChunks)
+	v.check(c.RateLimit >= 0, "RateLimit is negative, use 0 for no limit")
+	for name, d := range map[string]time.Duration{
+		"CircuitBreakerCooldown": c.CircuitBreakerCooldown,
+		"SpoolReplayInterval":    c.SpoolReplayInterval,
+		"ExportTimeout":          c.ExportTimeout,
+		"PatchCoalesceWindow":    c.PatchCoalesceWindow,
+		"PendingRunTTL":          c.PendingRunTTL,
+		"StreamUpdateInterval":   c.StreamUpdateInterval,
+	} {
+		v.check(d >= 0, "%s is negative", name)
+	}
+	v.check(c.SchemaVersion >= SchemaVersionDefault && c.SchemaVersion <= SchemaV2,
+		"SchemaVersion %d is unknown, use SchemaV1 or SchemaV2", c.SchemaVersion)
+	v.check(c.StreamOverflow == "" || c.StreamOverflow == StreamOverflowBlock || c.StreamOverflow == StreamOverflowDrop,
+		"StreamOverflow %q is unknown, use StreamOverflowBlock or StreamOverflowDrop", c.StreamOverflow)
+	if b := c.TraceBudget; b != nil {
+		v.check(b.MaxTokens >= 0 && b.MaxCost >= 0, "TraceBudget limits are negative, use 0 for no limit")
+		v.warn(b.MaxTokens != 0 || b.MaxCost != 0, "TraceBudget sets neither MaxTokens nor MaxCost")
+	}
+	if c.Langfuse != nil {
+		if _, err := NewLangfuseExporter(c.Langfuse); err != nil {
+			v.check(false, "Langfuse: %v", err)
+		}
+	}
+
+	// options conflicting or having no effect together
+	v.warn(!(c.HideInputs && c.InlineMedia), "InlineMedia has no effect with HideInputs, media inputs are hidden")
+	v.warn(!(c.HideOutputs && c.FullEmbeddings), "FullEmbeddings has no effect with HideOutputs, vectors are hidden")
+	v.warn(!(c.RootOnly && c.LatencyBreakdown), "LatencyBreakdown needs the nested runs dropped by RootOnly")
+	v.warn(c.RateBurst == 0 || c.RateLimit > 0, "RateBurst has no effect without RateLimit")
+	v.warn(c.CircuitBreakerCooldown == 0 || c.CircuitBreakerThreshold > 0,
+		"CircuitBreakerCooldown has no effect without CircuitBreakerThreshold")
+	v.warn(c.StreamOverflow != StreamOverflowDrop || c.MaxConcurrentStreams > 0,
+		"StreamOverflow has no effect without MaxConcurrentStreams")
+	sort.Strings(v.warnings)
+	return v.warnings, v.err()
+}
+
+// apiKeyPrefixes are the prefixes of the API keys issued by langsmith cloud.
+var apiKeyPrefixes = []string{"lsv2_", "ls__"}
+
+// validateCredentials checks the format of the API key, which is needed unless the runs aren't sent to langsmith.
+// requests with a malformed key fail with ErrUnauthorized, but the key is only checked by langsmith, so it's a warning.
+func (c *Config) validateCredentials(v *configValidator) {
+	if c.APIKey == "" {
+		v.warn(c.Exporter != nil || c.DryRun || c.AuthProvider != nil,
+			"APIKey is empty, set it or the %s environment variable with ConfigFromEnv", EnvAPIKey)
+		return
+	}
+	if strings.HasPrefix(c.APIKey, "Bearer ") {
+		v.warn(false, "APIKey must not include the Bearer scheme")
+		return
+	}
+	if strings.TrimSpace(c.APIKey) != c.APIKey || strings.ContainsAny(c.APIKey, " \t\r\n") {
+		v.warn(false, "APIKey contains whitespace")
+		return
+	}
+	// self-hosted deployments may issue keys of other formats
+	if c.APIURL != "" && strings.TrimRight(c.APIURL, "/") != DefaultLangsmithAPIURL {
+		return
+	}
+	for _, prefix := range apiKeyPrefixes {
+		if strings.HasPrefix(c.APIKey, prefix) {
+			return
+		}
+	}
+	v.warn(false, "APIKey isn't a langsmith API key, they start with %s", strings.Join(apiKeyPrefixes, " or "))
+}
+
+// configValidator collects the problems of a config, invalid values are errors, questionable settings warnings.
+type configValidator struct {
+	problems []string
+	warnings []string
+}
+
+func (v *configValidator) check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		v.problems = append(v.problems, fmt.Sprintf(format, args...))
+	}
+}
+
+func (v *configValidator) warn(ok bool, format string, args ...interface{}) {
+	if !ok {
+		v.warnings = append(v.warnings, fmt.Sprintf(format, args...))
+	}
+}
+
+func (v *configValidator) nonNegative(name string, n int) {
+	v.check(n >= 0, "%s is negative, use 0 for the default", name)
+}
+
+// url checks an optional absolute http(s) url.
+func (v *configValidator) url(name, raw string) {
+	if raw == "" {
+		return
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		v.check(false, "%s %q is invalid: %v", name, raw, err)
+		return
+	}
+	v.check((u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+		"%s %q must be an absolute http or https url, e.g. %s", name, raw, DefaultLangsmithAPIURL)
+}
+
+func (v *configValidator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	sort.Strings(v.problems)
+	return &ConfigError{Problems: v.problems}
+}
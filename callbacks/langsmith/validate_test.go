@@ -0,0 +1,112 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConfigValidate 测试配置校验
+func TestConfigValidate(t *testing.T) {
+	valid := []*Config{
+		{APIKey: "lsv2_pt_0123"},
+		{APIKey: "ls__0123"},
+		{APIKey: "custom", APIURL: "https://langsmith.internal/api"},
+		{Exporter: new(mockLangsmith)},
+		{DryRun: true},
+		{Disabled: true, APIURL: "::"},
+		{APIKey: "lsv2_x", RateLimit: 5, RateBurst: 10, StreamOverflow: StreamOverflowDrop, MaxConcurrentStreams: 2},
+		{APIKey: "lsv2_x", TraceBudget: &TraceBudget{MaxTokens: 100}},
+	}
+	for i, cfg := range valid {
+		warnings, err := cfg.validate()
+		assert.NoError(t, err, "config %d", i)
+		assert.Empty(t, warnings, "config %d", i)
+	}
+
+	invalid := []struct {
+		name    string
+		cfg     *Config
+		problem string
+	}{
+		{"relative url", &Config{APIKey: "x", APIURL: "langsmith.internal"}, "APIURL \"langsmith.internal\" must be an absolute http or https url"},
+		{"bad ingest url", &Config{Exporter: new(mockLangsmith), IngestURL: "ftp://host"}, "IngestURL \"ftp://host\""},
+		{"negative", &Config{DryRun: true, MaxRetries: -1}, "MaxRetries is negative"},
+		{"negative duration", &Config{DryRun: true, ExportTimeout: -time.Second}, "ExportTimeout is negative"},
+		{"schema", &Config{DryRun: true, SchemaVersion: 3}, "SchemaVersion 3 is unknown"},
+		{"overflow", &Config{DryRun: true, StreamOverflow: "skip"}, "StreamOverflow \"skip\" is unknown"},
+		{"negative budget", &Config{DryRun: true, TraceBudget: &TraceBudget{MaxCost: -1}}, "TraceBudget limits are negative"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+			var cfgErr *ConfigError
+			require.True(t, errors.As(err, &cfgErr))
+			require.Len(t, cfgErr.Problems, 1, err.Error())
+			assert.Contains(t, cfgErr.Problems[0], tt.problem)
+		})
+	}
+
+	questionable := []struct {
+		name    string
+		cfg     *Config
+		warning string
+	}{
+		{"missing key", &Config{}, "APIKey is empty, set it or the LANGSMITH_API_KEY environment variable"},
+		{"bearer key", &Config{APIKey: "Bearer lsv2_x"}, "APIKey must not include the Bearer scheme"},
+		{"whitespace key", &Config{APIKey: "lsv2_x\n"}, "APIKey contains whitespace"},
+		{"unknown key", &Config{APIKey: "sk-123"}, "APIKey isn't a langsmith API key"},
+		{"empty budget", &Config{DryRun: true, TraceBudget: &TraceBudget{}}, "TraceBudget sets neither MaxTokens nor MaxCost"},
+		{"hidden media", &Config{DryRun: true, HideInputs: true, InlineMedia: true}, "InlineMedia has no effect with HideInputs"},
+		{"root only latency", &Config{DryRun: true, RootOnly: true, LatencyBreakdown: true}, "LatencyBreakdown needs the nested runs"},
+		{"burst", &Config{DryRun: true, RateBurst: 3}, "RateBurst has no effect without RateLimit"},
+		{"drop", &Config{DryRun: true, StreamOverflow: StreamOverflowDrop}, "StreamOverflow has no effect without MaxConcurrentStreams"},
+	}
+	for _, tt := range questionable {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := tt.cfg.validate()
+			require.NoError(t, err)
+			require.Len(t, warnings, 1)
+			assert.Contains(t, warnings[0], tt.warning)
+		})
+	}
+}
+
+// TestConfigValidateAggregates 测试所有错误一次性返回，可疑配置记录为警告
+func TestConfigValidateAggregates(t *testing.T) {
+	_, err := NewLangsmithHandler(&Config{APIURL: "::", IngestURL: "ingest", MaxInputBytes: -1, RateLimit: -1})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	var cfgErr *ConfigError
+	require.True(t, errors.As(err, &cfgErr))
+	assert.Len(t, cfgErr.Problems, 4)
+	assert.Contains(t, err.Error(), "invalid langsmith config: ")
+	assert.Contains(t, err.Error(), "; ")
+
+	logger := &recordLogger{}
+	_, err = NewLangsmithHandler(&Config{APIKey: "test-key", Logger: logger, RootOnly: true, LatencyBreakdown: true})
+	require.NoError(t, err)
+	require.Len(t, logger.entries, 2)
+	assert.Contains(t, logger.entries[0], "WARN questionable langsmith config [problem APIKey isn't a langsmith API key")
+	assert.Contains(t, logger.entries[1], "LatencyBreakdown needs the nested runs")
+}
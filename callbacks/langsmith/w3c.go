@@ -0,0 +1,96 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TraceparentHeader is the W3C trace context header.
+const TraceparentHeader = "traceparent"
+
+// ContextWithTraceparent continues the distributed trace of a W3C traceparent: runs started from the returned
+// context use the W3C trace id, in uuid form, as their langsmith trace id, like WithTraceID.
+func ContextWithTraceparent(ctx context.Context, traceparent string) (context.Context, error) {
+	traceID, err := parseTraceparent(traceparent)
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, langsmithStateKey{}, &LangsmithState{TraceID: traceID}), nil
+}
+
+// ExtractFromHTTPHeaders continues the distributed trace of the traceparent header, see ContextWithTraceparent.
+// ctx is returned unchanged when the header is missing or invalid.
+func ExtractFromHTTPHeaders(ctx context.Context, header http.Header) context.Context {
+	traceparent := header.Get(TraceparentHeader)
+	if traceparent == "" {
+		return ctx
+	}
+	newCtx, err := ContextWithTraceparent(ctx, traceparent)
+	if err != nil {
+		return ctx
+	}
+	return newCtx
+}
+
+// Traceparent returns the W3C traceparent of the run ctx is traced in, the parent id is derived from the run id.
+// it's empty outside of a traced run or when the ids are not uuids, e.g. generated by a custom Config.RunIDGen.
+func Traceparent(ctx context.Context) string {
+	_, state := GetState(ctx)
+	if state == nil {
+		return ""
+	}
+	traceID := strings.ReplaceAll(state.TraceID, "-", "")
+	runID := strings.ReplaceAll(state.ParentRunID, "-", "")
+	if !isHex(traceID, 32) || !isHex(runID, 32) {
+		return ""
+	}
+	return fmt.Sprintf("00-%s-%s-01", strings.ToLower(traceID), strings.ToLower(runID[16:]))
+}
+
+// InjectTraceparent sets the traceparent header of an outgoing request to the run ctx is traced in.
+func InjectTraceparent(ctx context.Context, header http.Header) {
+	if traceparent := Traceparent(ctx); traceparent != "" {
+		header.Set(TraceparentHeader, traceparent)
+	}
+}
+
+// parseTraceparent validates a traceparent and returns its trace id in uuid form.
+func parseTraceparent(traceparent string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || !isHex(parts[0], 2) || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return "", fmt.Errorf("invalid traceparent %q", traceparent)
+	}
+	traceID, parentID, flags := strings.ToLower(parts[1]), parts[2], parts[3]
+	if !isHex(traceID, 32) || traceID == strings.Repeat("0", 32) ||
+		!isHex(parentID, 16) || parentID == strings.Repeat("0", 16) || !isHex(flags, 2) {
+		return "", fmt.Errorf("invalid traceparent %q", traceparent)
+	}
+	return fmt.Sprintf("%s-%s-%s-%s-%s", traceID[:8], traceID[8:12], traceID[12:16], traceID[16:20], traceID[20:]), nil
+}
+
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
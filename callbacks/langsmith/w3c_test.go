@@ -0,0 +1,72 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package langsmith
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	traceID, err := parseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f35-77b3-4da6-a3ce-929d0e0e4736", traceID)
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		_, err = parseTraceparent(invalid)
+		assert.Error(t, err, invalid)
+	}
+	// future versions may append fields
+	_, err = parseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	assert.NoError(t, err)
+}
+
+func TestTraceparentPropagation(t *testing.T) {
+	header := http.Header{}
+	header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ExtractFromHTTPHeaders(context.Background(), header)
+	_, state := GetState(ctx)
+	require.NotNil(t, state)
+	assert.Equal(t, "4bf92f35-77b3-4da6-a3ce-929d0e0e4736", state.TraceID)
+	assert.Equal(t, "", Traceparent(ctx), "no run is started yet")
+
+	ctx = context.WithValue(ctx, langsmithStateKey{}, &LangsmithState{
+		TraceID:     state.TraceID,
+		ParentRunID: "0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0",
+	})
+	out := http.Header{}
+	InjectTraceparent(ctx, out)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-8796a5b4c3d2e1f0-01", out.Get(TraceparentHeader))
+
+	bad := http.Header{}
+	bad.Set(TraceparentHeader, "garbage")
+	assert.Equal(t, context.Background(), ExtractFromHTTPHeaders(context.Background(), bad))
+	_, err := ContextWithTraceparent(context.Background(), "garbage")
+	assert.Error(t, err)
+}